@@ -0,0 +1,395 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config anke-toの起動時の設定
+type Config struct {
+	// Env 実行環境 (dev, pprofでSQLのログを出し，pprofではpprofのサーバーも起動する)
+	Env      string         `json:"env"`
+	Server   ServerConfig   `json:"server"`
+	Database DatabaseConfig `json:"database"`
+	Auth     AuthConfig     `json:"auth"`
+	TraQ     TraQConfig     `json:"traq"`
+	// RateLimit ユーザーとエンドポイントごとのリクエストの回数制限
+	RateLimit      RateLimitConfig      `json:"rate_limit"`
+	IdempotencyKey IdempotencyKeyConfig `json:"idempotency_key"`
+	Trash          TrashConfig          `json:"trash"`
+	// AnonymousSecret 匿名のアンケートの回答者をハッシュ化するときに加える秘密の値 (必須)
+	AnonymousSecret string `json:"anonymous_secret"`
+	// ShareLinkSecret 共有リンクのトークンの署名の鍵 (必須)
+	ShareLinkSecret string `json:"share_link_secret"`
+}
+
+// ServerConfig HTTPサーバーの設定
+type ServerConfig struct {
+	// Port echoのサーバーのアドレス (:1323の形式)
+	Port string `json:"port"`
+	// PprofAddress Envがpprofの場合に起動するpprofのサーバーのアドレス
+	PprofAddress string `json:"pprof_address"`
+	// AllowOrigins CORSで許可するオリジン
+	AllowOrigins []string `json:"allow_origins"`
+	// StaticRoot クライアントのビルド結果のディレクトリ
+	StaticRoot string `json:"static_root"`
+}
+
+// DatabaseConfig MariaDBの接続先の設定
+type DatabaseConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Database string `json:"database"`
+	// Location DBの日時のタイムゾーン
+	Location string `json:"location"`
+}
+
+// AuthConfig リクエストを送ったユーザーの認証の設定
+type AuthConfig struct {
+	// Mode 認証の方式 (必須，ヘッダーを信頼するかは明示的に選ぶ)
+	Mode string `json:"mode"`
+	// DevUser Modeがdevの場合に常に扱うユーザーのtraQID
+	DevUser string `json:"dev_user"`
+	// OAuthClientID Modeがoauthの場合のtraQのOAuth2のクライアントID
+	OAuthClientID     string `json:"oauth_client_id"`
+	OAuthClientSecret string `json:"oauth_client_secret"`
+	// OAuthRedirectURL /api/oauth2/callbackのURL
+	OAuthRedirectURL string `json:"oauth_redirect_url"`
+}
+
+// AuthConfig.Modeで指定する認証の方式
+const (
+	// AuthModeHeader showcaseのプロキシが付けるX-Showcase-Userヘッダーを信頼する
+	AuthModeHeader = "header"
+	// AuthModeOAuth traQのOAuth2でログインする
+	AuthModeOAuth = "oauth"
+	// AuthModeDev 常にDevUserのユーザーとして扱う (開発用)
+	AuthModeDev = "dev"
+)
+
+// TraQConfig traQのAPIとWebhookの設定
+type TraQConfig struct {
+	// APIURL traQのAPIのURL
+	APIURL string `json:"api_url"`
+	// AccessToken ユーザーとグループの一覧の取得に使うbotのアクセストークン
+	AccessToken   string `json:"access_token"`
+	WebhookID     string `json:"webhook_id"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// RateLimitConfig 読み込み(GET, HEAD)と書き込みのリクエストの回数制限
+type RateLimitConfig struct {
+	Read  RateLimitRule `json:"read"`
+	Write RateLimitRule `json:"write"`
+}
+
+// RateLimitRule トークンバケットの設定
+type RateLimitRule struct {
+	// PerMinute 1分あたりに補充されるトークンの数 (0の場合は制限しない)
+	PerMinute int `json:"per_minute"`
+	// Burst バケットの容量 (連続で許可されるリクエストの数)
+	Burst int `json:"burst"`
+}
+
+// IdempotencyKeyConfig Idempotency-Keyの設定
+type IdempotencyKeyConfig struct {
+	// WindowHours Idempotency-Keyを保持する時間
+	WindowHours int `json:"window_hours"`
+}
+
+// TrashConfig 削除されたアンケートの設定
+type TrashConfig struct {
+	// RetentionDays 削除されたアンケートを完全に削除するまでの日数 (0の場合は完全に削除しない)
+	RetentionDays int `json:"retention_days"`
+}
+
+// EnvConfigFile 設定ファイル(JSON)のパスを指定する環境変数
+const EnvConfigFile = "ANKE-TO_CONFIG_FILE"
+
+// Default 何も指定しない場合の設定
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:         ":1323",
+			PprofAddress: "0.0.0.0:6060",
+			AllowOrigins: []string{"http://localhost:8080"},
+			StaticRoot:   "client/dist",
+		},
+		Database: DatabaseConfig{
+			Username: "root",
+			Password: "password",
+			Hostname: "localhost",
+			Port:     3306,
+			Database: "anke-to",
+			Location: "Asia/Tokyo",
+		},
+		Auth: AuthConfig{
+			DevUser: "mds_boy",
+		},
+		TraQ: TraQConfig{
+			APIURL: "https://q.trap.jp/api/v3",
+		},
+		RateLimit: RateLimitConfig{
+			Read: RateLimitRule{
+				PerMinute: 600,
+				Burst:     60,
+			},
+			Write: RateLimitRule{
+				PerMinute: 60,
+				Burst:     10,
+			},
+		},
+		IdempotencyKey: IdempotencyKeyConfig{
+			WindowHours: 24,
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+		},
+	}
+}
+
+/*
+Load 既定値，設定ファイル，環境変数の順に上書きして設定を読み込む
+設定ファイルは環境変数ANKE-TO_CONFIG_FILEで指定した場合のみ読み込む
+*/
+func Load() (*Config, error) {
+	config := Default()
+
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		err := config.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := config.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the config file(%s): %w", path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	// 設定の名前の間違いに気づけるように知らない項目はエラーにする
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(c)
+	if err != nil {
+		return fmt.Errorf("failed to parse the config file(%s): %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString := func(key string, value *string) {
+		if v := os.Getenv(key); v != "" {
+			*value = v
+		}
+	}
+	var err error
+	setInt := func(key string, value *int) {
+		v := os.Getenv(key)
+		if v == "" || err != nil {
+			return
+		}
+
+		i, atoiErr := strconv.Atoi(v)
+		if atoiErr != nil {
+			err = fmt.Errorf("invalid %s: %s", key, v)
+			return
+		}
+		*value = i
+	}
+
+	setString("ANKE-TO_ENV", &c.Env)
+
+	setString("PORT", &c.Server.Port)
+	setString("PPROF_ADDRESS", &c.Server.PprofAddress)
+	if v := os.Getenv("CORS_ALLOW_ORIGINS"); v != "" {
+		c.Server.AllowOrigins = strings.Split(v, ",")
+		for i := range c.Server.AllowOrigins {
+			c.Server.AllowOrigins[i] = strings.TrimSpace(c.Server.AllowOrigins[i])
+		}
+	}
+	setString("STATIC_ROOT", &c.Server.StaticRoot)
+
+	setString("MARIADB_USERNAME", &c.Database.Username)
+	setString("MARIADB_PASSWORD", &c.Database.Password)
+	setString("MARIADB_HOSTNAME", &c.Database.Hostname)
+	setInt("MARIADB_PORT", &c.Database.Port)
+	setString("MARIADB_DATABASE", &c.Database.Database)
+	setString("MARIADB_LOCATION", &c.Database.Location)
+
+	setString("AUTH_MODE", &c.Auth.Mode)
+	setString("DEV_USER", &c.Auth.DevUser)
+	setString("TRAQ_OAUTH_CLIENT_ID", &c.Auth.OAuthClientID)
+	setString("TRAQ_OAUTH_CLIENT_SECRET", &c.Auth.OAuthClientSecret)
+	setString("TRAQ_OAUTH_REDIRECT_URL", &c.Auth.OAuthRedirectURL)
+
+	setString("TRAQ_API_URL", &c.TraQ.APIURL)
+	setString("TRAQ_ACCESS_TOKEN", &c.TraQ.AccessToken)
+	setString("TRAQ_WEBHOOK_ID", &c.TraQ.WebhookID)
+	setString("TRAQ_WEBHOOK_SECRET", &c.TraQ.WebhookSecret)
+
+	setInt("RATE_LIMIT_READ_PER_MINUTE", &c.RateLimit.Read.PerMinute)
+	setInt("RATE_LIMIT_READ_BURST", &c.RateLimit.Read.Burst)
+	setInt("RATE_LIMIT_WRITE_PER_MINUTE", &c.RateLimit.Write.PerMinute)
+	setInt("RATE_LIMIT_WRITE_BURST", &c.RateLimit.Write.Burst)
+	setInt("IDEMPOTENCY_KEY_WINDOW_HOURS", &c.IdempotencyKey.WindowHours)
+	setInt("TRASH_RETENTION_DAYS", &c.Trash.RetentionDays)
+
+	setString("ANONYMOUS_SECRET", &c.AnonymousSecret)
+	setString("SHARE_LINK_SECRET", &c.ShareLinkSecret)
+
+	return err
+}
+
+// Validate 設定が正しいかの確認 (誤りを全てまとめて返す)
+func (c *Config) Validate() error {
+	messages := []string{}
+
+	if !isValidAddress(c.Server.Port) {
+		messages = append(messages, fmt.Sprintf("server.port(PORT) must be like :1323: %s", c.Server.Port))
+	}
+	if c.Env == "pprof" {
+		if !isValidAddress(c.Server.PprofAddress) {
+			messages = append(messages, fmt.Sprintf("server.pprof_address(PPROF_ADDRESS) must be like 0.0.0.0:6060: %s", c.Server.PprofAddress))
+		}
+	}
+	for _, origin := range c.Server.AllowOrigins {
+		if !isValidOrigin(origin) {
+			messages = append(messages, fmt.Sprintf("server.allow_origins(CORS_ALLOW_ORIGINS) must be * or origins like https://anke-to.trap.jp: %s", origin))
+		}
+	}
+	if c.Server.StaticRoot == "" {
+		messages = append(messages, "server.static_root(STATIC_ROOT) is required")
+	}
+
+	if c.Database.Hostname == "" {
+		messages = append(messages, "database.hostname(MARIADB_HOSTNAME) is required")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		messages = append(messages, fmt.Sprintf("database.port(MARIADB_PORT) must be between 1 and 65535: %d", c.Database.Port))
+	}
+	if c.Database.Database == "" {
+		messages = append(messages, "database.database(MARIADB_DATABASE) is required")
+	}
+	if _, err := time.LoadLocation(c.Database.Location); err != nil || c.Database.Location == "" {
+		messages = append(messages, fmt.Sprintf("database.location(MARIADB_LOCATION) must be a time zone like Asia/Tokyo: %s", c.Database.Location))
+	}
+
+	switch c.Auth.Mode {
+	case AuthModeHeader:
+	case AuthModeOAuth:
+		if c.Auth.OAuthClientID == "" {
+			messages = append(messages, "auth.oauth_client_id(TRAQ_OAUTH_CLIENT_ID) is required in oauth mode")
+		}
+		if !isValidURL(c.Auth.OAuthRedirectURL) {
+			messages = append(messages, fmt.Sprintf("auth.oauth_redirect_url(TRAQ_OAUTH_REDIRECT_URL) must be a URL like https://anke-to.trap.jp/api/oauth2/callback in oauth mode: %s", c.Auth.OAuthRedirectURL))
+		}
+	case AuthModeDev:
+		if c.Auth.DevUser == "" {
+			messages = append(messages, "auth.dev_user(DEV_USER) is required in dev mode")
+		}
+	default:
+		// 既定値にするとX-Showcase-Userヘッダーを偽装できる環境で気づかずに起動してしまう
+		messages = append(messages, fmt.Sprintf("auth.mode(AUTH_MODE) must be header, oauth or dev: %s", c.Auth.Mode))
+	}
+
+	if !isValidURL(c.TraQ.APIURL) {
+		messages = append(messages, fmt.Sprintf("traq.api_url(TRAQ_API_URL) must be a URL like https://q.trap.jp/api/v3: %s", c.TraQ.APIURL))
+	}
+
+	for _, rule := range []struct {
+		name string
+		env  string
+		rule RateLimitRule
+	}{
+		{name: "read", env: "READ", rule: c.RateLimit.Read},
+		{name: "write", env: "WRITE", rule: c.RateLimit.Write},
+	} {
+		if rule.rule.PerMinute < 0 {
+			messages = append(messages, fmt.Sprintf("rate_limit.%s.per_minute(RATE_LIMIT_%s_PER_MINUTE) must not be negative: %d", rule.name, rule.env, rule.rule.PerMinute))
+		}
+		if rule.rule.Burst <= 0 {
+			messages = append(messages, fmt.Sprintf("rate_limit.%s.burst(RATE_LIMIT_%s_BURST) must be positive: %d", rule.name, rule.env, rule.rule.Burst))
+		}
+	}
+
+	if c.IdempotencyKey.WindowHours <= 0 {
+		messages = append(messages, fmt.Sprintf("idempotency_key.window_hours(IDEMPOTENCY_KEY_WINDOW_HOURS) must be positive: %d", c.IdempotencyKey.WindowHours))
+	}
+	if c.Trash.RetentionDays < 0 {
+		messages = append(messages, fmt.Sprintf("trash.retention_days(TRASH_RETENTION_DAYS) must not be negative: %d", c.Trash.RetentionDays))
+	}
+
+	// 空の場合は全てのtraQIDのハッシュと照らし合わせて匿名の回答者が分かってしまう
+	if c.AnonymousSecret == "" {
+		messages = append(messages, "anonymous_secret(ANONYMOUS_SECRET) is required")
+	}
+	// 空の場合は再起動のたびに発行済みの共有リンクが全て無効になる
+	if c.ShareLinkSecret == "" {
+		messages = append(messages, "share_link_secret(SHARE_LINK_SECRET) is required")
+	}
+
+	if len(messages) != 0 {
+		return errors.New("invalid config:\n  " + strings.Join(messages, "\n  "))
+	}
+
+	return nil
+}
+
+// isValidAddress ホストを省略できる「ホスト:ポート」の形式か
+func isValidAddress(address string) bool {
+	i := strings.LastIndex(address, ":")
+	if i < 0 {
+		return false
+	}
+
+	port, err := strconv.Atoi(address[i+1:])
+
+	return err == nil && port > 0 && port <= 65535
+}
+
+func isValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isValidOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
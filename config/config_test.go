@@ -0,0 +1,365 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var configEnvKeys = []string{
+	EnvConfigFile,
+	"ANKE-TO_ENV",
+	"PORT",
+	"PPROF_ADDRESS",
+	"CORS_ALLOW_ORIGINS",
+	"STATIC_ROOT",
+	"MARIADB_USERNAME",
+	"MARIADB_PASSWORD",
+	"MARIADB_HOSTNAME",
+	"MARIADB_PORT",
+	"MARIADB_DATABASE",
+	"MARIADB_LOCATION",
+	"AUTH_MODE",
+	"DEV_USER",
+	"TRAQ_OAUTH_CLIENT_ID",
+	"TRAQ_OAUTH_CLIENT_SECRET",
+	"TRAQ_OAUTH_REDIRECT_URL",
+	"TRAQ_API_URL",
+	"TRAQ_ACCESS_TOKEN",
+	"TRAQ_WEBHOOK_ID",
+	"TRAQ_WEBHOOK_SECRET",
+	"RATE_LIMIT_READ_PER_MINUTE",
+	"RATE_LIMIT_READ_BURST",
+	"RATE_LIMIT_WRITE_PER_MINUTE",
+	"RATE_LIMIT_WRITE_BURST",
+	"IDEMPOTENCY_KEY_WINDOW_HOURS",
+	"TRASH_RETENTION_DAYS",
+	"ANONYMOUS_SECRET",
+	"SHARE_LINK_SECRET",
+}
+
+// setenv テスト終了時に元に戻す環境変数の設定
+func setenv(t *testing.T, key string, value string) {
+	prevValue, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prevValue)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// clearEnv 設定の環境変数を全て空にする
+func clearEnv(t *testing.T) {
+	for _, key := range configEnvKeys {
+		setenv(t, key, "")
+	}
+}
+
+// setRequiredEnv 既定値の無い必須の設定を環境変数で与える
+func setRequiredEnv(t *testing.T) {
+	setenv(t, "AUTH_MODE", "header")
+	setenv(t, "ANONYMOUS_SECRET", "anonymous-secret")
+	setenv(t, "SHARE_LINK_SECRET", "share-link-secret")
+}
+
+// validConfig 既定値に必須の設定を加えた正しい設定
+func validConfig() *Config {
+	config := Default()
+	config.Auth.Mode = AuthModeHeader
+	config.AnonymousSecret = "anonymous-secret"
+	config.ShareLinkSecret = "share-link-secret"
+
+	return config
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "anke-to-config")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestLoadDefault(t *testing.T) {
+	clearEnv(t)
+	setRequiredEnv(t)
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, validConfig(), config)
+}
+
+func TestLoadRequired(t *testing.T) {
+	clearEnv(t)
+
+	_, err := Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "auth.mode(AUTH_MODE)")
+		assert.Contains(t, err.Error(), "anonymous_secret(ANONYMOUS_SECRET)")
+		assert.Contains(t, err.Error(), "share_link_secret(SHARE_LINK_SECRET)")
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	clearEnv(t)
+	setRequiredEnv(t)
+	setenv(t, "ANKE-TO_ENV", "dev")
+	setenv(t, "PORT", ":3000")
+	setenv(t, "CORS_ALLOW_ORIGINS", "https://anke-to.trap.jp, http://localhost:8080")
+	setenv(t, "STATIC_ROOT", "/srv/anke-to")
+	setenv(t, "MARIADB_HOSTNAME", "mysql")
+	setenv(t, "MARIADB_PORT", "3307")
+	setenv(t, "MARIADB_LOCATION", "UTC")
+	setenv(t, "TRAQ_API_URL", "http://localhost:3000/api/v3")
+	setenv(t, "RATE_LIMIT_READ_PER_MINUTE", "0")
+	setenv(t, "RATE_LIMIT_WRITE_BURST", "3")
+	setenv(t, "IDEMPOTENCY_KEY_WINDOW_HOURS", "1")
+	setenv(t, "TRASH_RETENTION_DAYS", "0")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "dev", config.Env)
+	assert.Equal(t, ":3000", config.Server.Port)
+	assert.Equal(t, []string{"https://anke-to.trap.jp", "http://localhost:8080"}, config.Server.AllowOrigins)
+	assert.Equal(t, "/srv/anke-to", config.Server.StaticRoot)
+	assert.Equal(t, "mysql", config.Database.Hostname)
+	assert.Equal(t, 3307, config.Database.Port)
+	assert.Equal(t, "UTC", config.Database.Location)
+	assert.Equal(t, "root", config.Database.Username, "default")
+	assert.Equal(t, "http://localhost:3000/api/v3", config.TraQ.APIURL)
+	assert.Equal(t, 0, config.RateLimit.Read.PerMinute)
+	assert.Equal(t, 3, config.RateLimit.Write.Burst)
+	assert.Equal(t, 60, config.RateLimit.Write.PerMinute, "default")
+	assert.Equal(t, 1, config.IdempotencyKey.WindowHours)
+	assert.Equal(t, 0, config.Trash.RetentionDays)
+
+	setenv(t, "RATE_LIMIT_WRITE_PER_MINUTE", "many")
+	_, err = Load()
+	assert.Error(t, err, "not a number")
+}
+
+func TestLoadFile(t *testing.T) {
+	clearEnv(t)
+	setRequiredEnv(t)
+	setenv(t, EnvConfigFile, writeConfigFile(t, `{
+		"server": {"allow_origins": ["https://anke-to.trap.jp"]},
+		"database": {"hostname": "db.example.com", "port": 3307}
+	}`))
+	setenv(t, "MARIADB_PORT", "3308")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://anke-to.trap.jp"}, config.Server.AllowOrigins)
+	assert.Equal(t, "client/dist", config.Server.StaticRoot, "default")
+	assert.Equal(t, "db.example.com", config.Database.Hostname)
+	assert.Equal(t, 3308, config.Database.Port, "env overrides the file")
+
+	setenv(t, EnvConfigFile, writeConfigFile(t, `{"database": {"host": "db.example.com"}}`))
+	_, err = Load()
+	assert.Error(t, err, "unknown field")
+
+	setenv(t, EnvConfigFile, filepath.Join(os.TempDir(), "anke-to-not-found.json"))
+	_, err = Load()
+	assert.Error(t, err, "missing file")
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		modify      func(config *Config)
+		isErr       bool
+	}{
+		{
+			description: "default",
+			modify:      func(*Config) {},
+		},
+		{
+			description: "port without colon",
+			modify: func(config *Config) {
+				config.Server.Port = "1323"
+			},
+			isErr: true,
+		},
+		{
+			description: "invalid pprof address in pprof mode",
+			modify: func(config *Config) {
+				config.Env = "pprof"
+				config.Server.PprofAddress = "localhost"
+			},
+			isErr: true,
+		},
+		{
+			description: "wildcard origin",
+			modify: func(config *Config) {
+				config.Server.AllowOrigins = []string{"*"}
+			},
+		},
+		{
+			description: "origin with path",
+			modify: func(config *Config) {
+				config.Server.AllowOrigins = []string{"https://anke-to.trap.jp/questionnaires"}
+			},
+			isErr: true,
+		},
+		{
+			description: "origin without scheme",
+			modify: func(config *Config) {
+				config.Server.AllowOrigins = []string{"anke-to.trap.jp"}
+			},
+			isErr: true,
+		},
+		{
+			description: "empty static root",
+			modify: func(config *Config) {
+				config.Server.StaticRoot = ""
+			},
+			isErr: true,
+		},
+		{
+			description: "database port out of range",
+			modify: func(config *Config) {
+				config.Database.Port = 70000
+			},
+			isErr: true,
+		},
+		{
+			description: "unknown location",
+			modify: func(config *Config) {
+				config.Database.Location = "Asia/Nowhere"
+			},
+			isErr: true,
+		},
+		{
+			description: "empty auth mode",
+			modify: func(config *Config) {
+				config.Auth.Mode = ""
+			},
+			isErr: true,
+		},
+		{
+			description: "unknown auth mode",
+			modify: func(config *Config) {
+				config.Auth.Mode = "none"
+			},
+			isErr: true,
+		},
+		{
+			description: "oauth mode without client id",
+			modify: func(config *Config) {
+				config.Auth.Mode = AuthModeOAuth
+				config.Auth.OAuthRedirectURL = "https://anke-to.trap.jp/api/oauth2/callback"
+			},
+			isErr: true,
+		},
+		{
+			description: "oauth mode",
+			modify: func(config *Config) {
+				config.Auth.Mode = AuthModeOAuth
+				config.Auth.OAuthClientID = "client-id"
+				config.Auth.OAuthRedirectURL = "https://anke-to.trap.jp/api/oauth2/callback"
+			},
+		},
+		{
+			description: "dev mode without user",
+			modify: func(config *Config) {
+				config.Auth.Mode = AuthModeDev
+				config.Auth.DevUser = ""
+			},
+			isErr: true,
+		},
+		{
+			description: "invalid traQ api url",
+			modify: func(config *Config) {
+				config.TraQ.APIURL = "q.trap.jp/api/v3"
+			},
+			isErr: true,
+		},
+		{
+			description: "disabled rate limit",
+			modify: func(config *Config) {
+				config.RateLimit.Read.PerMinute = 0
+			},
+		},
+		{
+			description: "negative rate limit",
+			modify: func(config *Config) {
+				config.RateLimit.Write.PerMinute = -1
+			},
+			isErr: true,
+		},
+		{
+			description: "zero burst",
+			modify: func(config *Config) {
+				config.RateLimit.Write.Burst = 0
+			},
+			isErr: true,
+		},
+		{
+			description: "zero idempotency key window",
+			modify: func(config *Config) {
+				config.IdempotencyKey.WindowHours = 0
+			},
+			isErr: true,
+		},
+		{
+			description: "negative trash retention",
+			modify: func(config *Config) {
+				config.Trash.RetentionDays = -1
+			},
+			isErr: true,
+		},
+		{
+			description: "empty anonymous secret",
+			modify: func(config *Config) {
+				config.AnonymousSecret = ""
+			},
+			isErr: true,
+		},
+		{
+			description: "empty share link secret",
+			modify: func(config *Config) {
+				config.ShareLinkSecret = ""
+			},
+			isErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		config := validConfig()
+		testCase.modify(config)
+		err := config.Validate()
+		if testCase.isErr {
+			assert.Error(t, err, testCase.description)
+		} else {
+			assert.NoError(t, err, testCase.description)
+		}
+	}
+}
+
+func TestValidateAllErrors(t *testing.T) {
+	t.Parallel()
+
+	config := validConfig()
+	config.Server.Port = ""
+	config.Database.Port = 0
+
+	err := config.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "server.port(PORT)")
+		assert.Contains(t, err.Error(), "database.port(MARIADB_PORT)")
+	}
+}
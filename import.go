@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/traPtitech/anke-to/config"
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/router"
+)
+
+// importResponses import-responsesサブコマンド CSVから回答をインポートする
+func importResponses(conf *config.Config, args []string) error {
+	ctx := context.Background()
+
+	flags := flag.NewFlagSet("import-responses", flag.ExitOnError)
+	questionnaireID := flags.Int("questionnaire", 0, "インポート先のアンケートのID")
+	filePath := flags.String("file", "", "インポートするCSVファイルのパス")
+	mappingPath := flags.String("mapping", "", "CSVの列と質問の対応を書いたJSONファイルのパス (省略した場合は列名と質問文で対応させる)")
+	enteredBy := flags.String("entered-by", "", "代理入力した管理者として記録するtraQID")
+	dryRun := flags.Bool("dry-run", false, "検証のみを行い回答を追加しない")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *questionnaireID == 0 || *filePath == "" || *enteredBy == "" {
+		flags.Usage()
+		return errors.New("-questionnaire, -file and -entered-by are required")
+	}
+
+	mapping := router.ImportMapping{}
+	if *mappingPath != "" {
+		rawMapping, err := ioutil.ReadFile(*mappingPath)
+		if err != nil {
+			return fmt.Errorf("failed to read mapping: %w", err)
+		}
+		if err := json.Unmarshal(rawMapping, &mapping); err != nil {
+			return fmt.Errorf("failed to parse mapping: %w", err)
+		}
+	}
+
+	file, err := os.Open(*filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open csv: %w", err)
+	}
+	defer file.Close()
+
+	db, err := model.EstablishConnection(conf.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect db: %w", err)
+	}
+	defer db.Close()
+
+	err = model.SetAnonymousSecret(conf.AnonymousSecret)
+	if err != nil {
+		return fmt.Errorf("failed to set the anonymous secret: %w", err)
+	}
+
+	api, err := InjectAPIServer(conf)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	result, err := api.Response.ImportResponsesFromCSV(ctx, *questionnaireID, *enteredBy, mapping, file, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+
+	if len(result.Errors) != 0 {
+		return fmt.Errorf("%d of %d rows failed", len(result.Errors), result.Total)
+	}
+
+	return nil
+}
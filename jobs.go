@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/traq"
+)
+
+const (
+	trashPurgeInterval          = time.Hour
+	idempotencyKeyPurgeInterval = time.Hour
+	sessionPurgeInterval        = time.Hour
+	orphanCheckInterval         = 24 * time.Hour
+)
+
+// purgeTrash 保持期間を過ぎた削除済みのアンケートを定期的に完全に削除する
+func purgeTrash(questionnaire model.IQuestionnaire, retention time.Duration) {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := questionnaire.PurgeDeletedQuestionnaires(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("failed to purge deleted questionnaires: %v", err)
+		} else if count != 0 {
+			log.Printf("purged %d deleted questionnaires", count)
+		}
+
+		<-ticker.C
+	}
+}
+
+// purgeIdempotencyKeys 保持期間を過ぎたIdempotency-Keyを定期的に削除する
+func purgeIdempotencyKeys(idempotencyKey model.IIdempotencyKey) {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(idempotencyKeyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := idempotencyKey.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+		if err != nil {
+			log.Printf("failed to delete expired idempotency keys: %v", err)
+		} else if count != 0 {
+			log.Printf("deleted %d expired idempotency keys", count)
+		}
+
+		<-ticker.C
+	}
+}
+
+// purgeSessions 有効期限の切れたセッションを定期的に削除する
+func purgeSessions(session model.ISession) {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(sessionPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := session.DeleteExpiredSessions(ctx, time.Now())
+		if err != nil {
+			log.Printf("failed to delete expired sessions: %v", err)
+		} else if count != 0 {
+			log.Printf("deleted %d expired sessions", count)
+		}
+
+		<-ticker.C
+	}
+}
+
+/*
+flagOrphanedQuestionnaires 管理者が全員凍結されたアンケートに定期的に印を付けて通知する
+役割は問わず，管理者のグループは有効なメンバーがいれば有効な管理者とみなす
+*/
+func flagOrphanedQuestionnaires(administrator model.IAdministrator, questionnaire model.IQuestionnaire, user traq.IUser, group traq.IGroup, webhook traq.IWebhook) {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(orphanCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		managedQuestionnaireIDs, err := getManagedQuestionnaireIDs(ctx, administrator, user, group)
+		if err != nil {
+			log.Printf("failed to get managed questionnaires: %v", err)
+			<-ticker.C
+			continue
+		}
+
+		questionnaires, err := questionnaire.FlagOrphanedQuestionnaires(ctx, managedQuestionnaireIDs)
+		if err != nil {
+			log.Printf("failed to flag orphaned questionnaires: %v", err)
+		} else if len(questionnaires) != 0 {
+			log.Printf("flagged %d orphaned questionnaires", len(questionnaires))
+
+			questionnaireLinks := make([]string, 0, len(questionnaires))
+			for _, questionnaire := range questionnaires {
+				questionnaireLinks = append(questionnaireLinks, "- ["+questionnaire.Title+"](https://anke-to.trap.jp/questionnaires/"+strconv.Itoa(questionnaire.ID)+")")
+			}
+			err := webhook.PostMessage(
+				"### 有効な管理者がいないアンケートがあります\n" +
+					strings.Join(questionnaireLinks, "\n"))
+			if err != nil {
+				log.Printf("failed to post orphaned questionnaires: %v", err)
+			}
+		}
+
+		<-ticker.C
+	}
+}
+
+// getManagedQuestionnaireIDs 有効な管理者がいるアンケートのIDの取得
+func getManagedQuestionnaireIDs(ctx context.Context, administrator model.IAdministrator, user traq.IUser, group traq.IGroup) ([]int, error) {
+	administrators, err := administrator.GetAllAdministrators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	activeUsers, err := user.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	activeUserSet := make(map[string]struct{}, len(activeUsers))
+	for _, activeUser := range activeUsers {
+		activeUserSet[activeUser] = struct{}{}
+	}
+
+	groups, err := group.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	// Membersには凍結されたユーザーが含まれない
+	activeGroupSet := map[string]struct{}{}
+	for _, group := range groups {
+		if len(group.Members) != 0 {
+			activeGroupSet[group.ID] = struct{}{}
+		}
+	}
+
+	managedQuestionnaireIDSet := map[int]struct{}{}
+	for _, administrator := range administrators {
+		_, isActiveUser := activeUserSet[administrator.UserTraqid]
+		_, isActiveGroup := activeGroupSet[administrator.UserTraqid]
+		if isActiveUser || isActiveGroup {
+			managedQuestionnaireIDSet[administrator.QuestionnaireID] = struct{}{}
+		}
+	}
+
+	managedQuestionnaireIDs := make([]int, 0, len(managedQuestionnaireIDSet))
+	for questionnaireID := range managedQuestionnaireIDSet {
+		managedQuestionnaireIDs = append(managedQuestionnaireIDs, questionnaireID)
+	}
+
+	return managedQuestionnaireIDs, nil
+}
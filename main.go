@@ -6,14 +6,21 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"runtime"
+	"time"
 
+	"github.com/traPtitech/anke-to/config"
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/traq"
 	"github.com/traPtitech/anke-to/tuning"
 )
 
 func main() {
-	env := os.Getenv("ANKE-TO_ENV")
-	logOn := env == "pprof" || env == "dev"
+	conf, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logOn := conf.Env == "pprof" || conf.Env == "dev"
 
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -23,15 +30,25 @@ func main() {
 		case "bench":
 			tuning.Bench()
 			return
+		case "import-responses":
+			if err := importResponses(conf, os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
 		}
 	}
 
-	db, err := model.EstablishConnection()
+	db, err := model.EstablishConnection(conf.Database)
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
+	err = model.SetAnonymousSecret(conf.AnonymousSecret)
+	if err != nil {
+		panic(err)
+	}
+
 	err = model.Migrate()
 	if err != nil {
 		panic(err)
@@ -41,14 +58,26 @@ func main() {
 		db.LogMode(true)
 	}
 
-	if env == "pprof" {
+	if conf.Env == "pprof" {
 		runtime.SetBlockProfileRate(1)
 		go func() {
-			log.Println(http.ListenAndServe("0.0.0.0:6060", nil))
+			log.Println(http.ListenAndServe(conf.Server.PprofAddress, nil))
 		}()
 	}
 
-	port := os.Getenv("PORT")
+	// 保持期間が0の場合は完全な削除を行わない
+	if conf.Trash.RetentionDays > 0 {
+		go purgeTrash(model.NewQuestionnaire(), time.Duration(conf.Trash.RetentionDays)*24*time.Hour)
+	}
+
+	go purgeIdempotencyKeys(model.NewIdempotencyKey())
+	go purgeSessions(model.NewSession())
+	go flagOrphanedQuestionnaires(model.NewAdministrator(), model.NewQuestionnaire(), traq.NewUser(conf.TraQ), traq.NewGroup(conf.TraQ), traq.NewWebhook(conf.TraQ))
+
+	api, err := InjectAPIServer(conf)
+	if err != nil {
+		panic(err)
+	}
 
-	SetRouting(port)
+	SetRouting(api)
 }
@@ -1,11 +1,22 @@
 package model
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jinzhu/gorm"
 )
 
+// アンケートの管理者の役割
+const (
+	// RoleOwner アンケートの削除や管理者の変更を含む全ての操作ができる
+	RoleOwner = "owner"
+	// RoleEditor アンケートと質問の編集と結果の閲覧ができる
+	RoleEditor = "editor"
+	// RoleViewer 結果と回答状況の閲覧のみができる
+	RoleViewer = "viewer"
+)
+
 // Administrator AdministratorRepositoryの実装
 type Administrator struct{}
 
@@ -16,20 +27,23 @@ func NewAdministrator() *Administrator {
 
 // Administrators administratorsテーブルの構造体
 type Administrators struct {
-	QuestionnaireID int    `sql:"type:int(11);not null;primary_key;"`
-	UserTraqid      string `sql:"type:char(32);not null;primary_key;"`
+	QuestionnaireID int `sql:"type:int(11);not null;primary_key;"`
+	// UserTraqid traQIDまたはtraQのグループのID
+	UserTraqid string `sql:"type:char(36);not null;primary_key;"`
+	Role       string `sql:"type:char(10);not null;default:'owner';"`
 }
 
-// InsertAdministrators アンケートの管理者を追加
-func (*Administrator) InsertAdministrators(questionnaireID int, administrators []string) error {
+// InsertAdministrators アンケートの管理者を役割を指定して追加
+func (*Administrator) InsertAdministrators(ctx context.Context, questionnaireID int, administrators []string, role string) error {
 	var administrator Administrators
 	var err error
 	for _, v := range administrators {
 		administrator = Administrators{
 			QuestionnaireID: questionnaireID,
 			UserTraqid:      v,
+			Role:            role,
 		}
-		err = db.Create(&administrator).Error
+		err = getTx(ctx).Create(&administrator).Error
 		if err != nil {
 			return fmt.Errorf("failed to insert administrators: %w", err)
 		}
@@ -38,8 +52,8 @@ func (*Administrator) InsertAdministrators(questionnaireID int, administrators [
 }
 
 // DeleteAdministrators アンケートの管理者の削除
-func (*Administrator) DeleteAdministrators(questionnaireID int) error {
-	err := db.
+func (*Administrator) DeleteAdministrators(ctx context.Context, questionnaireID int) error {
+	err := getTx(ctx).
 		Where("questionnaire_id = ?", questionnaireID).
 		Delete(Administrators{}).Error
 	if err != nil {
@@ -50,9 +64,9 @@ func (*Administrator) DeleteAdministrators(questionnaireID int) error {
 }
 
 // GetAdministrators アンケートの管理者を取得
-func (*Administrator) GetAdministrators(questionnaireIDs []int) ([]Administrators, error) {
+func (*Administrator) GetAdministrators(ctx context.Context, questionnaireIDs []int) ([]Administrators, error) {
 	administrators := []Administrators{}
-	err := db.
+	err := getTx(ctx).
 		Where("questionnaire_id IN (?)", questionnaireIDs).
 		Find(&administrators).Error
 	if err != nil {
@@ -62,17 +76,78 @@ func (*Administrator) GetAdministrators(questionnaireIDs []int) ([]Administrator
 	return administrators, nil
 }
 
-// CheckQuestionnaireAdmin 自分がアンケートの管理者か判定
-func (*Administrator) CheckQuestionnaireAdmin(userID string, questionnaireID int) (bool, error) {
-	err := db.
+// GetQuestionnaireRole 自分のアンケートでの役割の取得 (管理者でない場合は空文字列)
+func (*Administrator) GetQuestionnaireRole(ctx context.Context, userID string, questionnaireID int) (string, error) {
+	administrator := Administrators{}
+	err := getTx(ctx).
 		Where("user_traqid = ? AND questionnaire_id = ?", userID, questionnaireID).
-		Find(&Administrators{}).Error
+		First(&administrator).Error
 	if gorm.IsRecordNotFoundError(err) {
-		return false, nil
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get a administrator: %w", err)
+	}
+
+	return administrator.Role, nil
+}
+
+// GetAllAdministrators 削除されていない全てのアンケートの管理者を取得
+func (*Administrator) GetAllAdministrators(ctx context.Context) ([]Administrators, error) {
+	administrators := []Administrators{}
+	err := getTx(ctx).
+		Table("administrators").
+		Joins("INNER JOIN questionnaires ON administrators.questionnaire_id = questionnaires.id").
+		Where("questionnaires.deleted_at IS NULL").
+		Select("administrators.*").
+		Find(&administrators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get administrators: %w", err)
 	}
+
+	return administrators, nil
+}
+
+/*
+TransferOwnership アンケートのオーナーを譲渡する
+譲渡したユーザーは編集者になる
+譲渡したユーザーがオーナーでない場合はErrNoRecordUpdatedを返す
+*/
+func (*Administrator) TransferOwnership(ctx context.Context, questionnaireID int, fromUserID string, toUserID string) error {
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		result := tx.
+			Model(&Administrators{}).
+			Where("questionnaire_id = ? AND user_traqid = ? AND role = ?", questionnaireID, fromUserID, RoleOwner).
+			Update("role", RoleEditor)
+		err := result.Error
+		if err != nil {
+			return fmt.Errorf("failed to update administrator: %w", err)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("failed to update administrator: %w", ErrNoRecordUpdated)
+		}
+
+		err = tx.
+			Where("questionnaire_id = ? AND user_traqid = ?", questionnaireID, toUserID).
+			Delete(&Administrators{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete administrator: %w", err)
+		}
+
+		err = tx.Create(&Administrators{
+			QuestionnaireID: questionnaireID,
+			UserTraqid:      toUserID,
+			Role:            RoleOwner,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to insert administrator: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to get a administrator: %w", err)
+		return fmt.Errorf("failed in the transaction: %w", err)
 	}
 
-	return true, nil
+	return nil
 }
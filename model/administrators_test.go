@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"testing"
 
 	"github.com/jinzhu/gorm"
@@ -29,7 +30,7 @@ func TestAministrators(t *testing.T) {
 	t.Run("InsertAdministrators", insertAdministratorsTest)
 	t.Run("DeleteAdministrators", deleteAdministratorsTest)
 	t.Run("GetAdministrators", getAdministratorsTest)
-	t.Run("CheckQuestionnaireAdmin", checkQuestionnaireAdminTest)
+	t.Run("GetQuestionnaireRole", getQuestionnaireRoleTest)
 }
 
 func setupAdministratorTest(t *testing.T) {
@@ -70,6 +71,8 @@ func setupAdministratorTest(t *testing.T) {
 
 func insertAdministratorsTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -167,7 +170,7 @@ func insertAdministratorsTest(t *testing.T) {
 			t.Errorf("failed to create questionnaire(%+v): %w", testCase.args.questionnaire, err)
 		}
 
-		err = administratorImpl.InsertAdministrators(testCase.args.questionnaire.ID, testCase.args.administrators)
+		err = administratorImpl.InsertAdministrators(ctx, testCase.args.questionnaire.ID, testCase.args.administrators, RoleOwner)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -191,6 +194,8 @@ func insertAdministratorsTest(t *testing.T) {
 
 func deleteAdministratorsTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -274,7 +279,7 @@ func deleteAdministratorsTest(t *testing.T) {
 			t.Errorf("failed to create questionnaire(%+v): %w", testCase.args.questionnaire, err)
 		}
 
-		err = administratorImpl.DeleteAdministrators(testCase.args.questionnaire.ID)
+		err = administratorImpl.DeleteAdministrators(ctx, testCase.args.questionnaire.ID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -297,6 +302,8 @@ func deleteAdministratorsTest(t *testing.T) {
 
 func getAdministratorsTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -326,6 +333,7 @@ func getAdministratorsTest(t *testing.T) {
 					{
 						QuestionnaireID: administratorTestQuestionnaireDatas[0].questionnaire.ID,
 						UserTraqid:      administratorsTestUserIDs[0],
+						Role:            RoleOwner,
 					},
 				},
 			},
@@ -340,6 +348,7 @@ func getAdministratorsTest(t *testing.T) {
 					{
 						QuestionnaireID: administratorTestQuestionnaireDatas[0].questionnaire.ID,
 						UserTraqid:      administratorsTestUserIDs[0],
+						Role:            RoleOwner,
 					},
 				},
 			},
@@ -365,7 +374,7 @@ func getAdministratorsTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualAdministrators, err := administratorImpl.GetAdministrators(testCase.args.questionnaireIDs)
+		actualAdministrators, err := administratorImpl.GetAdministrators(ctx, testCase.args.questionnaireIDs)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -380,8 +389,10 @@ func getAdministratorsTest(t *testing.T) {
 	}
 }
 
-func checkQuestionnaireAdminTest(t *testing.T) {
+func getQuestionnaireRoleTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -391,9 +402,9 @@ func checkQuestionnaireAdminTest(t *testing.T) {
 		questionnaireID int
 	}
 	type expect struct {
-		isAdmin bool
-		isErr   bool
-		err     error
+		role  string
+		isErr bool
+		err   error
 	}
 	type test struct {
 		description string
@@ -415,25 +426,38 @@ func checkQuestionnaireAdminTest(t *testing.T) {
 		invalidQuestionnaireID *= 10
 	}
 
+	viewerQuestionnaire := Questionnaires{
+		Title:       "第1回集会らん☆ぷろ募集アンケート",
+		Description: "第1回集会らん☆ぷろ参加者募集",
+	}
+	err := db.Create(&viewerQuestionnaire).Error
+	if err != nil {
+		t.Errorf("failed to create questionnaire(%+v): %v", viewerQuestionnaire, err)
+	}
+	err = administratorImpl.InsertAdministrators(ctx, viewerQuestionnaire.ID, []string{administratorsTestUserIDs[1]}, RoleViewer)
+	if err != nil {
+		t.Errorf("failed to insert viewer: %v", err)
+	}
+
 	testCases := []test{
 		{
-			description: "questionnaireID: valid, is_admin: true",
+			description: "questionnaireID: valid, role: owner",
 			args: args{
 				userID:          administratorsTestUserIDs[0],
 				questionnaireID: administratorTestQuestionnaireDatas[0].questionnaire.ID,
 			},
 			expect: expect{
-				isAdmin: true,
+				role: RoleOwner,
 			},
 		},
 		{
-			description: "questionnaireID: valid, is_admin: false",
+			description: "questionnaireID: valid, role: none",
 			args: args{
 				userID:          invalidAdministratorTestUserID,
 				questionnaireID: administratorTestQuestionnaireDatas[0].questionnaire.ID,
 			},
 			expect: expect{
-				isAdmin: false,
+				role: "",
 			},
 		},
 		{
@@ -443,13 +467,24 @@ func checkQuestionnaireAdminTest(t *testing.T) {
 				questionnaireID: invalidQuestionnaireID,
 			},
 			expect: expect{
-				isAdmin: false,
+				role: "",
 			},
 		},
 	}
 
+	testCases = append(testCases, test{
+		description: "questionnaireID: valid, role: viewer",
+		args: args{
+			userID:          administratorsTestUserIDs[1],
+			questionnaireID: viewerQuestionnaire.ID,
+		},
+		expect: expect{
+			role: RoleViewer,
+		},
+	})
+
 	for _, testCase := range testCases {
-		actualIsAdmin, err := administratorImpl.CheckQuestionnaireAdmin(testCase.args.userID, testCase.args.questionnaireID)
+		actualRole, err := administratorImpl.GetQuestionnaireRole(ctx, testCase.args.userID, testCase.args.questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -460,6 +495,6 @@ func checkQuestionnaireAdminTest(t *testing.T) {
 			continue
 		}
 
-		assertion.Equal(testCase.expect.isAdmin, actualIsAdmin, testCase.description, "isAdmin")
+		assertion.Equal(testCase.expect.role, actualRole, testCase.description, "role")
 	}
 }
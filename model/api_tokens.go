@@ -0,0 +1,18 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// IAPIToken APITokenのRepository
+type IAPIToken interface {
+	InsertAPIToken(ctx context.Context, userID string, name string, token string, scopes []string, expiresAt null.Time) (int, error)
+	GetAPITokens(ctx context.Context, userID string) ([]APITokens, error)
+	GetAPITokenByToken(ctx context.Context, token string) (*APITokens, error)
+	UpdateAPITokenLastUsedAt(ctx context.Context, tokenID int) error
+	DeleteAPIToken(ctx context.Context, userID string, tokenID int) error
+}
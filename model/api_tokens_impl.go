@@ -0,0 +1,111 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// APIToken APITokenRepositoryの実装
+type APIToken struct{}
+
+// NewAPIToken APITokenのコンストラクター
+func NewAPIToken() *APIToken {
+	return new(APIToken)
+}
+
+// APITokens api_tokensテーブルの構造体
+type APITokens struct {
+	ID         int    `json:"id"   gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	UserTraqid string `json:"-"    gorm:"type:char(30) NOT NULL;"`
+	Name       string `json:"name" gorm:"type:varchar(50) NOT NULL;"`
+	// TokenHash トークンが漏れないようにSHA-256のハッシュのみを保存する
+	TokenHash string `json:"-" gorm:"type:char(64) NOT NULL;"`
+	// Scopes スペース区切りのスコープ
+	Scopes     string    `json:"-"            gorm:"type:text NOT NULL;"`
+	CreatedAt  time.Time `json:"created_at"   gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	ExpiresAt  null.Time `json:"expires_at"   gorm:"type:timestamp NULL;default:NULL;"`
+	LastUsedAt null.Time `json:"last_used_at" gorm:"type:timestamp NULL;default:NULL;"`
+}
+
+// GetScopes スコープの一覧
+func (t *APITokens) GetScopes() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// InsertAPIToken APIトークンの追加
+func (*APIToken) InsertAPIToken(ctx context.Context, userID string, name string, token string, scopes []string, expiresAt null.Time) (int, error) {
+	apiToken := APITokens{
+		UserTraqid: userID,
+		Name:       name,
+		TokenHash:  hashSecret(token),
+		Scopes:     strings.Join(scopes, " "),
+		ExpiresAt:  expiresAt,
+	}
+
+	err := getTx(ctx).Create(&apiToken).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert an api token: %w", err)
+	}
+
+	return apiToken.ID, nil
+}
+
+// GetAPITokens ユーザーのAPIトークンの一覧の取得
+func (*APIToken) GetAPITokens(ctx context.Context, userID string) ([]APITokens, error) {
+	apiTokens := []APITokens{}
+	err := getTx(ctx).
+		Where("user_traqid = ?", userID).
+		Order("id").
+		Find(&apiTokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api tokens: %w", err)
+	}
+
+	return apiTokens, nil
+}
+
+// GetAPITokenByToken 有効期限内のAPIトークンの取得
+func (*APIToken) GetAPITokenByToken(ctx context.Context, token string) (*APITokens, error) {
+	apiToken := APITokens{}
+	err := getTx(ctx).
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", hashSecret(token), time.Now()).
+		First(&apiToken).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an api token: %w", err)
+	}
+
+	return &apiToken, nil
+}
+
+// UpdateAPITokenLastUsedAt APIトークンの最終使用日時の更新
+func (*APIToken) UpdateAPITokenLastUsedAt(ctx context.Context, tokenID int) error {
+	err := getTx(ctx).
+		Model(&APITokens{}).
+		Where("id = ?", tokenID).
+		Update("last_used_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to update last_used_at of an api token: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAPIToken ユーザーのAPIトークンの削除
+func (*APIToken) DeleteAPIToken(ctx context.Context, userID string, tokenID int) error {
+	result := getTx(ctx).
+		Where("id = ? AND user_traqid = ?", tokenID, userID).
+		Delete(&APITokens{})
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to delete an api token: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to delete an api token: %w", ErrNoRecordDeleted)
+	}
+
+	return nil
+}
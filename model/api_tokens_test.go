@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestInsertAPIToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	token := "anketo_insert-api-token"
+	scopes := []string{"results:read", "responses:write"}
+	tokenID, err := apiTokenImpl.InsertAPIToken(ctx, userOne, "CI", token, scopes, null.NewTime(time.Now().Add(time.Hour), true))
+	require.NoError(t, err)
+
+	apiToken, err := apiTokenImpl.GetAPITokenByToken(ctx, token)
+	require.NoError(t, err)
+	assertion.Equal(tokenID, apiToken.ID, "id")
+	assertion.Equal(userOne, apiToken.UserTraqid, "userID")
+	assertion.Equal("CI", apiToken.Name, "name")
+	assertion.Equal(scopes, apiToken.GetScopes(), "scopes")
+	assertion.NotEqual(token, apiToken.TokenHash, "token is hashed")
+	assertion.False(apiToken.LastUsedAt.Valid, "last_used_at")
+
+	err = apiTokenImpl.UpdateAPITokenLastUsedAt(ctx, tokenID)
+	require.NoError(t, err)
+
+	apiToken, err = apiTokenImpl.GetAPITokenByToken(ctx, token)
+	require.NoError(t, err)
+	assertion.True(apiToken.LastUsedAt.Valid, "last_used_at updated")
+
+	_, err = apiTokenImpl.GetAPITokenByToken(ctx, "anketo_unknown")
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "unknown token")
+}
+
+func TestGetAPITokenByTokenExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	expiredToken := "anketo_expired-api-token"
+	_, err := apiTokenImpl.InsertAPIToken(ctx, userTwo, "expired", expiredToken, []string{"results:read"}, null.NewTime(time.Now().Add(-time.Hour), true))
+	require.NoError(t, err)
+
+	_, err = apiTokenImpl.GetAPITokenByToken(ctx, expiredToken)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "expired token")
+
+	noExpiryToken := "anketo_no-expiry-api-token"
+	_, err = apiTokenImpl.InsertAPIToken(ctx, userTwo, "no expiry", noExpiryToken, []string{"results:read"}, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	_, err = apiTokenImpl.GetAPITokenByToken(ctx, noExpiryToken)
+	assertion.NoError(err, "no expiry token")
+}
+
+func TestGetAPITokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	tokenIDs := make([]int, 0, 2)
+	for _, name := range []string{"first", "second"} {
+		tokenID, err := apiTokenImpl.InsertAPIToken(ctx, userThree, name, "anketo_get-api-tokens-"+name, []string{"questionnaires:write"}, null.NewTime(time.Time{}, false))
+		require.NoError(t, err)
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+
+	apiTokens, err := apiTokenImpl.GetAPITokens(ctx, userThree)
+	require.NoError(t, err)
+
+	actualIDs := make([]int, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		assertion.Equal(userThree, apiToken.UserTraqid, "userID")
+		actualIDs = append(actualIDs, apiToken.ID)
+	}
+	assertion.Subset(actualIDs, tokenIDs, "tokenIDs")
+}
+
+func TestDeleteAPIToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	token := "anketo_delete-api-token"
+	tokenID, err := apiTokenImpl.InsertAPIToken(ctx, userOne, "delete", token, []string{"results:read"}, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	err = apiTokenImpl.DeleteAPIToken(ctx, userTwo, tokenID)
+	assertion.True(errors.Is(err, ErrNoRecordDeleted), "other user's token")
+
+	err = apiTokenImpl.DeleteAPIToken(ctx, userOne, tokenID)
+	assertion.NoError(err, "delete")
+
+	_, err = apiTokenImpl.GetAPITokenByToken(ctx, token)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "deleted token")
+
+	err = apiTokenImpl.DeleteAPIToken(ctx, userOne, tokenID)
+	assertion.True(errors.Is(err, ErrNoRecordDeleted), "already deleted")
+}
@@ -0,0 +1,15 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// IAuditLog AuditLogのRepository
+type IAuditLog interface {
+	InsertAuditLog(ctx context.Context, actor string, action string, targetType string, targetID int, before null.String, after null.String) error
+	GetAuditLogs(ctx context.Context, filter AuditLogFilter, beforeID int, limit int) ([]AuditLogs, error)
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// AuditLog AuditLogRepositoryの実装
+type AuditLog struct{}
+
+// NewAuditLog AuditLogのコンストラクター
+func NewAuditLog() *AuditLog {
+	return new(AuditLog)
+}
+
+// AuditLogs audit_logsテーブルの構造体
+// 追記のみを行い，アンケートが完全に削除されても残す
+type AuditLogs struct {
+	ID         int    `json:"id"          gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	Actor      string `json:"actor"       gorm:"type:char(30) NOT NULL;"`
+	Action     string `json:"action"      gorm:"type:varchar(50) NOT NULL;"`
+	TargetType string `json:"target_type" gorm:"type:varchar(30) NOT NULL;"`
+	TargetID   int    `json:"target_id"   gorm:"type:int(11) NOT NULL;"`
+	// Before 操作前の対象のJSON (作成の場合はNULL)
+	Before null.String `json:"before" gorm:"type:mediumtext NULL;default:NULL;"`
+	// After 操作後の対象のJSON (削除の場合はNULL)
+	After     null.String `json:"after"      gorm:"type:mediumtext NULL;default:NULL;"`
+	CreatedAt time.Time   `json:"created_at" gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+}
+
+// AuditLogFilter 監査ログの絞り込みの条件 (空の条件は使わない)
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   null.Int
+	Since      null.Time
+	Until      null.Time
+}
+
+// InsertAuditLog 監査ログの追加
+func (*AuditLog) InsertAuditLog(ctx context.Context, actor string, action string, targetType string, targetID int, before null.String, after null.String) error {
+	err := getTx(ctx).Create(&AuditLogs{
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to insert an audit log: %w", err)
+	}
+
+	return nil
+}
+
+/*
+GetAuditLogs 新しい順に監査ログを取得
+beforeIDが0でない場合はそれより前の監査ログのみを取得する
+*/
+func (*AuditLog) GetAuditLogs(ctx context.Context, filter AuditLogFilter, beforeID int, limit int) ([]AuditLogs, error) {
+	query := getTx(ctx).Model(&AuditLogs{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID.Valid {
+		query = query.Where("target_id = ?", filter.TargetID.Int64)
+	}
+	if filter.Since.Valid {
+		query = query.Where("created_at >= ?", filter.Since.Time)
+	}
+	if filter.Until.Valid {
+		query = query.Where("created_at < ?", filter.Until.Time)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	auditLogs := []AuditLogs{}
+	err := query.
+		Order("id DESC").
+		Limit(limit).
+		Find(&auditLogs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return auditLogs, nil
+}
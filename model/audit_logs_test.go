@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestInsertAuditLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	// 他のテストの監査ログと混ざらないように新しいアンケートを対象にする
+	questionnaireID := createShareLinkTestQuestionnaire(t)
+
+	err := auditLogImpl.InsertAuditLog(ctx, userOne, "questionnaire.create", "questionnaire", questionnaireID, null.NewString("", false), null.StringFrom(`{"title":"before"}`))
+	require.NoError(t, err)
+	err = auditLogImpl.InsertAuditLog(ctx, userTwo, "questionnaire.update", "questionnaire", questionnaireID, null.StringFrom(`{"title":"before"}`), null.StringFrom(`{"title":"after"}`))
+	require.NoError(t, err)
+
+	filter := AuditLogFilter{
+		TargetType: "questionnaire",
+		TargetID:   null.IntFrom(int64(questionnaireID)),
+	}
+	auditLogs, err := auditLogImpl.GetAuditLogs(ctx, filter, 0, 10)
+	require.NoError(t, err)
+	if assertion.Len(auditLogs, 2) {
+		assertion.Equal("questionnaire.update", auditLogs[0].Action, "newest first")
+		assertion.Equal(userTwo, auditLogs[0].Actor)
+		assertion.Equal(`{"title":"after"}`, auditLogs[0].After.String)
+		assertion.False(auditLogs[1].Before.Valid, "before of creation")
+		assertion.WithinDuration(time.Now(), auditLogs[0].CreatedAt, 2*time.Second)
+	}
+
+	filter.Actor = userOne
+	auditLogs, err = auditLogImpl.GetAuditLogs(ctx, filter, 0, 10)
+	require.NoError(t, err)
+	if assertion.Len(auditLogs, 1) {
+		assertion.Equal("questionnaire.create", auditLogs[0].Action)
+	}
+
+	filter.Actor = ""
+	filter.Action = "questionnaire.update"
+	auditLogs, err = auditLogImpl.GetAuditLogs(ctx, filter, 0, 10)
+	require.NoError(t, err)
+	assertion.Len(auditLogs, 1)
+
+	filter.Action = ""
+	filter.Since = null.TimeFrom(time.Now().Add(time.Hour))
+	auditLogs, err = auditLogImpl.GetAuditLogs(ctx, filter, 0, 10)
+	require.NoError(t, err)
+	assertion.Len(auditLogs, 0, "since")
+
+	filter.Since = null.Time{}
+	filter.Until = null.TimeFrom(time.Now().Add(-time.Hour))
+	auditLogs, err = auditLogImpl.GetAuditLogs(ctx, filter, 0, 10)
+	require.NoError(t, err)
+	assertion.Len(auditLogs, 0, "until")
+}
+
+func TestGetAuditLogsPage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createShareLinkTestQuestionnaire(t)
+	for i := 0; i < 3; i++ {
+		err := auditLogImpl.InsertAuditLog(ctx, userOne, "questionnaire.update", "questionnaire", questionnaireID, null.StringFrom("{}"), null.StringFrom("{}"))
+		require.NoError(t, err)
+	}
+
+	filter := AuditLogFilter{
+		TargetType: "questionnaire",
+		TargetID:   null.IntFrom(int64(questionnaireID)),
+	}
+	firstPage, err := auditLogImpl.GetAuditLogs(ctx, filter, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	assertion.Greater(firstPage[0].ID, firstPage[1].ID)
+
+	secondPage, err := auditLogImpl.GetAuditLogs(ctx, filter, firstPage[1].ID, 2)
+	require.NoError(t, err)
+	if assertion.Len(secondPage, 1) {
+		assertion.Less(secondPage[0].ID, firstPage[1].ID)
+	}
+}
@@ -2,9 +2,14 @@ package model
 
 import (
 	"fmt"
-	"os"
+	"net"
+	"strconv"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
+
+	"github.com/traPtitech/anke-to/config"
 )
 
 var (
@@ -13,38 +18,43 @@ var (
 		Questionnaires{},
 		Questions{},
 		Respondents{},
+		Revisions{},
 		Responses{},
 		Administrators{},
 		Options{},
+		OptionHistories{},
 		ScaleLabels{},
 		Targets{},
 		Validations{},
+		IdempotencyKeys{},
+		Sessions{},
+		APITokens{},
+		Invitations{},
+		ShareLinks{},
+		AuditLogs{},
 	}
 )
 
 // EstablishConnection DBと接続
-func EstablishConnection() (*gorm.DB, error) {
-	user := os.Getenv("MARIADB_USERNAME")
-	if user == "" {
-		user = "root"
-	}
-
-	pass := os.Getenv("MARIADB_PASSWORD")
-	if pass == "" {
-		pass = "password"
-	}
-
-	host := os.Getenv("MARIADB_HOSTNAME")
-	if host == "" {
-		host = "localhost"
+func EstablishConnection(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	location, err := time.LoadLocation(dbConfig.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location(%s): %w", dbConfig.Location, err)
 	}
 
-	dbname := os.Getenv("MARIADB_DATABASE")
-	if dbname == "" {
-		dbname = "anke-to"
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = dbConfig.Username
+	mysqlConfig.Passwd = dbConfig.Password
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(dbConfig.Hostname, strconv.Itoa(dbConfig.Port))
+	mysqlConfig.DBName = dbConfig.Database
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Loc = location
+	mysqlConfig.Params = map[string]string{
+		"charset": "utf8mb4",
 	}
 
-	_db, err := gorm.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:3306)/%s", user, pass, host, dbname)+"?parseTime=true&loc=Asia%2FTokyo&charset=utf8mb4")
+	_db, err := gorm.Open("mysql", mysqlConfig.FormatDSN())
 	db = _db
 	db = db.BlockGlobalUpdate(true)
 	db = db.Set("gorm:table_options", "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci")
@@ -59,6 +69,30 @@ func Migrate() error {
 		return fmt.Errorf("failed in table's migration: %w", err)
 	}
 
+	// traQのグループのIDも入るように広げる
+	// first_submitted_atの追加前に送信された回答は最後に送信した日時で埋める
+	err = db.
+		Model(&Respondents{}).
+		Where("first_submitted_at IS NULL AND submitted_at IS NOT NULL").
+		UpdateColumn("first_submitted_at", gorm.Expr("submitted_at")).Error
+	if err != nil {
+		return fmt.Errorf("failed to fill column(respondents.first_submitted_at): %w", err)
+	}
+
+	err = db.
+		Model(&Targets{}).
+		ModifyColumn("user_traqid", "char(36) NOT NULL").Error
+	if err != nil {
+		return fmt.Errorf("failed to modify column(targets.user_traqid): %w", err)
+	}
+
+	err = db.
+		Model(&Administrators{}).
+		ModifyColumn("user_traqid", "char(36) NOT NULL").Error
+	if err != nil {
+		return fmt.Errorf("failed to modify column(administrators.user_traqid): %w", err)
+	}
+
 	err = db.
 		Model(&Options{}).
 		AddUniqueIndex("question_id", "question_id", "option_num").Error
@@ -80,6 +114,13 @@ func Migrate() error {
 		return fmt.Errorf("failed to add foreingkey(options.question_id): %w", err)
 	}
 
+	err = db.
+		Model(&OptionHistories{}).
+		AddForeignKey("question_id", "question(id)", "RESTRICT", "RESTRICT").Error
+	if err != nil {
+		return fmt.Errorf("failed to add foreingkey(option_histories.question_id): %w", err)
+	}
+
 	err = db.
 		Model(&Questions{}).
 		AddForeignKey("questionnaire_id", "questionnaires(id)", "RESTRICT", "RESTRICT").Error
@@ -94,6 +135,56 @@ func Migrate() error {
 		return fmt.Errorf("failed to add foreingkey(respondents.questionnaire_id): %w", err)
 	}
 
+	err = db.
+		Model(&Respondents{}).
+		AddForeignKey("revision_id", "questionnaire_revisions(id)", "RESTRICT", "RESTRICT").Error
+	if err != nil {
+		return fmt.Errorf("failed to add foreingkey(respondents.revision_id): %w", err)
+	}
+
+	// 回答モードによる回答数の制限を同時実行時にも保証する
+	err = db.
+		Model(&Respondents{}).
+		AddUniqueIndex("respondent_response_slot", "questionnaire_id", "user_traqid", "response_slot").Error
+	if err != nil {
+		return fmt.Errorf("failed to add unique index(respondent_response_slot): %w", err)
+	}
+
+	err = db.
+		Model(&IdempotencyKeys{}).
+		AddUniqueIndex("user_traqid_idempotency_key", "user_traqid", "idempotency_key").Error
+	if err != nil {
+		return fmt.Errorf("failed to add unique index(user_traqid_idempotency_key): %w", err)
+	}
+
+	err = db.
+		Model(&APITokens{}).
+		AddUniqueIndex("token_hash", "token_hash").Error
+	if err != nil {
+		return fmt.Errorf("failed to add unique index(token_hash): %w", err)
+	}
+
+	err = db.
+		Model(&APITokens{}).
+		AddIndex("api_tokens_user_traqid", "user_traqid").Error
+	if err != nil {
+		return fmt.Errorf("failed to add index(api_tokens_user_traqid): %w", err)
+	}
+
+	err = db.
+		Model(&Revisions{}).
+		AddUniqueIndex("questionnaire_id_revision", "questionnaire_id", "revision").Error
+	if err != nil {
+		return fmt.Errorf("failed to add unique index(questionnaire_id_revision): %w", err)
+	}
+
+	err = db.
+		Model(&Revisions{}).
+		AddForeignKey("questionnaire_id", "questionnaires(id)", "RESTRICT", "RESTRICT").Error
+	if err != nil {
+		return fmt.Errorf("failed to add foreingkey(questionnaire_revisions.questionnaire_id): %w", err)
+	}
+
 	err = db.
 		Model(&Responses{}).
 		AddForeignKey("response_id", "respondents(response_id)", "RESTRICT", "RESTRICT").Error
@@ -129,5 +220,48 @@ func Migrate() error {
 		return fmt.Errorf("failed to add foreingkey(validations.question_id): %w", err)
 	}
 
+	// 同じユーザーへの招待は1つまで
+	err = db.
+		Model(&Invitations{}).
+		AddUniqueIndex("invitations_questionnaire_id_user_traqid", "questionnaire_id", "user_traqid").Error
+	if err != nil {
+		return fmt.Errorf("failed to add unique index(invitations_questionnaire_id_user_traqid): %w", err)
+	}
+
+	err = db.
+		Model(&Invitations{}).
+		AddIndex("invitations_user_traqid", "user_traqid").Error
+	if err != nil {
+		return fmt.Errorf("failed to add index(invitations_user_traqid): %w", err)
+	}
+
+	err = db.
+		Model(&Invitations{}).
+		AddForeignKey("questionnaire_id", "questionnaires(id)", "RESTRICT", "RESTRICT").Error
+	if err != nil {
+		return fmt.Errorf("failed to add foreingkey(invitations.questionnaire_id): %w", err)
+	}
+
+	err = db.
+		Model(&ShareLinks{}).
+		AddForeignKey("questionnaire_id", "questionnaires(id)", "RESTRICT", "RESTRICT").Error
+	if err != nil {
+		return fmt.Errorf("failed to add foreingkey(share_links.questionnaire_id): %w", err)
+	}
+
+	err = db.
+		Model(&AuditLogs{}).
+		AddIndex("audit_logs_actor", "actor").Error
+	if err != nil {
+		return fmt.Errorf("failed to add index(audit_logs_actor): %w", err)
+	}
+
+	err = db.
+		Model(&AuditLogs{}).
+		AddIndex("audit_logs_target", "target_type", "target_id").Error
+	if err != nil {
+		return fmt.Errorf("failed to add index(audit_logs_target): %w", err)
+	}
+
 	return nil
 }
@@ -3,6 +3,8 @@ package model
 import (
 	"os"
 	"testing"
+
+	"github.com/traPtitech/anke-to/config"
 )
 
 const (
@@ -12,23 +14,43 @@ const (
 )
 
 var (
-	administratorImpl = new(Administrator)
-	questionnaireImpl = new(Questionnaire)
-	questionImpl      = new(Question)
-	respondentImpl    = new(Respondent)
-	responseImpl      = new(Response)
-	scaleLabelImpl    = new(ScaleLabel)
-	validationImpl    = new(Validation)
+	administratorImpl  = new(Administrator)
+	apiTokenImpl       = new(APIToken)
+	auditLogImpl       = new(AuditLog)
+	idempotencyKeyImpl = new(IdempotencyKey)
+	invitationImpl     = new(Invitation)
+	questionnaireImpl  = new(Questionnaire)
+	optionImpl         = new(Option)
+	questionImpl       = new(Question)
+	respondentImpl     = new(Respondent)
+	responseImpl       = new(Response)
+	revisionImpl       = new(Revision)
+	scaleLabelImpl     = new(ScaleLabel)
+	sessionImpl        = new(Session)
+	shareLinkImpl      = new(ShareLink)
+	targetImpl         = new(Target)
+	transactionImpl    = new(Transaction)
+	validationImpl     = new(Validation)
 )
 
 //TestMain テストのmain
 func TestMain(m *testing.M) {
-	db, err := EstablishConnection()
+	conf, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := EstablishConnection(conf.Database)
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
+	err = SetAnonymousSecret(conf.AnonymousSecret)
+	if err != nil {
+		panic(err)
+	}
+
 	err = Migrate()
 	if err != nil {
 		panic(err)
@@ -23,4 +23,20 @@ var (
 	ErrTextMatching = errors.New("failed to match the pattern")
 	// ErrInvalidAnsweredParam invalid sort param
 	ErrInvalidAnsweredParam = errors.New("invalid answered param")
+	// ErrResponseAlreadyExists 回答の上限に達している
+	ErrResponseAlreadyExists = errors.New("the response already exists")
+	// ErrAnonymityLocked 回答があるアンケートの匿名設定は変更できない
+	ErrAnonymityLocked = errors.New("the anonymity of the questionnaire with responses cannot be changed")
+	// ErrResponseModeConflict 既にある回答が変更後の回答モードの上限を超えている
+	ErrResponseModeConflict = errors.New("the existing responses exceed the limit of the response mode")
+	// ErrNoAnonymousSecret 匿名の回答者のハッシュ化に使う秘密の値が設定されていない
+	ErrNoAnonymousSecret = errors.New("the anonymous secret is not set")
+	// ErrIdempotencyKeyExists 同じIdempotency-Keyのリクエストが既にある
+	ErrIdempotencyKeyExists = errors.New("the idempotency key already exists")
+	// ErrInvalidCursor ページのカーソルが不正
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidFilter 回答のフィルターが不正
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvitationExists 同じユーザーへの招待が既にある
+	ErrInvitationExists = errors.New("the invitation already exists")
 )
@@ -0,0 +1,423 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// maxFilterLength フィルターの最大の長さ
+	maxFilterLength = 1000
+	// maxFilterDepth フィルターの括弧とnotの最大の深さ
+	maxFilterDepth = 32
+)
+
+// filterNode フィルターの構文木のノード
+type filterNode interface {
+	// toSQL respondentsに対する条件に変換する
+	toSQL(questions []Questions) (string, []interface{}, error)
+}
+
+// filterAnd A and B
+type filterAnd struct {
+	left  filterNode
+	right filterNode
+}
+
+// filterOr A or B
+type filterOr struct {
+	left  filterNode
+	right filterNode
+}
+
+// filterNot not A
+type filterNot struct {
+	node filterNode
+}
+
+// filterComparison q<質問番号> <演算子> <値>
+type filterComparison struct {
+	questionNum int
+	operator    string
+	value       filterToken
+}
+
+func (node *filterAnd) toSQL(questions []Questions) (string, []interface{}, error) {
+	return binaryFilterToSQL("AND", node.left, node.right, questions)
+}
+
+func (node *filterOr) toSQL(questions []Questions) (string, []interface{}, error) {
+	return binaryFilterToSQL("OR", node.left, node.right, questions)
+}
+
+func binaryFilterToSQL(operator string, left filterNode, right filterNode, questions []Questions) (string, []interface{}, error) {
+	leftSQL, leftArgs, err := left.toSQL(questions)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSQL, rightArgs, err := right.toSQL(questions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "(" + leftSQL + " " + operator + " " + rightSQL + ")", append(leftArgs, rightArgs...), nil
+}
+
+func (node *filterNot) toSQL(questions []Questions) (string, []interface{}, error) {
+	sql, args, err := node.node.toSQL(questions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "NOT " + sql, args, nil
+}
+
+func (node *filterComparison) toSQL(questions []Questions) (string, []interface{}, error) {
+	if node.questionNum <= 0 || node.questionNum > len(questions) {
+		return "", nil, fmt.Errorf("question q%d does not exist: %w", node.questionNum, ErrInvalidFilter)
+	}
+	question := questions[node.questionNum-1]
+
+	isNumeric := question.Type == "Number" || question.Type == "LinearScale"
+	isOption := question.Type == "MultipleChoice" || question.Type == "Checkbox" || question.Type == "Dropdown"
+
+	var condition string
+	var value interface{}
+	negate := false
+	switch node.operator {
+	case "contains":
+		if node.value.kind != filterTokenString {
+			return "", nil, fmt.Errorf("contains needs a string at %d: %w", node.value.pos, ErrInvalidFilter)
+		}
+		if isOption {
+			condition = "filter_response.body = ?"
+			value = node.value.text
+		} else {
+			condition = "filter_response.body LIKE ?"
+			value = "%" + escapeLike(node.value.text) + "%"
+		}
+	case "=", "!=":
+		negate = node.operator == "!="
+		if isNumeric && node.value.kind == filterTokenNumber {
+			condition = "filter_response.body <> '' AND filter_response.body + 0 = ?"
+			value = node.value.number
+		} else {
+			condition = "filter_response.body = ?"
+			value = node.value.text
+		}
+	case "<", "<=", ">", ">=":
+		if !isNumeric {
+			return "", nil, fmt.Errorf("%s is only for Number or LinearScale questions, but q%d is %s: %w", node.operator, node.questionNum, question.Type, ErrInvalidFilter)
+		}
+		if node.value.kind != filterTokenNumber {
+			return "", nil, fmt.Errorf("%s needs a number at %d: %w", node.operator, node.value.pos, ErrInvalidFilter)
+		}
+		condition = "filter_response.body <> '' AND filter_response.body + 0 " + node.operator + " ?"
+		value = node.value.number
+	default:
+		return "", nil, fmt.Errorf("unknown operator %s: %w", node.operator, ErrInvalidFilter)
+	}
+
+	sql := "EXISTS (SELECT 1 FROM response AS filter_response WHERE filter_response.response_id = respondents.response_id AND filter_response.question_id = ? AND filter_response.deleted_at IS NULL AND " + condition + ")"
+	if negate {
+		sql = "NOT " + sql
+	}
+
+	return sql, []interface{}{question.ID, value}, nil
+}
+
+// escapeLike LIKEのワイルドカードのエスケープ
+func escapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(str)
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenQuestion
+	filterTokenKeyword
+	filterTokenOperator
+	filterTokenString
+	filterTokenNumber
+	filterTokenLeftParen
+	filterTokenRightParen
+)
+
+// filterToken フィルターの字句
+type filterToken struct {
+	kind filterTokenKind
+	// text 演算子やキーワードは小文字，文字列はエスケープを戻した値
+	text   string
+	number float64
+	// pos フィルターの先頭を1文字目とした位置
+	pos int
+}
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	runes := []rune(filter)
+	tokens := []filterToken{}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenLeftParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenRightParen, text: ")", pos: pos})
+			i++
+		case r == '=':
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: "=", pos: pos})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				operator += "="
+			}
+			if operator == "!" {
+				return nil, fmt.Errorf("unexpected character %q at %d: %w", r, pos, ErrInvalidFilter)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: operator, pos: pos})
+			i += len(operator)
+		case r == '"':
+			var builder strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					builder.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				builder.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at %d: %w", pos, ErrInvalidFilter)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: builder.String(), pos: pos})
+		case r == '-' || r == '.' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (runes[i] == '.' || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			text := string(runes[start:i])
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at %d: %w", text, pos, ErrInvalidFilter)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, text: text, number: number, pos: pos})
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := strings.ToLower(string(runes[start:i]))
+			switch word {
+			case "and", "or", "not":
+				tokens = append(tokens, filterToken{kind: filterTokenKeyword, text: word, pos: pos})
+			case "contains":
+				tokens = append(tokens, filterToken{kind: filterTokenOperator, text: word, pos: pos})
+			default:
+				questionNum, err := strconv.Atoi(strings.TrimPrefix(word, "q"))
+				if !strings.HasPrefix(word, "q") || err != nil {
+					return nil, fmt.Errorf("unexpected word %s at %d: %w", word, pos, ErrInvalidFilter)
+				}
+				tokens = append(tokens, filterToken{kind: filterTokenQuestion, text: word, number: float64(questionNum), pos: pos})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d: %w", r, pos, ErrInvalidFilter)
+		}
+	}
+
+	return append(tokens, filterToken{kind: filterTokenEOF, pos: len(runes) + 1}), nil
+}
+
+// filterParser 再帰下降によるフィルターの構文解析器
+//
+// expr       = and ("or" and)*
+// and        = unary ("and" unary)*
+// unary      = "not" unary | "(" expr ")" | comparison
+// comparison = question operator value
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	depth  int
+}
+
+// parseFilter フィルターを構文木に変換する
+func parseFilter(filter string) (filterNode, error) {
+	if len(filter) > maxFilterLength {
+		return nil, fmt.Errorf("the filter is longer than %d: %w", maxFilterLength, ErrInvalidFilter)
+	}
+
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{
+		tokens: tokens,
+	}
+	node, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.kind != filterTokenEOF {
+		return nil, fmt.Errorf("unexpected %s at %d: %w", token.text, token.pos, ErrInvalidFilter)
+	}
+
+	return node, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.pos]
+	if token.kind != filterTokenEOF {
+		p.pos++
+	}
+
+	return token
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == filterTokenKeyword && token.text == keyword
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		node = &filterOr{left: node, right: right}
+	}
+
+	return node, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		node = &filterAnd{left: node, right: right}
+	}
+
+	return node, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	p.depth++
+	defer func() {
+		p.depth--
+	}()
+	if p.depth > maxFilterDepth {
+		return nil, fmt.Errorf("the filter is nested deeper than %d: %w", maxFilterDepth, ErrInvalidFilter)
+	}
+
+	token := p.peek()
+	switch {
+	case p.isKeyword("not"):
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{node: node}, nil
+	case token.kind == filterTokenLeftParen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != filterTokenRightParen {
+			return nil, fmt.Errorf("missing ) for ( at %d: %w", token.pos, ErrInvalidFilter)
+		}
+		return node, nil
+	case token.kind == filterTokenQuestion:
+		return p.parseComparison()
+	case token.kind == filterTokenEOF:
+		return nil, fmt.Errorf("unexpected end of the filter: %w", ErrInvalidFilter)
+	default:
+		return nil, fmt.Errorf("expected a question like q1 at %d, but got %s: %w", token.pos, token.text, ErrInvalidFilter)
+	}
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	question := p.next()
+
+	operator := p.next()
+	if operator.kind != filterTokenOperator {
+		return nil, fmt.Errorf("expected an operator after %s at %d: %w", question.text, operator.pos, ErrInvalidFilter)
+	}
+
+	value := p.next()
+	if value.kind != filterTokenString && value.kind != filterTokenNumber {
+		return nil, fmt.Errorf("expected a string or a number after %s at %d: %w", operator.text, value.pos, ErrInvalidFilter)
+	}
+
+	return &filterComparison{
+		questionNum: int(question.number),
+		operator:    operator.text,
+		value:       value,
+	}, nil
+}
+
+// setRespondentsFilter フィルターを回答一覧の条件に追加する
+func setRespondentsFilter(ctx context.Context, query *gorm.DB, questionnaireID int, filter string) (*gorm.DB, error) {
+	node, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// q<n>はsortと同じくn番目の質問を表す
+	questions := []Questions{}
+	err = getTx(ctx).
+		Where("questionnaire_id = ? AND deleted_at IS NULL", questionnaireID).
+		Order("question_num").
+		Select("id, type").
+		Find(&questions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questions: %w", err)
+	}
+
+	sql, args, err := node.toSQL(questions)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.Where(sql, args...), nil
+}
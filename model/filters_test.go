@@ -0,0 +1,224 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	assertion := assert.New(t)
+
+	questions := []Questions{
+		{ID: 11, Type: "Checkbox"},
+		{ID: 12, Type: "Text"},
+		{ID: 13, Type: "LinearScale"},
+	}
+
+	type expect struct {
+		isErr bool
+		sql   string
+		args  []interface{}
+	}
+	type test struct {
+		description string
+		filter      string
+		expect
+	}
+
+	existsSQL := func(condition string) string {
+		return "EXISTS (SELECT 1 FROM response AS filter_response WHERE filter_response.response_id = respondents.response_id AND filter_response.question_id = ? AND filter_response.deleted_at IS NULL AND " + condition + ")"
+	}
+
+	testCases := []test{
+		{
+			description: "contains option and number comparison",
+			filter:      `q1 contains "CTF班" and q3 >= 4`,
+			expect: expect{
+				sql:  "(" + existsSQL("filter_response.body = ?") + " AND " + existsSQL("filter_response.body <> '' AND filter_response.body + 0 >= ?") + ")",
+				args: []interface{}{11, "CTF班", 13, float64(4)},
+			},
+		},
+		{
+			description: "contains text escapes wildcards",
+			filter:      `q2 contains "100%_\"ok\""`,
+			expect: expect{
+				sql:  existsSQL("filter_response.body LIKE ?"),
+				args: []interface{}{12, `%100\%\_"ok"%`},
+			},
+		},
+		{
+			description: "or binds weaker than and",
+			filter:      `q1 contains "a" or q1 contains "b" and not q3 = 1`,
+			expect: expect{
+				sql:  "(" + existsSQL("filter_response.body = ?") + " OR (" + existsSQL("filter_response.body = ?") + " AND NOT " + existsSQL("filter_response.body <> '' AND filter_response.body + 0 = ?") + "))",
+				args: []interface{}{11, "a", 11, "b", 13, float64(1)},
+			},
+		},
+		{
+			description: "parentheses and !=",
+			filter:      `(q1 contains "a" OR q2 = "x") AND q2 != "y"`,
+			expect: expect{
+				sql:  "((" + existsSQL("filter_response.body = ?") + " OR " + existsSQL("filter_response.body = ?") + ") AND NOT " + existsSQL("filter_response.body = ?") + ")",
+				args: []interface{}{11, "a", 12, "x", 12, "y"},
+			},
+		},
+		{
+			description: "question does not exist",
+			filter:      `q4 = "a"`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "comparison on text question",
+			filter:      `q2 > 1`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "contains with number",
+			filter:      `q1 contains 1`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "unterminated string",
+			filter:      `q1 contains "a`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "missing )",
+			filter:      `(q1 contains "a"`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "trailing token",
+			filter:      `q1 contains "a" "b"`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "sql injection",
+			filter:      `q1 = "a"; DROP TABLE response`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "empty",
+			filter:      ``,
+			expect: expect{
+				isErr: true,
+			},
+		},
+		{
+			description: "too deep",
+			filter:      `not not not not not not not not not not not not not not not not not not not not not not not not not not not not not not not not q1 = "a"`,
+			expect: expect{
+				isErr: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		node, err := parseFilter(testCase.filter)
+		if err == nil {
+			var sql string
+			var args []interface{}
+			sql, args, err = node.toSQL(questions)
+			if err == nil {
+				assertion.Equal(testCase.expect.sql, sql, testCase.description, "sql")
+				assertion.Equal(testCase.expect.args, args, testCase.description, "args")
+			}
+		}
+
+		if testCase.expect.isErr {
+			assertion.True(errors.Is(err, ErrInvalidFilter), testCase.description, "errorIs")
+		} else {
+			assertion.NoError(err, testCase.description, "no error")
+		}
+	}
+}
+
+func TestGetRespondentDetailsPageFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	checkboxID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Checkbox", "所属班", true)
+	require.NoError(t, err)
+	scaleID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 2, "LinearScale", "満足度", true)
+	require.NoError(t, err)
+
+	responseMetasList := [][]*ResponseMeta{
+		{
+			{QuestionID: checkboxID, Data: "CTF班"},
+			{QuestionID: checkboxID, Data: "SysAd班"},
+			{QuestionID: scaleID, Data: "5"},
+		},
+		{
+			{QuestionID: checkboxID, Data: "CTF班"},
+			{QuestionID: scaleID, Data: "3"},
+		},
+		{
+			{QuestionID: checkboxID, Data: "SysAd班"},
+			{QuestionID: scaleID, Data: "4"},
+		},
+	}
+	responseIDs := make([]int, 0, len(responseMetasList))
+	for _, responseMetas := range responseMetasList {
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+		require.NoError(t, err)
+
+		err = responseImpl.InsertResponses(ctx, responseID, responseMetas)
+		require.NoError(t, err)
+
+		responseIDs = append(responseIDs, responseID)
+	}
+
+	testCases := []struct {
+		filter      string
+		responseIDs []int
+	}{
+		{filter: `q1 contains "CTF班" and q2 >= 4`, responseIDs: []int{responseIDs[0]}},
+		{filter: `q1 contains "CTF班"`, responseIDs: []int{responseIDs[0], responseIDs[1]}},
+		{filter: `not q1 contains "CTF班" or q2 = 3`, responseIDs: []int{responseIDs[1], responseIDs[2]}},
+	}
+
+	for _, testCase := range testCases {
+		respondentDetails, _, err := respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, "", testCase.filter, 0, "")
+		require.NoError(t, err, testCase.filter)
+
+		actualIDs := make([]int, 0, len(respondentDetails))
+		for _, respondentDetail := range respondentDetails {
+			actualIDs = append(actualIDs, respondentDetail.ResponseID)
+		}
+		assertion.Equal(testCase.responseIDs, actualIDs, testCase.filter)
+	}
+
+	_, _, err = respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, "", `q3 = "a"`, 0, "")
+	assertion.True(errors.Is(err, ErrInvalidFilter), "question does not exist")
+}
@@ -0,0 +1,17 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// IIdempotencyKey IdempotencyKeyのRepository
+type IIdempotencyKey interface {
+	InsertIdempotencyKey(ctx context.Context, userID string, key string, requestHash string, expiresAt time.Time) error
+	GetIdempotencyKey(ctx context.Context, userID string, key string) (*IdempotencyKeys, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, userID string, key string, statusCode int, responseBody string) error
+	DeleteIdempotencyKey(ctx context.Context, userID string, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int, error)
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gopkg.in/guregu/null.v3"
+)
+
+// IdempotencyKey IdempotencyKeyRepositoryの実装
+type IdempotencyKey struct{}
+
+// NewIdempotencyKey IdempotencyKeyのコンストラクター
+func NewIdempotencyKey() *IdempotencyKey {
+	return new(IdempotencyKey)
+}
+
+// IdempotencyKeys idempotency_keysテーブルの構造体
+type IdempotencyKeys struct {
+	ID             int         `gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	UserTraqid     string      `gorm:"type:char(30) NOT NULL;"`
+	IdempotencyKey string      `gorm:"type:char(64) NOT NULL;"`
+	RequestHash    string      `gorm:"type:char(64) NOT NULL;"`
+	StatusCode     null.Int    `gorm:"type:int(11) NULL;default:NULL;"`
+	ResponseBody   null.String `gorm:"type:mediumtext NULL;"`
+	CreatedAt      time.Time   `gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	ExpiresAt      time.Time   `gorm:"type:timestamp NOT NULL;"`
+}
+
+// mysqlErrDuplicateEntry UNIQUE制約違反のエラー番号
+const mysqlErrDuplicateEntry = 1062
+
+// InsertIdempotencyKey 処理中のIdempotency-Keyの追加
+// 同じユーザーの同じキーが既にある場合はErrIdempotencyKeyExistsを返す
+func (*IdempotencyKey) InsertIdempotencyKey(ctx context.Context, userID string, key string, requestHash string, expiresAt time.Time) error {
+	idempotencyKey := IdempotencyKeys{
+		UserTraqid:     userID,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		ExpiresAt:      expiresAt,
+	}
+
+	err := getTx(ctx).Create(&idempotencyKey).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return fmt.Errorf("failed to insert an idempotency key: %w", ErrIdempotencyKeyExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert an idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey Idempotency-Keyの取得
+func (*IdempotencyKey) GetIdempotencyKey(ctx context.Context, userID string, key string) (*IdempotencyKeys, error) {
+	idempotencyKey := IdempotencyKeys{}
+	err := getTx(ctx).
+		Where("user_traqid = ? AND idempotency_key = ?", userID, key).
+		First(&idempotencyKey).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an idempotency key: %w", err)
+	}
+
+	return &idempotencyKey, nil
+}
+
+// UpdateIdempotencyKeyResponse 処理が完了したリクエストのレスポンスを保存
+func (*IdempotencyKey) UpdateIdempotencyKeyResponse(ctx context.Context, userID string, key string, statusCode int, responseBody string) error {
+	result := getTx(ctx).
+		Model(&IdempotencyKeys{}).
+		Where("user_traqid = ? AND idempotency_key = ?", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": responseBody,
+		})
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to update an idempotency key: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to update an idempotency key: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey Idempotency-Keyの削除
+func (*IdempotencyKey) DeleteIdempotencyKey(ctx context.Context, userID string, key string) error {
+	err := getTx(ctx).
+		Where("user_traqid = ? AND idempotency_key = ?", userID, key).
+		Delete(&IdempotencyKeys{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete an idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys 保持期間を過ぎたIdempotency-Keyの削除
+func (*IdempotencyKey) DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int, error) {
+	result := getTx(ctx).
+		Where("expires_at < ?", expiredBefore).
+		Delete(&IdempotencyKeys{})
+	err := result.Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return int(result.RowsAffected), nil
+}
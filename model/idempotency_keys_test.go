@@ -0,0 +1,73 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	key := "insert-idempotency-key"
+	err := idempotencyKeyImpl.InsertIdempotencyKey(ctx, userOne, key, "hash", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	err = idempotencyKeyImpl.InsertIdempotencyKey(ctx, userOne, key, "hash", time.Now().Add(time.Hour))
+	assertion.True(errors.Is(err, ErrIdempotencyKeyExists), "same user and key")
+
+	err = idempotencyKeyImpl.InsertIdempotencyKey(ctx, userTwo, key, "hash", time.Now().Add(time.Hour))
+	assertion.NoError(err, "another user")
+
+	idempotencyKey, err := idempotencyKeyImpl.GetIdempotencyKey(ctx, userOne, key)
+	require.NoError(t, err)
+	assertion.Equal("hash", idempotencyKey.RequestHash, "requestHash")
+	assertion.False(idempotencyKey.StatusCode.Valid, "statusCode before update")
+
+	err = idempotencyKeyImpl.UpdateIdempotencyKeyResponse(ctx, userOne, key, 201, `{"responseID":1}`)
+	assertion.NoError(err, "update")
+
+	idempotencyKey, err = idempotencyKeyImpl.GetIdempotencyKey(ctx, userOne, key)
+	require.NoError(t, err)
+	assertion.Equal(int64(201), idempotencyKey.StatusCode.Int64, "statusCode")
+	assertion.Equal(`{"responseID":1}`, idempotencyKey.ResponseBody.String, "responseBody")
+
+	err = idempotencyKeyImpl.DeleteIdempotencyKey(ctx, userOne, key)
+	assertion.NoError(err, "delete")
+
+	_, err = idempotencyKeyImpl.GetIdempotencyKey(ctx, userOne, key)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "get after delete")
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	err := idempotencyKeyImpl.InsertIdempotencyKey(ctx, userThree, "expired-idempotency-key", "hash", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	err = idempotencyKeyImpl.InsertIdempotencyKey(ctx, userThree, "valid-idempotency-key", "hash", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	count, err := idempotencyKeyImpl.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	assertion.NoError(err)
+	assertion.GreaterOrEqual(count, 1, "count")
+
+	_, err = idempotencyKeyImpl.GetIdempotencyKey(ctx, userThree, "expired-idempotency-key")
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "expired key")
+
+	_, err = idempotencyKeyImpl.GetIdempotencyKey(ctx, userThree, "valid-idempotency-key")
+	assertion.NoError(err, "valid key")
+}
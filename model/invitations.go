@@ -0,0 +1,16 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+)
+
+// IInvitation InvitationのRepository
+type IInvitation interface {
+	InsertInvitation(ctx context.Context, questionnaireID int, userID string, role string, invitedBy string) (int, error)
+	GetInvitations(ctx context.Context, questionnaireID int) ([]Invitations, error)
+	GetUserInvitations(ctx context.Context, userID string) ([]InvitationInfo, error)
+	AcceptInvitation(ctx context.Context, userID string, invitationID int) (*Invitations, error)
+	DeclineInvitation(ctx context.Context, userID string, invitationID int) error
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+)
+
+// Invitation InvitationRepositoryの実装
+type Invitation struct{}
+
+// NewInvitation Invitationのコンストラクター
+func NewInvitation() *Invitation {
+	return new(Invitation)
+}
+
+// Invitations invitationsテーブルの構造体
+type Invitations struct {
+	ID              int       `json:"id"              gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	QuestionnaireID int       `json:"questionnaireID" gorm:"type:int(11) NOT NULL;"`
+	UserTraqid      string    `json:"traqID"          gorm:"type:char(30) NOT NULL;"`
+	Role            string    `json:"role"            gorm:"type:char(10) NOT NULL;"`
+	InvitedBy       string    `json:"invited_by"      gorm:"type:char(30) NOT NULL;"`
+	CreatedAt       time.Time `json:"created_at"      gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+}
+
+// InvitationInfo 招待とアンケートのタイトル
+type InvitationInfo struct {
+	Invitations
+	Title string `json:"title"`
+}
+
+// InsertInvitation アンケートの管理者への招待の追加
+// 同じユーザーへの招待が既にある場合はErrInvitationExistsを返す
+func (*Invitation) InsertInvitation(ctx context.Context, questionnaireID int, userID string, role string, invitedBy string) (int, error) {
+	invitation := Invitations{
+		QuestionnaireID: questionnaireID,
+		UserTraqid:      userID,
+		Role:            role,
+		InvitedBy:       invitedBy,
+	}
+
+	err := getTx(ctx).Create(&invitation).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return 0, fmt.Errorf("failed to insert an invitation: %w", ErrInvitationExists)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert an invitation: %w", err)
+	}
+
+	return invitation.ID, nil
+}
+
+// GetInvitations アンケートの承認されていない招待の取得
+func (*Invitation) GetInvitations(ctx context.Context, questionnaireID int) ([]Invitations, error) {
+	invitations := []Invitations{}
+	err := getTx(ctx).
+		Where("questionnaire_id = ?", questionnaireID).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// GetUserInvitations 自分への承認されていない招待の取得 (削除されたアンケートへの招待は除く)
+func (*Invitation) GetUserInvitations(ctx context.Context, userID string) ([]InvitationInfo, error) {
+	invitations := []InvitationInfo{}
+	err := getTx(ctx).
+		Table("invitations").
+		Joins("INNER JOIN questionnaires ON invitations.questionnaire_id = questionnaires.id").
+		Where("invitations.user_traqid = ? AND questionnaires.deleted_at IS NULL", userID).
+		Order("invitations.created_at DESC").
+		Select("invitations.*, questionnaires.title").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// AcceptInvitation 招待を承認して招待された役割の管理者になる
+// ゴミ箱に移動されたアンケートへの招待はgorm.ErrRecordNotFoundになる
+func (*Invitation) AcceptInvitation(ctx context.Context, userID string, invitationID int) (*Invitations, error) {
+	invitation := Invitations{}
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		err := tx.
+			Set("gorm:query_option", "FOR UPDATE").
+			Table("invitations").
+			Joins("INNER JOIN questionnaires ON invitations.questionnaire_id = questionnaires.id").
+			Where("invitations.id = ? AND invitations.user_traqid = ? AND questionnaires.deleted_at IS NULL", invitationID, userID).
+			Select("invitations.*").
+			First(&invitation).Error
+		if err != nil {
+			return fmt.Errorf("failed to get an invitation: %w", err)
+		}
+
+		err = tx.
+			Where("questionnaire_id = ? AND user_traqid = ?", invitation.QuestionnaireID, userID).
+			Delete(&Administrators{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete an administrator: %w", err)
+		}
+
+		err = tx.Create(&Administrators{
+			QuestionnaireID: invitation.QuestionnaireID,
+			UserTraqid:      userID,
+			Role:            invitation.Role,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to insert an administrator: %w", err)
+		}
+
+		err = tx.
+			Where("id = ?", invitationID).
+			Delete(&Invitations{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete an invitation: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// DeclineInvitation 招待の辞退
+func (*Invitation) DeclineInvitation(ctx context.Context, userID string, invitationID int) error {
+	result := getTx(ctx).
+		Where("id = ? AND user_traqid = ?", invitationID, userID).
+		Delete(&Invitations{})
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to delete an invitation: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to delete an invitation: %w", ErrNoRecordDeleted)
+	}
+
+	return nil
+}
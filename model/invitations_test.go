@@ -0,0 +1,184 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createInvitationTestQuestionnaire(t *testing.T) int {
+	t.Helper()
+
+	ctx := context.Background()
+
+	questionnaire := Questionnaires{
+		Title:       "第1回集会らん☆ぷろ募集アンケート",
+		Description: "第1回集会らん☆ぷろ参加者募集",
+	}
+	err := db.Create(&questionnaire).Error
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaire.ID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	return questionnaire.ID
+}
+
+func TestInsertInvitation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createInvitationTestQuestionnaire(t)
+
+	invitationID, err := invitationImpl.InsertInvitation(ctx, questionnaireID, userTwo, RoleEditor, userOne)
+	require.NoError(t, err)
+
+	_, err = invitationImpl.InsertInvitation(ctx, questionnaireID, userTwo, RoleViewer, userOne)
+	assertion.True(errors.Is(err, ErrInvitationExists), "duplicate invitation")
+
+	invitations, err := invitationImpl.GetInvitations(ctx, questionnaireID)
+	require.NoError(t, err)
+	if assertion.Len(invitations, 1) {
+		assertion.Equal(invitationID, invitations[0].ID, "id")
+		assertion.Equal(userTwo, invitations[0].UserTraqid, "userID")
+		assertion.Equal(RoleEditor, invitations[0].Role, "role")
+		assertion.Equal(userOne, invitations[0].InvitedBy, "invitedBy")
+	}
+
+	userInvitations, err := invitationImpl.GetUserInvitations(ctx, userTwo)
+	require.NoError(t, err)
+	found := false
+	for _, invitation := range userInvitations {
+		if invitation.ID == invitationID {
+			found = true
+			assertion.Equal("第1回集会らん☆ぷろ募集アンケート", invitation.Title, "title")
+		}
+	}
+	assertion.True(found, "user invitations")
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createInvitationTestQuestionnaire(t)
+
+	invitationID, err := invitationImpl.InsertInvitation(ctx, questionnaireID, userThree, RoleViewer, userOne)
+	require.NoError(t, err)
+
+	_, err = invitationImpl.AcceptInvitation(ctx, userTwo, invitationID)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "invitation for another user")
+
+	invitation, err := invitationImpl.AcceptInvitation(ctx, userThree, invitationID)
+	require.NoError(t, err)
+	assertion.Equal(questionnaireID, invitation.QuestionnaireID, "questionnaireID")
+
+	role, err := administratorImpl.GetQuestionnaireRole(ctx, userThree, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal(RoleViewer, role, "role")
+
+	invitations, err := invitationImpl.GetInvitations(ctx, questionnaireID)
+	require.NoError(t, err)
+	assertion.Len(invitations, 0, "accepted invitation is deleted")
+
+	_, err = invitationImpl.AcceptInvitation(ctx, userThree, invitationID)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "already accepted")
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createInvitationTestQuestionnaire(t)
+
+	invitationID, err := invitationImpl.InsertInvitation(ctx, questionnaireID, userTwo, RoleViewer, userOne)
+	require.NoError(t, err)
+
+	err = invitationImpl.DeclineInvitation(ctx, userThree, invitationID)
+	assertion.True(errors.Is(err, ErrNoRecordDeleted), "invitation for another user")
+
+	err = invitationImpl.DeclineInvitation(ctx, userTwo, invitationID)
+	assertion.NoError(err)
+
+	role, err := administratorImpl.GetQuestionnaireRole(ctx, userTwo, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal("", role, "declined user is not an administrator")
+
+	err = invitationImpl.DeclineInvitation(ctx, userTwo, invitationID)
+	assertion.True(errors.Is(err, ErrNoRecordDeleted), "already declined")
+}
+
+func TestTransferOwnership(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createInvitationTestQuestionnaire(t)
+
+	err := administratorImpl.TransferOwnership(ctx, questionnaireID, userTwo, userThree)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "not an owner")
+
+	err = administratorImpl.TransferOwnership(ctx, questionnaireID, userOne, userTwo)
+	require.NoError(t, err)
+
+	role, err := administratorImpl.GetQuestionnaireRole(ctx, userTwo, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal(RoleOwner, role, "new owner")
+
+	role, err = administratorImpl.GetQuestionnaireRole(ctx, userOne, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal(RoleEditor, role, "previous owner")
+}
+
+func TestFlagOrphanedQuestionnaires(t *testing.T) {
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	managedQuestionnaireID := createInvitationTestQuestionnaire(t)
+	orphanedQuestionnaireID := createInvitationTestQuestionnaire(t)
+
+	err := db.
+		Model(&Questionnaires{}).
+		Where("id != ?", managedQuestionnaireID).
+		UpdateColumn("orphaned_at", gorm.Expr("NOW()")).Error
+	require.NoError(t, err)
+	err = db.
+		Model(&Questionnaires{}).
+		Where("id = ?", orphanedQuestionnaireID).
+		UpdateColumn("orphaned_at", gorm.Expr("NULL")).Error
+	require.NoError(t, err)
+
+	questionnaires, err := questionnaireImpl.FlagOrphanedQuestionnaires(ctx, []int{managedQuestionnaireID})
+	require.NoError(t, err)
+	if assertion.Len(questionnaires, 1, "only newly orphaned questionnaires") {
+		assertion.Equal(orphanedQuestionnaireID, questionnaires[0].ID)
+	}
+
+	questionnaires, err = questionnaireImpl.FlagOrphanedQuestionnaires(ctx, []int{managedQuestionnaireID})
+	require.NoError(t, err)
+	assertion.Len(questionnaires, 0, "already flagged")
+
+	_, err = questionnaireImpl.FlagOrphanedQuestionnaires(ctx, []int{managedQuestionnaireID, orphanedQuestionnaireID})
+	require.NoError(t, err)
+
+	questionnaire := Questionnaires{}
+	err = db.Where("id = ?", orphanedQuestionnaireID).First(&questionnaire).Error
+	require.NoError(t, err)
+	assertion.False(questionnaire.OrphanedAt.Valid, "unflagged")
+}
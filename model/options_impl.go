@@ -1,7 +1,9 @@
 package model
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"gopkg.in/guregu/null.v3"
@@ -24,59 +26,107 @@ type Options struct {
 }
 
 // InsertOption 選択肢の追加
-func (*Option) InsertOption(lastID int, num int, body string) error {
+func (*Option) InsertOption(ctx context.Context, lastID int, num int, body string) error {
 	option := Options{
 		QuestionID: lastID,
 		OptionNum:  num,
 		Body:       body,
 	}
-	err := db.Create(&option).Error
+	err := getTx(ctx).Create(&option).Error
 	if err != nil {
 		return fmt.Errorf("failed to insert a option: %w", err)
 	}
 	return nil
 }
 
+// OptionHistories option_historiesテーブルの構造体
+type OptionHistories struct {
+	ID         int       `json:"-"           gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	QuestionID int       `json:"questionID"  gorm:"type:int(11) NOT NULL;"`
+	OptionNum  int       `json:"option_num"  gorm:"type:int(11) NOT NULL;"`
+	Body       string    `json:"body"        gorm:"type:text;default:NULL;"`
+	ReplacedAt time.Time `json:"replaced_at" gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+}
+
 // UpdateOptions 選択肢の修正
-func (*Option) UpdateOptions(options []string, questionID int) error {
-	var err error
-	for i, optionLabel := range options {
-		option := Options{
-			Body: optionLabel,
-		}
-		query := db.
-			Model(Options{}).
-			Where("question_id = ? AND option_num = ?", questionID, i+1)
-		err := query.First(&Options{}).Error
-		if err != nil && !gorm.IsRecordNotFoundError(err) {
-			return fmt.Errorf("failed to get option: %w", err)
-		}
+// 既存の回答が変更前の選択肢を参照できるよう，書き換え・削除される選択肢はoption_historiesに残す
+func (*Option) UpdateOptions(ctx context.Context, options []string, questionID int) error {
+	return runInTx(ctx, func(tx *gorm.DB) error {
+		now := time.Now()
+		for i, optionLabel := range options {
+			option := Options{
+				Body: optionLabel,
+			}
+			query := tx.
+				Model(Options{}).
+				Where("question_id = ? AND option_num = ?", questionID, i+1)
+			oldOption := Options{}
+			err := query.First(&oldOption).Error
+			if err != nil && !gorm.IsRecordNotFoundError(err) {
+				return fmt.Errorf("failed to get option: %w", err)
+			}
+
+			if gorm.IsRecordNotFoundError(err) {
+				option.QuestionID = questionID
+				option.OptionNum = i + 1
+				err = tx.Create(&option).Error
+				if err != nil {
+					return fmt.Errorf("failed to insert option: %w", err)
+				}
+				continue
+			}
 
-		if gorm.IsRecordNotFoundError(err) {
-			option.QuestionID = questionID
-			option.OptionNum = i + 1
-			err = db.Create(&option).Error
+			if oldOption.Body == optionLabel {
+				continue
+			}
+
+			err = tx.Create(&OptionHistories{
+				QuestionID: questionID,
+				OptionNum:  oldOption.OptionNum,
+				Body:       oldOption.Body,
+				ReplacedAt: now,
+			}).Error
 			if err != nil {
-				return fmt.Errorf("failed to insert option: %w", err)
+				return fmt.Errorf("failed to insert option history: %w", err)
 			}
-		} else {
-			result := query.Update(&option)
-			err = result.Error
+
+			err = query.Update(&option).Error
 			if err != nil {
 				return fmt.Errorf("failed to update option: %w", err)
 			}
 		}
-	}
-	err = db.Where("question_id = ? AND option_num > ?", questionID, len(options)).Delete(Options{}).Error
-	if err != nil {
-		return fmt.Errorf("failed to update option: %w", err)
-	}
-	return nil
+
+		removedOptions := []Options{}
+		err := tx.
+			Where("question_id = ? AND option_num > ?", questionID, len(options)).
+			Find(&removedOptions).Error
+		if err != nil {
+			return fmt.Errorf("failed to get removed options: %w", err)
+		}
+		for _, removedOption := range removedOptions {
+			err = tx.Create(&OptionHistories{
+				QuestionID: questionID,
+				OptionNum:  removedOption.OptionNum,
+				Body:       removedOption.Body,
+				ReplacedAt: now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to insert option history: %w", err)
+			}
+		}
+
+		err = tx.Where("question_id = ? AND option_num > ?", questionID, len(options)).Delete(Options{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update option: %w", err)
+		}
+
+		return nil
+	})
 }
 
 // DeleteOptions 選択肢の削除
-func (*Option) DeleteOptions(questionID int) error {
-	err := db.
+func (*Option) DeleteOptions(ctx context.Context, questionID int) error {
+	err := getTx(ctx).
 		Where("question_id = ?", questionID).
 		Delete(Options{}).Error
 	if err != nil {
@@ -86,14 +136,14 @@ func (*Option) DeleteOptions(questionID int) error {
 }
 
 // GetOptions 質問の選択肢の取得
-func (*Option) GetOptions(questionIDs []int) ([]Options, error) {
+func (*Option) GetOptions(ctx context.Context, questionIDs []int) ([]Options, error) {
 	type option struct {
 		QuestionID int         `gorm:"type:int(11) NOT NULL;"`
 		Body       null.String `gorm:"type:text;default:NULL;"`
 	}
 	options := []option{}
 
-	err := db.
+	err := getTx(ctx).
 		Model(Options{}).
 		Where("question_id IN (?)", questionIDs).
 		Order("option_num").
@@ -113,3 +163,19 @@ func (*Option) GetOptions(questionIDs []int) ([]Options, error) {
 
 	return optns, nil
 }
+
+// GetOptionHistories 質問の変更前の選択肢の取得
+func (*Option) GetOptionHistories(ctx context.Context, questionIDs []int) ([]OptionHistories, error) {
+	optionHistories := []OptionHistories{}
+
+	err := getTx(ctx).
+		Where("question_id IN (?)", questionIDs).
+		Order("option_num").
+		Order("replaced_at").
+		Find(&optionHistories).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get option histories: %w", err)
+	}
+
+	return optionHistories, nil
+}
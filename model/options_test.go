@@ -0,0 +1,51 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestUpdateOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "MultipleChoice", "質問文", true)
+	require.NoError(t, err)
+
+	for i, body := range []string{"選択肢1", "選択肢2", "選択肢3"} {
+		err = optionImpl.InsertOption(ctx, questionID, i+1, body)
+		require.NoError(t, err)
+	}
+
+	err = optionImpl.UpdateOptions(ctx, []string{"選択肢1", "選択肢2(修正)"}, questionID)
+	assertion.NoError(err, "no error")
+
+	options, err := optionImpl.GetOptions(ctx, []int{questionID})
+	require.NoError(t, err)
+	bodies := make([]string, 0, len(options))
+	for _, option := range options {
+		bodies = append(bodies, option.Body)
+	}
+	assertion.Equal([]string{"選択肢1", "選択肢2(修正)"}, bodies, "options")
+
+	optionHistories, err := optionImpl.GetOptionHistories(ctx, []int{questionID})
+	require.NoError(t, err)
+	if assertion.Len(optionHistories, 2, "option histories") {
+		assertion.Equal(2, optionHistories[0].OptionNum, "option_num")
+		assertion.Equal("選択肢2", optionHistories[0].Body, "body")
+		assertion.Equal(3, optionHistories[1].OptionNum, "option_num")
+		assertion.Equal("選択肢3", optionHistories[1].Body, "body")
+		assertion.WithinDuration(time.Now(), optionHistories[0].ReplacedAt, 2*time.Second, "replaced_at")
+	}
+}
@@ -2,17 +2,31 @@
 
 package model
 
-import "gopkg.in/guregu/null.v3"
+import (
+	"context"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
 
 // IQuestionnaire QuestionnaireのRepository
 type IQuestionnaire interface {
-	InsertQuestionnaire(title string, description string, resTimeLimit null.Time, resSharedTo string) (int, error)
-	UpdateQuestionnaire(title string, description string, resTimeLimit null.Time, resSharedTo string, questionnaireID int) error
-	DeleteQuestionnaire(questionnaireID int) error
-	GetQuestionnaires(userID string, sort string, search string, pageNum int, nontargeted bool) ([]QuestionnaireInfo, int, error)
-	GetAdminQuestionnaires(userID string) ([]Questionnaires, error)
-	GetQuestionnaireInfo(questionnaireID int) (*Questionnaires, []string, []string, []string, error)
-	GetTargettedQuestionnaires(userID string, answered string, sort string) ([]TargettedQuestionnaire, error)
-	GetQuestionnaireLimit(questionnaireID int) (null.Time, error)
-	GetResShared(questionnaireID int) (string, error)
+	InsertQuestionnaire(ctx context.Context, title string, description string, resTimeLimit null.Time, resSharedTo string, responseMode string, isAnonymous bool) (int, error)
+	UpdateQuestionnaire(ctx context.Context, title string, description string, resTimeLimit null.Time, resSharedTo string, responseMode string, isAnonymous bool, questionnaireID int) error
+	DeleteQuestionnaire(ctx context.Context, questionnaireID int) error
+	RestoreQuestionnaire(ctx context.Context, questionnaireID int) error
+	CloseQuestionnaire(ctx context.Context, questionnaireID int, userID string) error
+	ReopenQuestionnaire(ctx context.Context, questionnaireID int) error
+	PurgeDeletedQuestionnaires(ctx context.Context, deletedBefore time.Time) (int, error)
+	FlagOrphanedQuestionnaires(ctx context.Context, managedQuestionnaireIDs []int) ([]Questionnaires, error)
+	GetQuestionnaires(ctx context.Context, userID string, groupIDs []string, sort string, search string, pageNum int, nontargeted bool) ([]QuestionnaireInfo, int, error)
+	GetAdminQuestionnaires(ctx context.Context, userID string, groupIDs []string) ([]Questionnaires, error)
+	GetDeletedQuestionnaires(ctx context.Context, userID string, groupIDs []string) ([]Questionnaires, error)
+	GetQuestionnaireInfo(ctx context.Context, questionnaireID int) (*Questionnaires, []string, []string, []string, error)
+	GetRespondentCount(ctx context.Context, questionnaireID int) (int, error)
+	GetTargettedQuestionnaires(ctx context.Context, userID string, groupIDs []string, answered string, sort string) ([]TargettedQuestionnaire, error)
+	GetQuestionnaireLimit(ctx context.Context, questionnaireID int) (null.Time, error)
+	GetResShared(ctx context.Context, questionnaireID int) (string, error)
+	CheckQuestionnaireClosed(ctx context.Context, questionnaireID int) (bool, error)
+	CheckQuestionnaireDeleted(ctx context.Context, questionnaireID int) (bool, error)
 }
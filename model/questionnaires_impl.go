@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"time"
@@ -9,6 +10,15 @@ import (
 	"gopkg.in/guregu/null.v3"
 )
 
+const (
+	// ResponseModeMultiple 1人が何度でも回答できる
+	ResponseModeMultiple = "multiple"
+	// ResponseModeOnce 1人1回(下書きを含む)のみ回答できる
+	ResponseModeOnce = "once"
+	// ResponseModeOnceWithDraft 1人につき送信済みの回答1つと編集中の下書き1つまで回答できる
+	ResponseModeOnceWithDraft = "once_with_draft"
+)
+
 // Questionnaire QuestionnaireRepositoryの実装
 type Questionnaire struct{}
 
@@ -19,14 +29,20 @@ func NewQuestionnaire() *Questionnaire {
 
 //Questionnaires questionnairesテーブルの構造体
 type Questionnaires struct {
-	ID           int       `json:"questionnaireID" gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
-	Title        string    `json:"title"           gorm:"type:char(50) NOT NULL;"`
-	Description  string    `json:"description"     gorm:"type:text NOT NULL;"`
-	ResTimeLimit null.Time `json:"res_time_limit,omitempty"  gorm:"type:timestamp NULL;default:NULL;"`
-	DeletedAt    null.Time `json:"deleted_at,omitempty"      gorm:"type:timestamp NULL;default:NULL;"`
-	ResSharedTo  string    `json:"res_shared_to"   gorm:"type:char(30) NOT NULL;default:\"administrators\";"`
-	CreatedAt    time.Time `json:"created_at"      gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
-	ModifiedAt   time.Time `json:"modified_at"     gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	ID           int         `json:"questionnaireID" gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	Title        string      `json:"title"           gorm:"type:char(50) NOT NULL;"`
+	Description  string      `json:"description"     gorm:"type:text NOT NULL;"`
+	ResTimeLimit null.Time   `json:"res_time_limit,omitempty"  gorm:"type:timestamp NULL;default:NULL;"`
+	DeletedAt    null.Time   `json:"deleted_at,omitempty"      gorm:"type:timestamp NULL;default:NULL;"`
+	ResSharedTo  string      `json:"res_shared_to"   gorm:"type:char(30) NOT NULL;default:\"administrators\";"`
+	ResponseMode string      `json:"response_mode"   gorm:"type:char(30) NOT NULL;default:\"multiple\";"`
+	IsAnonymous  bool        `json:"is_anonymous"    gorm:"type:boolean NOT NULL;default:false;"`
+	ClosedAt     null.Time   `json:"closed_at,omitempty"       gorm:"type:timestamp NULL;default:NULL;"`
+	ClosedBy     null.String `json:"closed_by,omitempty"       gorm:"type:char(30) NULL;default:NULL;"`
+	// OrphanedAt 有効な管理者がいなくなったことを検出した日時
+	OrphanedAt null.Time `json:"orphaned_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
+	CreatedAt    time.Time   `json:"created_at"      gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	ModifiedAt   time.Time   `json:"modified_at"     gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
 }
 
 //BeforeUpdate Update時に自動でmodified_atを現在時刻に
@@ -58,16 +74,19 @@ type TargettedQuestionnaire struct {
 	Questionnaires
 	RespondedAt null.Time `json:"responded_at"`
 	HasResponse bool      `json:"has_response"`
+	IsClosed    bool      `json:"is_closed"`
 }
 
 //InsertQuestionnaire アンケートの追加
-func (*Questionnaire) InsertQuestionnaire(title string, description string, resTimeLimit null.Time, resSharedTo string) (int, error) {
+func (*Questionnaire) InsertQuestionnaire(ctx context.Context, title string, description string, resTimeLimit null.Time, resSharedTo string, responseMode string, isAnonymous bool) (int, error) {
 	var questionnaire Questionnaires
 	if !resTimeLimit.Valid {
 		questionnaire = Questionnaires{
-			Title:       title,
-			Description: description,
-			ResSharedTo: resSharedTo,
+			Title:        title,
+			Description:  description,
+			ResSharedTo:  resSharedTo,
+			ResponseMode: responseMode,
+			IsAnonymous:  isAnonymous,
 		}
 	} else {
 		questionnaire = Questionnaires{
@@ -75,10 +94,12 @@ func (*Questionnaire) InsertQuestionnaire(title string, description string, resT
 			Description:  description,
 			ResTimeLimit: resTimeLimit,
 			ResSharedTo:  resSharedTo,
+			ResponseMode: responseMode,
+			IsAnonymous:  isAnonymous,
 		}
 	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
+	err := runInTx(ctx, func(tx *gorm.DB) error {
 		err := tx.Create(&questionnaire).Error
 		if err != nil {
 			return fmt.Errorf("failed to insert a questionnaire: %w", err)
@@ -101,20 +122,44 @@ func (*Questionnaire) InsertQuestionnaire(title string, description string, resT
 }
 
 //UpdateQuestionnaire アンケートの更新
-func (*Questionnaire) UpdateQuestionnaire(title string, description string, resTimeLimit null.Time, resSharedTo string, questionnaireID int) error {
+func (*Questionnaire) UpdateQuestionnaire(ctx context.Context, title string, description string, resTimeLimit null.Time, resSharedTo string, responseMode string, isAnonymous bool, questionnaireID int) error {
+	questionnaire := map[string]interface{}{
+		"title":          title,
+		"description":    description,
+		"res_time_limit": resTimeLimit,
+		"res_shared_to":  resSharedTo,
+		"response_mode":  responseMode,
+		"is_anonymous":   isAnonymous,
+	}
 	if !resTimeLimit.Valid {
-		questionnaire := map[string]interface{}{
-			"title":          title,
-			"description":    description,
-			"res_time_limit": gorm.Expr("NULL"),
-			"res_shared_to":  resSharedTo,
+		questionnaire["res_time_limit"] = gorm.Expr("NULL")
+	}
+
+	return runInTx(ctx, func(tx *gorm.DB) error {
+		// 回答者の記録のされ方が変わるので，回答があるアンケートの匿名設定は変更できない
+		var count int
+		err := tx.
+			Table("respondents").
+			Joins("INNER JOIN questionnaires ON questionnaires.id = respondents.questionnaire_id").
+			Where("respondents.questionnaire_id = ? AND questionnaires.is_anonymous != ?", questionnaireID, isAnonymous).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to count respondents: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("failed to update a questionnaire record: %w", ErrAnonymityLocked)
+		}
+
+		err = updateResponseSlots(tx, questionnaireID, responseMode)
+		if err != nil {
+			return fmt.Errorf("failed to update response slots: %w", err)
 		}
 
-		result := db.
+		result := tx.
 			Model(&Questionnaires{}).
 			Where("id = ?", questionnaireID).
 			Update(questionnaire)
-		err := result.Error
+		err = result.Error
 		if err != nil {
 			return fmt.Errorf("failed to update a questionnaire record: %w", err)
 		}
@@ -122,34 +167,86 @@ func (*Questionnaire) UpdateQuestionnaire(title string, description string, resT
 			return fmt.Errorf("failed to update a questionnaire record: %w", ErrNoRecordUpdated)
 		}
 
+		return nil
+	})
+}
+
+/*updateResponseSlots 回答モードの変更に合わせて削除されていない回答の枠を振り直す
+1人あたりの回答が変更後の回答モードの上限を超えている場合はErrResponseModeConflictを返す*/
+func updateResponseSlots(tx *gorm.DB, questionnaireID int, responseMode string) error {
+	questionnaire := Questionnaires{}
+	// 同時に回答された場合に備えてアンケートの行をロックする
+	err := tx.
+		Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", questionnaireID).
+		Select("response_mode").
+		First(&questionnaire).Error
+	if err != nil {
+		return fmt.Errorf("failed to get the questionnaire: %w", err)
+	}
+	if questionnaire.ResponseMode == responseMode {
 		return nil
 	}
 
-	questionnaire := Questionnaires{
-		Title:        title,
-		Description:  description,
-		ResTimeLimit: resTimeLimit,
-		ResSharedTo:  resSharedTo,
+	var slot interface{}
+	switch responseMode {
+	case ResponseModeOnce:
+		slot = "once"
+	case ResponseModeOnceWithDraft:
+		slot = gorm.Expr("IF(submitted_at IS NULL, 'draft', 'submitted')")
+	default:
+		slot = gorm.Expr("NULL")
 	}
 
-	result := db.
-		Model(&Questionnaires{}).
-		Where("id = ?", questionnaireID).
-		Update(&questionnaire)
-	err := result.Error
+	if responseMode != ResponseModeMultiple {
+		// 振り直した後に同じ枠の回答が複数になるユーザーがいれば変更できない
+		group := "user_traqid"
+		if responseMode == ResponseModeOnceWithDraft {
+			group = "user_traqid, submitted_at IS NULL"
+		}
+
+		var count int
+		err = tx.
+			Table("respondents").
+			Where("questionnaire_id = ? AND deleted_at IS NULL", questionnaireID).
+			Select("user_traqid").
+			Group(group).
+			Having("COUNT(*) > 1").
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to count respondents: %w", err)
+		}
+		if count > 0 {
+			return ErrResponseModeConflict
+		}
+	}
+
+	// 枠が入れ替わる間に一意制約に反しないよう一度全て外す
+	err = tx.
+		Model(&Respondents{}).
+		Where("questionnaire_id = ? AND deleted_at IS NULL", questionnaireID).
+		UpdateColumn("response_slot", gorm.Expr("NULL")).Error
 	if err != nil {
-		return fmt.Errorf("failed to update a questionnaire record: %w", err)
+		return fmt.Errorf("failed to clear response slots: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("failed to update a questionnaire record: %w", ErrNoRecordUpdated)
+	if responseMode == ResponseModeMultiple {
+		return nil
+	}
+
+	err = tx.
+		Model(&Respondents{}).
+		Where("questionnaire_id = ? AND deleted_at IS NULL", questionnaireID).
+		UpdateColumn("response_slot", slot).Error
+	if err != nil {
+		return fmt.Errorf("failed to set response slots: %w", err)
 	}
 
 	return nil
 }
 
 //DeleteQuestionnaire アンケートの削除
-func (*Questionnaire) DeleteQuestionnaire(questionnaireID int) error {
-	result := db.Delete(&Questionnaires{ID: questionnaireID})
+func (*Questionnaire) DeleteQuestionnaire(ctx context.Context, questionnaireID int) error {
+	result := getTx(ctx).Delete(&Questionnaires{ID: questionnaireID})
 	err := result.Error
 	if err != nil {
 		return fmt.Errorf("failed to delete questionnaire: %w", err)
@@ -161,12 +258,240 @@ func (*Questionnaire) DeleteQuestionnaire(questionnaireID int) error {
 	return nil
 }
 
+//RestoreQuestionnaire 削除されたアンケートの復元
+func (*Questionnaire) RestoreQuestionnaire(ctx context.Context, questionnaireID int) error {
+	result := getTx(ctx).
+		Unscoped().
+		Model(&Questionnaires{}).
+		Where("id = ? AND deleted_at IS NOT NULL", questionnaireID).
+		Update("deleted_at", gorm.Expr("NULL"))
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to restore questionnaire: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to restore questionnaire: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
+
+//CloseQuestionnaire アンケートの回答受付の終了
+func (*Questionnaire) CloseQuestionnaire(ctx context.Context, questionnaireID int, userID string) error {
+	result := getTx(ctx).
+		Model(&Questionnaires{}).
+		Where("id = ? AND closed_at IS NULL", questionnaireID).
+		Update(map[string]interface{}{
+			"closed_at": time.Now(),
+			"closed_by": userID,
+		})
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to close questionnaire: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to close questionnaire: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
+
+//ReopenQuestionnaire アンケートの回答受付の再開
+func (*Questionnaire) ReopenQuestionnaire(ctx context.Context, questionnaireID int) error {
+	result := getTx(ctx).
+		Model(&Questionnaires{}).
+		Where("id = ? AND closed_at IS NOT NULL", questionnaireID).
+		Update(map[string]interface{}{
+			"closed_at": gorm.Expr("NULL"),
+			"closed_by": gorm.Expr("NULL"),
+		})
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to reopen questionnaire: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to reopen questionnaire: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
+
+/*
+FlagOrphanedQuestionnaires 有効な管理者がいるアンケート以外に管理者不在の印を付ける
+有効な管理者がいるアンケートの印は外す
+戻り値は新たに印を付けたアンケート
+*/
+func (*Questionnaire) FlagOrphanedQuestionnaires(ctx context.Context, managedQuestionnaireIDs []int) ([]Questionnaires, error) {
+	questionnaires := []Questionnaires{}
+
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		query := tx.Where("orphaned_at IS NULL")
+		if len(managedQuestionnaireIDs) != 0 {
+			query = query.Where("id NOT IN (?)", managedQuestionnaireIDs)
+		}
+		err := query.Find(&questionnaires).Error
+		if err != nil {
+			return fmt.Errorf("failed to get orphaned questionnaires: %w", err)
+		}
+
+		// modified_atを変えないようにUpdateColumnを使う
+		if len(questionnaires) != 0 {
+			questionnaireIDs := make([]int, 0, len(questionnaires))
+			for _, questionnaire := range questionnaires {
+				questionnaireIDs = append(questionnaireIDs, questionnaire.ID)
+			}
+
+			err = tx.
+				Model(&Questionnaires{}).
+				Where("id IN (?)", questionnaireIDs).
+				UpdateColumn("orphaned_at", time.Now()).Error
+			if err != nil {
+				return fmt.Errorf("failed to flag orphaned questionnaires: %w", err)
+			}
+		}
+
+		if len(managedQuestionnaireIDs) != 0 {
+			err = tx.
+				Model(&Questionnaires{}).
+				Where("id IN (?) AND orphaned_at IS NOT NULL", managedQuestionnaireIDs).
+				UpdateColumn("orphaned_at", gorm.Expr("NULL")).Error
+			if err != nil {
+				return fmt.Errorf("failed to unflag questionnaires: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed in the transaction: %w", err)
+	}
+
+	return questionnaires, nil
+}
+
+/*PurgeDeletedQuestionnaires 削除されてから一定期間経ったアンケートを関連するレコードごと完全に削除
+戻り値は削除したアンケートの数*/
+func (*Questionnaire) PurgeDeletedQuestionnaires(ctx context.Context, deletedBefore time.Time) (int, error) {
+	questionnaireIDs := []int{}
+
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		err := tx.
+			Unscoped().
+			Model(&Questionnaires{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Pluck("id", &questionnaireIDs).Error
+		if err != nil {
+			return fmt.Errorf("failed to get deleted questionnaires: %w", err)
+		}
+		if len(questionnaireIDs) == 0 {
+			return nil
+		}
+
+		questionIDs := []int{}
+		err = tx.
+			Unscoped().
+			Model(&Questions{}).
+			Where("questionnaire_id IN (?)", questionnaireIDs).
+			Pluck("id", &questionIDs).Error
+		if err != nil {
+			return fmt.Errorf("failed to get questions: %w", err)
+		}
+
+		responseIDs := []int{}
+		err = tx.
+			Unscoped().
+			Model(&Respondents{}).
+			Where("questionnaire_id IN (?)", questionnaireIDs).
+			Pluck("response_id", &responseIDs).Error
+		if err != nil {
+			return fmt.Errorf("failed to get respondents: %w", err)
+		}
+
+		// 外部キー制約があるので参照している側から削除する
+		if len(responseIDs) != 0 {
+			err = tx.
+				Unscoped().
+				Where("response_id IN (?)", responseIDs).
+				Delete(&Responses{}).Error
+			if err != nil {
+				return fmt.Errorf("failed to delete responses: %w", err)
+			}
+		}
+
+		err = tx.
+			Unscoped().
+			Where("questionnaire_id IN (?)", questionnaireIDs).
+			Delete(&Respondents{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete respondents: %w", err)
+		}
+
+		if len(questionIDs) != 0 {
+			questionTables := []interface{}{
+				&Options{},
+				&OptionHistories{},
+				&ScaleLabels{},
+				&Validations{},
+			}
+			for _, table := range questionTables {
+				err = tx.
+					Where("question_id IN (?)", questionIDs).
+					Delete(table).Error
+				if err != nil {
+					return fmt.Errorf("failed to delete question's records: %w", err)
+				}
+			}
+
+			err = tx.
+				Unscoped().
+				Where("id IN (?)", questionIDs).
+				Delete(&Questions{}).Error
+			if err != nil {
+				return fmt.Errorf("failed to delete questions: %w", err)
+			}
+		}
+
+		questionnaireTables := []interface{}{
+			&Targets{},
+			&Administrators{},
+			&Revisions{},
+			&Invitations{},
+			&ShareLinks{},
+		}
+		for _, table := range questionnaireTables {
+			err = tx.
+				Where("questionnaire_id IN (?)", questionnaireIDs).
+				Delete(table).Error
+			if err != nil {
+				return fmt.Errorf("failed to delete questionnaire's records: %w", err)
+			}
+		}
+
+		err = tx.
+			Unscoped().
+			Where("id IN (?)", questionnaireIDs).
+			Delete(&Questionnaires{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete questionnaires: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed in the transaction: %w", err)
+	}
+
+	return len(questionnaireIDs), nil
+}
+
 /*GetQuestionnaires アンケートの一覧
 2つ目の戻り値はページ数の最大値*/
-func (*Questionnaire) GetQuestionnaires(userID string, sort string, search string, pageNum int, nontargeted bool) ([]QuestionnaireInfo, int, error) {
+func (*Questionnaire) GetQuestionnaires(ctx context.Context, userID string, groupIDs []string, sort string, search string, pageNum int, nontargeted bool) ([]QuestionnaireInfo, int, error) {
 	questionnaires := make([]QuestionnaireInfo, 0, 20)
 
-	query := db.
+	targetIDs := getTargetIDs(userID, groupIDs)
+
+	query := getTx(ctx).
 		Table("questionnaires").
 		Joins("LEFT OUTER JOIN targets ON questionnaires.id = targets.questionnaire_id")
 
@@ -176,7 +501,7 @@ func (*Questionnaire) GetQuestionnaires(userID string, sort string, search strin
 	}
 
 	if nontargeted {
-		query = query.Where("targets.questionnaire_id IS NULL OR (targets.user_traqid != ? AND targets.user_traqid != 'traP')", userID)
+		query = query.Where("targets.questionnaire_id IS NULL OR targets.user_traqid NOT IN (?)", targetIDs)
 	}
 	if len(search) != 0 {
 		// MySQLでのregexpの構文は少なくともGoのregexpの構文でvalidである必要がある
@@ -211,7 +536,7 @@ func (*Questionnaire) GetQuestionnaires(userID string, sort string, search strin
 
 	err = query.
 		Group("questionnaires.id").
-		Select("questionnaires.*, (targets.user_traqid = ? OR targets.user_traqid = 'traP') AS is_targeted", userID).
+		Select("questionnaires.*, (targets.user_traqid IN (?)) AS is_targeted", targetIDs).
 		Find(&questionnaires).Error
 	if err != nil && !gorm.IsRecordNotFoundError(err) {
 		return nil, 0, fmt.Errorf("failed to get the targeted questionnaires: %w", err)
@@ -220,13 +545,14 @@ func (*Questionnaire) GetQuestionnaires(userID string, sort string, search strin
 	return questionnaires, pageMax, nil
 }
 
-// GetAdminQuestionnaires 自分が管理者のアンケートの取得
-func (*Questionnaire) GetAdminQuestionnaires(userID string) ([]Questionnaires, error) {
+// GetAdminQuestionnaires 自分が管理者 (役割は問わない) のアンケートの取得
+func (*Questionnaire) GetAdminQuestionnaires(ctx context.Context, userID string, groupIDs []string) ([]Questionnaires, error) {
 	questionnaires := []Questionnaires{}
-	err := db.
+	err := getTx(ctx).
 		Table("questionnaires").
 		Joins("INNER JOIN administrators ON questionnaires.id = administrators.questionnaire_id").
-		Where("administrators.user_traqid = ?", userID).
+		Where("administrators.user_traqid IN (?)", append([]string{userID}, groupIDs...)).
+		Group("questionnaires.id").
 		Order("questionnaires.modified_at DESC").
 		Find(&questionnaires).Error
 	if err != nil {
@@ -236,14 +562,32 @@ func (*Questionnaire) GetAdminQuestionnaires(userID string) ([]Questionnaires, e
 	return questionnaires, nil
 }
 
+// GetDeletedQuestionnaires 自分がオーナーの削除されたアンケートの取得
+func (*Questionnaire) GetDeletedQuestionnaires(ctx context.Context, userID string, groupIDs []string) ([]Questionnaires, error) {
+	questionnaires := []Questionnaires{}
+	err := getTx(ctx).
+		Unscoped().
+		Table("questionnaires").
+		Joins("INNER JOIN administrators ON questionnaires.id = administrators.questionnaire_id").
+		Where("administrators.user_traqid IN (?) AND administrators.role = ? AND questionnaires.deleted_at IS NOT NULL", append([]string{userID}, groupIDs...), RoleOwner).
+		Group("questionnaires.id").
+		Order("questionnaires.deleted_at DESC").
+		Find(&questionnaires).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted questionnaires: %w", err)
+	}
+
+	return questionnaires, nil
+}
+
 //GetQuestionnaireInfo アンケートの詳細な情報取得
-func (*Questionnaire) GetQuestionnaireInfo(questionnaireID int) (*Questionnaires, []string, []string, []string, error) {
+func (*Questionnaire) GetQuestionnaireInfo(ctx context.Context, questionnaireID int) (*Questionnaires, []string, []string, []string, error) {
 	questionnaire := Questionnaires{}
 	targets := []string{}
 	administrators := []string{}
 	respondents := []string{}
 
-	err := db.
+	err := getTx(ctx).
 		Model(&Questionnaires{}).
 		Where("questionnaires.id = ?", questionnaireID).
 		First(&questionnaire).Error
@@ -251,7 +595,7 @@ func (*Questionnaire) GetQuestionnaireInfo(questionnaireID int) (*Questionnaires
 		return nil, nil, nil, nil, fmt.Errorf("failed to get a questionnaire: %w", err)
 	}
 
-	err = db.
+	err = getTx(ctx).
 		Table("targets").
 		Where("questionnaire_id = ?", questionnaire.ID).
 		Pluck("user_traqid", &targets).Error
@@ -259,15 +603,20 @@ func (*Questionnaire) GetQuestionnaireInfo(questionnaireID int) (*Questionnaires
 		return nil, nil, nil, nil, fmt.Errorf("failed to get targets: %w", err)
 	}
 
-	err = db.
+	err = getTx(ctx).
 		Table("administrators").
-		Where("questionnaire_id = ?", questionnaire.ID).
+		Where("questionnaire_id = ? AND role = ?", questionnaire.ID, RoleOwner).
 		Pluck("user_traqid", &administrators).Error
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to get administrators: %w", err)
 	}
 
-	err = db.
+	// 匿名のアンケートでは回答者を返さない
+	if questionnaire.IsAnonymous {
+		return &questionnaire, targets, administrators, respondents, nil
+	}
+
+	err = getTx(ctx).
 		Table("respondents").
 		Where("questionnaire_id = ? AND deleted_at IS NULL AND submitted_at IS NOT NULL", questionnaire.ID).
 		Pluck("user_traqid", &respondents).Error
@@ -278,16 +627,39 @@ func (*Questionnaire) GetQuestionnaireInfo(questionnaireID int) (*Questionnaires
 	return &questionnaire, targets, administrators, respondents, nil
 }
 
+// GetRespondentCount アンケートの回答者数(送信済みの回答数)の取得
+func (*Questionnaire) GetRespondentCount(ctx context.Context, questionnaireID int) (int, error) {
+	var count int
+	err := getTx(ctx).
+		Table("respondents").
+		Where("questionnaire_id = ? AND deleted_at IS NULL AND submitted_at IS NOT NULL", questionnaireID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count respondents: %w", err)
+	}
+
+	return count, nil
+}
+
+// getTargetIDs ユーザーが対象者に含まれるときのtargets.user_traqidの値の一覧
+func getTargetIDs(userID string, groupIDs []string) []string {
+	targetIDs := make([]string, 0, len(groupIDs)+2)
+	targetIDs = append(targetIDs, userID, "traP")
+	targetIDs = append(targetIDs, groupIDs...)
+
+	return targetIDs
+}
+
 //GetTargettedQuestionnaires targetになっているアンケートの取得
-func (*Questionnaire) GetTargettedQuestionnaires(userID string, answered string, sort string) ([]TargettedQuestionnaire, error) {
-	query := db.
+func (*Questionnaire) GetTargettedQuestionnaires(ctx context.Context, userID string, groupIDs []string, answered string, sort string) ([]TargettedQuestionnaire, error) {
+	query := getTx(ctx).
 		Table("questionnaires").
 		Where("questionnaires.res_time_limit > ? OR questionnaires.res_time_limit IS NULL", time.Now()).
 		Joins("INNER JOIN targets ON questionnaires.id = targets.questionnaire_id").
-		Where("targets.user_traqid = ? OR targets.user_traqid = 'traP'", userID).
-		Joins("LEFT OUTER JOIN respondents ON questionnaires.id = respondents.questionnaire_id AND respondents.user_traqid = ? AND respondents.deleted_at IS NULL", userID).
+		Where("targets.user_traqid IN (?)", getTargetIDs(userID, groupIDs)).
+		Joins("LEFT OUTER JOIN respondents ON questionnaires.id = respondents.questionnaire_id AND "+respondentUserCondition+" AND respondents.deleted_at IS NULL", respondentUserArgs(userID)...).
 		Group("questionnaires.id,respondents.user_traqid").
-		Select("questionnaires.*, MAX(respondents.submitted_at) AS responded_at, COUNT(respondents.response_id) != 0 AS has_response")
+		Select("questionnaires.*, MAX(respondents.submitted_at) AS responded_at, COUNT(respondents.response_id) != 0 AS has_response, questionnaires.closed_at IS NOT NULL AS is_closed")
 
 	query, err := setQuestionnairesOrder(query, sort)
 	if err != nil {
@@ -318,10 +690,10 @@ func (*Questionnaire) GetTargettedQuestionnaires(userID string, answered string,
 }
 
 //GetQuestionnaireLimit アンケートの回答期限の取得
-func (*Questionnaire) GetQuestionnaireLimit(questionnaireID int) (null.Time, error) {
+func (*Questionnaire) GetQuestionnaireLimit(ctx context.Context, questionnaireID int) (null.Time, error) {
 	res := Questionnaires{}
 
-	err := db.
+	err := getTx(ctx).
 		Model(Questionnaires{}).
 		Where("id = ?", questionnaireID).
 		Select("res_time_limit").
@@ -334,10 +706,10 @@ func (*Questionnaire) GetQuestionnaireLimit(questionnaireID int) (null.Time, err
 }
 
 //GetResShared アンケートの回答の公開範囲の取得
-func (*Questionnaire) GetResShared(questionnaireID int) (string, error) {
+func (*Questionnaire) GetResShared(ctx context.Context, questionnaireID int) (string, error) {
 	res := Questionnaires{}
 
-	err := db.
+	err := getTx(ctx).
 		Model(Questionnaires{}).
 		Where("id = ?", questionnaireID).
 		Select("res_shared_to").
@@ -349,6 +721,39 @@ func (*Questionnaire) GetResShared(questionnaireID int) (string, error) {
 	return res.ResSharedTo, nil
 }
 
+//CheckQuestionnaireClosed アンケートの回答受付が終了しているか
+func (*Questionnaire) CheckQuestionnaireClosed(ctx context.Context, questionnaireID int) (bool, error) {
+	res := Questionnaires{}
+
+	err := getTx(ctx).
+		Model(Questionnaires{}).
+		Where("id = ?", questionnaireID).
+		Select("closed_at").
+		Scan(&res).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get closed_at: %w", err)
+	}
+
+	return res.ClosedAt.Valid, nil
+}
+
+//CheckQuestionnaireDeleted アンケートがゴミ箱に移動されているか
+func (*Questionnaire) CheckQuestionnaireDeleted(ctx context.Context, questionnaireID int) (bool, error) {
+	res := Questionnaires{}
+
+	err := getTx(ctx).
+		Unscoped().
+		Model(Questionnaires{}).
+		Where("id = ?", questionnaireID).
+		Select("deleted_at").
+		Scan(&res).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get deleted_at: %w", err)
+	}
+
+	return res.DeletedAt.Valid, nil
+}
+
 func setQuestionnairesOrder(query *gorm.DB, sort string) (*gorm.DB, error) {
 	switch sort {
 	case "created_at":
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"math"
 	"sort"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 )
 
@@ -255,6 +257,8 @@ func setupQuestionnairesTest(t *testing.T) {
 
 func insertQuestionnaireTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -358,7 +362,7 @@ func insertQuestionnaireTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questionnaireID, err := questionnaireImpl.InsertQuestionnaire(testCase.args.title, testCase.args.description, testCase.args.resTimeLimit, testCase.args.resSharedTo)
+		questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, testCase.args.title, testCase.args.description, testCase.args.resTimeLimit, testCase.args.resSharedTo, ResponseModeMultiple, false)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -387,6 +391,8 @@ func insertQuestionnaireTest(t *testing.T) {
 
 func updateQuestionnaireTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -563,7 +569,7 @@ func updateQuestionnaireTest(t *testing.T) {
 		createdAt := questionnaire.CreatedAt
 		questionnaireID := questionnaire.ID
 		after := &testCase.after
-		err = questionnaireImpl.UpdateQuestionnaire(after.title, after.description, after.resTimeLimit, after.resSharedTo, questionnaireID)
+		err = questionnaireImpl.UpdateQuestionnaire(ctx, after.title, after.description, after.resTimeLimit, after.resSharedTo, ResponseModeMultiple, false, questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -619,7 +625,7 @@ func updateQuestionnaireTest(t *testing.T) {
 	}
 
 	for _, arg := range invalidTestCases {
-		err := questionnaireImpl.UpdateQuestionnaire(arg.title, arg.description, arg.resTimeLimit, arg.resSharedTo, invalidQuestionnaireID)
+		err := questionnaireImpl.UpdateQuestionnaire(ctx, arg.title, arg.description, arg.resTimeLimit, arg.resSharedTo, ResponseModeMultiple, false, invalidQuestionnaireID)
 		if !errors.Is(err, ErrNoRecordUpdated) {
 			if err == nil {
 				t.Errorf("Succeeded with invalid questionnaireID")
@@ -632,6 +638,8 @@ func updateQuestionnaireTest(t *testing.T) {
 
 func deleteQuestionnaireTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -675,7 +683,7 @@ func deleteQuestionnaireTest(t *testing.T) {
 		}
 
 		questionnaireID := questionnaire.ID
-		err = questionnaireImpl.DeleteQuestionnaire(questionnaireID)
+		err = questionnaireImpl.DeleteQuestionnaire(ctx, questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -712,7 +720,7 @@ func deleteQuestionnaireTest(t *testing.T) {
 		invalidQuestionnaireID *= 10
 	}
 
-	err := questionnaireImpl.DeleteQuestionnaire(invalidQuestionnaireID)
+	err := questionnaireImpl.DeleteQuestionnaire(ctx, invalidQuestionnaireID)
 	if !errors.Is(err, ErrNoRecordDeleted) {
 		if err == nil {
 			t.Errorf("Succeeded with invalid questionnaireID")
@@ -725,6 +733,8 @@ func deleteQuestionnaireTest(t *testing.T) {
 func getQuestionnairesTest(t *testing.T) {
 	t.Helper()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
 	sortFuncMap := map[string]func(questionnaires []QuestionnaireInfo) func(i, j int) bool{
@@ -934,7 +944,7 @@ func getQuestionnairesTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questionnaires, pageMax, err := questionnaireImpl.GetQuestionnaires(testCase.args.userID, testCase.args.sort, testCase.args.search, testCase.args.pageNum, testCase.args.nontargeted)
+		questionnaires, pageMax, err := questionnaireImpl.GetQuestionnaires(ctx, testCase.args.userID, nil, testCase.args.sort, testCase.args.search, testCase.args.pageNum, testCase.args.nontargeted)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -995,6 +1005,8 @@ func getQuestionnairesTest(t *testing.T) {
 
 func getAdminQuestionnairesTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -1034,7 +1046,7 @@ func getAdminQuestionnairesTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questionnaires, err := questionnaireImpl.GetAdminQuestionnaires(testCase.userID)
+		questionnaires, err := questionnaireImpl.GetAdminQuestionnaires(ctx, testCase.userID, nil)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -1078,6 +1090,8 @@ func getAdminQuestionnairesTest(t *testing.T) {
 
 func getQuestionnaireInfoTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -1187,7 +1201,7 @@ func getQuestionnaireInfoTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualQuestionnaire, actualTargets, actualAdministrators, actualRespondents, err := questionnaireImpl.GetQuestionnaireInfo(testCase.questionnaireID)
+		actualQuestionnaire, actualTargets, actualAdministrators, actualRespondents, err := questionnaireImpl.GetQuestionnaireInfo(ctx, testCase.questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -1225,6 +1239,8 @@ func getQuestionnaireInfoTest(t *testing.T) {
 
 func getTargettedQuestionnairesTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -1369,7 +1385,7 @@ func getTargettedQuestionnairesTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questionnaires, err := questionnaireImpl.GetTargettedQuestionnaires(testCase.args.userID, testCase.args.answered, testCase.args.sort)
+		questionnaires, err := questionnaireImpl.GetTargettedQuestionnaires(ctx, testCase.args.userID, nil, testCase.args.answered, testCase.args.sort)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -1422,6 +1438,8 @@ func getTargettedQuestionnairesTest(t *testing.T) {
 
 func getQuestionnaireLimitTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -1485,7 +1503,7 @@ func getQuestionnaireLimitTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualLimit, err := questionnaireImpl.GetQuestionnaireLimit(testCase.args.questionnaireID)
+		actualLimit, err := questionnaireImpl.GetQuestionnaireLimit(ctx, testCase.args.questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -1504,6 +1522,8 @@ func getQuestionnaireLimitTest(t *testing.T) {
 
 func getResSharedTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -1577,7 +1597,7 @@ func getResSharedTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualResSharedTo, err := questionnaireImpl.GetResShared(testCase.args.questionnaireID)
+		actualResSharedTo, err := questionnaireImpl.GetResShared(ctx, testCase.args.questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -1593,3 +1613,230 @@ func getResSharedTest(t *testing.T) {
 		assertion.Equal(testCase.expect.resSharedTo, actualResSharedTo, testCase.description, "res_shared_to")
 	}
 }
+
+func TestRestoreQuestionnaire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回集会らん☆ぷろ参加者募集", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{questionnairesTestUserID}, RoleOwner)
+	require.NoError(t, err)
+
+	err = questionnaireImpl.RestoreQuestionnaire(ctx, questionnaireID)
+	assertion.Equal(true, errors.Is(err, ErrNoRecordUpdated), "not deleted")
+
+	err = questionnaireImpl.DeleteQuestionnaire(ctx, questionnaireID)
+	require.NoError(t, err)
+
+	deletedQuestionnaires, err := questionnaireImpl.GetDeletedQuestionnaires(ctx, questionnairesTestUserID, nil)
+	assertion.NoError(err, "GetDeletedQuestionnaires")
+	isFound := false
+	for _, questionnaire := range deletedQuestionnaires {
+		if questionnaire.ID == questionnaireID {
+			isFound = true
+			assertion.Equal(true, questionnaire.DeletedAt.Valid, "GetDeletedQuestionnaires", "deleted_at")
+		}
+	}
+	assertion.Equal(true, isFound, "GetDeletedQuestionnaires", "found")
+
+	err = questionnaireImpl.RestoreQuestionnaire(ctx, questionnaireID)
+	assertion.NoError(err, "restore")
+
+	questionnaire := Questionnaires{}
+	err = db.Where("id = ?", questionnaireID).First(&questionnaire).Error
+	assertion.NoError(err, "restored questionnaire")
+
+	role, err := administratorImpl.GetQuestionnaireRole(ctx, questionnairesTestUserID, questionnaireID)
+	assertion.NoError(err, "restored administrator")
+	assertion.Equal(RoleOwner, role, "restored administrator")
+
+	err = questionnaireImpl.RestoreQuestionnaire(ctx, -1)
+	assertion.Equal(true, errors.Is(err, ErrNoRecordUpdated), "questionnaire not found")
+}
+
+func TestPurgeDeletedQuestionnaires(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	// 他のテストで削除されたアンケートを消さないように十分過去に削除されたことにする
+	deletedAt := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回集会らん☆ぷろ参加者募集", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{questionnairesTestUserID}, RoleOwner)
+	require.NoError(t, err)
+	err = targetImpl.InsertTargets(ctx, questionnaireID, []string{questionnairesTestUserID})
+	require.NoError(t, err)
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "MultipleChoice", "質問文", true)
+	require.NoError(t, err)
+	err = optionImpl.InsertOption(ctx, questionID, 1, "選択肢1")
+	require.NoError(t, err)
+	_, err = revisionImpl.InsertRevision(ctx, questionnaireID, questionnairesTestUserID)
+	require.NoError(t, err)
+	responseID, err := respondentImpl.InsertRespondent(ctx, questionnairesTestUserID, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+		{QuestionID: questionID, Data: "選択肢1"},
+	})
+	require.NoError(t, err)
+
+	keptQuestionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回集会らん☆ぷろ参加者募集", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = db.
+		Model(&Questionnaires{}).
+		Where("id IN (?)", []int{questionnaireID, keptQuestionnaireID}).
+		Update("deleted_at", deletedAt).Error
+	require.NoError(t, err)
+	err = db.
+		Unscoped().
+		Model(&Questionnaires{}).
+		Where("id = ?", keptQuestionnaireID).
+		Update("deleted_at", deletedAt.Add(time.Hour)).Error
+	require.NoError(t, err)
+
+	count, err := questionnaireImpl.PurgeDeletedQuestionnaires(ctx, deletedAt.Add(time.Minute))
+	assertion.NoError(err, "no error")
+	assertion.Equal(1, count, "count")
+
+	err = db.Unscoped().Where("id = ?", questionnaireID).First(&Questionnaires{}).Error
+	assertion.Equal(true, gorm.IsRecordNotFoundError(err), "purged questionnaire")
+	err = db.Unscoped().Where("response_id = ?", responseID).First(&Respondents{}).Error
+	assertion.Equal(true, gorm.IsRecordNotFoundError(err), "purged respondent")
+	err = db.Where("questionnaire_id = ?", questionnaireID).First(&Administrators{}).Error
+	assertion.Equal(true, gorm.IsRecordNotFoundError(err), "purged administrator")
+
+	err = db.Unscoped().Where("id = ?", keptQuestionnaireID).First(&Questionnaires{}).Error
+	assertion.NoError(err, "kept questionnaire")
+}
+
+func TestCloseQuestionnaire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回集会らん☆ぷろ参加者募集", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+	err = targetImpl.InsertTargets(ctx, questionnaireID, []string{questionnairesTestUserID})
+	require.NoError(t, err)
+
+	isClosed, err := questionnaireImpl.CheckQuestionnaireClosed(ctx, questionnaireID)
+	assertion.NoError(err, "open")
+	assertion.Equal(false, isClosed, "open")
+
+	err = questionnaireImpl.ReopenQuestionnaire(ctx, questionnaireID)
+	assertion.Equal(true, errors.Is(err, ErrNoRecordUpdated), "reopen open questionnaire")
+
+	err = questionnaireImpl.CloseQuestionnaire(ctx, questionnaireID, questionnairesTestUserID)
+	assertion.NoError(err, "close")
+
+	questionnaire := Questionnaires{}
+	err = db.Where("id = ?", questionnaireID).First(&questionnaire).Error
+	require.NoError(t, err)
+	assertion.WithinDuration(time.Now(), questionnaire.ClosedAt.ValueOrZero(), 2*time.Second, "closed_at")
+	assertion.Equal(questionnairesTestUserID, questionnaire.ClosedBy.ValueOrZero(), "closed_by")
+
+	isClosed, err = questionnaireImpl.CheckQuestionnaireClosed(ctx, questionnaireID)
+	assertion.NoError(err, "closed")
+	assertion.Equal(true, isClosed, "closed")
+
+	err = questionnaireImpl.CloseQuestionnaire(ctx, questionnaireID, questionnairesTestUserID)
+	assertion.Equal(true, errors.Is(err, ErrNoRecordUpdated), "close closed questionnaire")
+
+	targettedQuestionnaires, err := questionnaireImpl.GetTargettedQuestionnaires(ctx, questionnairesTestUserID, nil, "", "")
+	require.NoError(t, err)
+	for _, targettedQuestionnaire := range targettedQuestionnaires {
+		if targettedQuestionnaire.ID == questionnaireID {
+			assertion.Equal(true, targettedQuestionnaire.IsClosed, "GetTargettedQuestionnaires", "is_closed")
+		}
+	}
+
+	err = questionnaireImpl.ReopenQuestionnaire(ctx, questionnaireID)
+	assertion.NoError(err, "reopen")
+
+	isClosed, err = questionnaireImpl.CheckQuestionnaireClosed(ctx, questionnaireID)
+	assertion.NoError(err, "reopened")
+	assertion.Equal(false, isClosed, "reopened")
+}
+
+func TestGetQuestionnairesWithGroups(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	const (
+		groupID      = "c1a4e7a3-2b36-4c3e-8a25-0d5b0c0f7a11"
+		otherGroupID = "5d1f0e3c-7b2a-4c9d-8e6f-1a2b3c4d5e6f"
+		groupUserID  = "questionnairesGroupUser"
+	)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now().Add(time.Hour), true), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = targetImpl.InsertTargets(ctx, questionnaireID, []string{groupID})
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{groupID}, RoleOwner)
+	require.NoError(t, err)
+
+	containsQuestionnaire := func(questionnaireIDs []int) bool {
+		for _, id := range questionnaireIDs {
+			if id == questionnaireID {
+				return true
+			}
+		}
+		return false
+	}
+
+	type test struct {
+		description string
+		groupIDs    []string
+		expect      bool
+	}
+	testCases := []test{
+		{
+			description: "member of the group",
+			groupIDs:    []string{otherGroupID, groupID},
+			expect:      true,
+		},
+		{
+			description: "member of another group",
+			groupIDs:    []string{otherGroupID},
+			expect:      false,
+		},
+		{
+			description: "no group",
+			groupIDs:    nil,
+			expect:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		targettedQuestionnaires, err := questionnaireImpl.GetTargettedQuestionnaires(ctx, groupUserID, testCase.groupIDs, "", "")
+		require.NoError(t, err, testCase.description)
+		targettedIDs := make([]int, 0, len(targettedQuestionnaires))
+		for _, targettedQuestionnaire := range targettedQuestionnaires {
+			targettedIDs = append(targettedIDs, targettedQuestionnaire.ID)
+		}
+		assertion.Equal(testCase.expect, containsQuestionnaire(targettedIDs), testCase.description, "GetTargettedQuestionnaires")
+
+		adminQuestionnaires, err := questionnaireImpl.GetAdminQuestionnaires(ctx, groupUserID, testCase.groupIDs)
+		require.NoError(t, err, testCase.description)
+		adminIDs := make([]int, 0, len(adminQuestionnaires))
+		for _, adminQuestionnaire := range adminQuestionnaires {
+			adminIDs = append(adminIDs, adminQuestionnaire.ID)
+		}
+		assertion.Equal(testCase.expect, containsQuestionnaire(adminIDs), testCase.description, "GetAdminQuestionnaires")
+	}
+}
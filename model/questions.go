@@ -2,11 +2,18 @@
 
 package model
 
+import (
+	"context"
+)
+
 // IQuestion QuestionのRepository
 type IQuestion interface {
-	InsertQuestion(questionnaireID int, pageNum int, questionNum int, questionType string, body string, isRequired bool) (int, error)
-	UpdateQuestion(questionnaireID int, pageNum int, questionNum int, questionType string, body string, isRequired bool, questionID int) error
-	DeleteQuestion(questionID int) error
-	GetQuestions(questionnaireID int) ([]Questions, error)
-	CheckQuestionAdmin(userID string, questionID int) (bool, error)
+	InsertQuestion(ctx context.Context, questionnaireID int, pageNum int, questionNum int, questionType string, body string, isRequired bool) (int, error)
+	UpdateQuestion(ctx context.Context, questionnaireID int, pageNum int, questionNum int, questionType string, body string, isRequired bool, questionID int) error
+	DeleteQuestion(ctx context.Context, questionID int) error
+	GetQuestion(ctx context.Context, questionID int) (Questions, error)
+	GetQuestions(ctx context.Context, questionnaireID int) ([]Questions, error)
+	GetAnsweredQuestionIDs(ctx context.Context, questionnaireID int) ([]int, error)
+	CheckQuestionAdmin(ctx context.Context, userID string, questionID int) (bool, error)
+	CheckQuestionAnswered(ctx context.Context, questionID int) (bool, error)
 }
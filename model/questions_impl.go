@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -42,7 +43,7 @@ type QuestionIDType struct {
 }
 
 //InsertQuestion 質問の追加
-func (*Question) InsertQuestion(questionnaireID int, pageNum int, questionNum int, questionType string,
+func (*Question) InsertQuestion(ctx context.Context, questionnaireID int, pageNum int, questionNum int, questionType string,
 	body string, isRequired bool) (int, error) {
 	question := Questions{
 		QuestionnaireID: questionnaireID,
@@ -53,7 +54,7 @@ func (*Question) InsertQuestion(questionnaireID int, pageNum int, questionNum in
 		IsRequired:      isRequired,
 	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
+	err := runInTx(ctx, func(tx *gorm.DB) error {
 		err := tx.Create(&question).Error
 		if err != nil {
 			return fmt.Errorf("failed to insert a question record: %w", err)
@@ -76,7 +77,7 @@ func (*Question) InsertQuestion(questionnaireID int, pageNum int, questionNum in
 }
 
 //UpdateQuestion 質問の修正
-func (*Question) UpdateQuestion(questionnaireID int, pageNum int, questionNum int, questionType string,
+func (*Question) UpdateQuestion(ctx context.Context, questionnaireID int, pageNum int, questionNum int, questionType string,
 	body string, isRequired bool, questionID int) error {
 	question := map[string]interface{}{
 		"questionnaire_id": questionnaireID,
@@ -87,7 +88,7 @@ func (*Question) UpdateQuestion(questionnaireID int, pageNum int, questionNum in
 		"is_required":      isRequired,
 	}
 
-	err := db.
+	err := getTx(ctx).
 		Model(&Questions{}).
 		Where("id = ?", questionID).
 		Update(question).Error
@@ -99,8 +100,8 @@ func (*Question) UpdateQuestion(questionnaireID int, pageNum int, questionNum in
 }
 
 //DeleteQuestion 質問の削除
-func (*Question) DeleteQuestion(questionID int) error {
-	result := db.
+func (*Question) DeleteQuestion(ctx context.Context, questionID int) error {
+	result := getTx(ctx).
 		Where("id = ?", questionID).
 		Delete(&Questions{})
 	err := result.Error
@@ -114,11 +115,25 @@ func (*Question) DeleteQuestion(questionID int) error {
 	return nil
 }
 
+//GetQuestion 質問の取得
+func (*Question) GetQuestion(ctx context.Context, questionID int) (Questions, error) {
+	question := Questions{}
+
+	err := getTx(ctx).
+		Where("id = ?", questionID).
+		First(&question).Error
+	if err != nil {
+		return Questions{}, fmt.Errorf("failed to get a question: %w", err)
+	}
+
+	return question, nil
+}
+
 //GetQuestions 質問一覧の取得
-func (*Question) GetQuestions(questionnaireID int) ([]Questions, error) {
+func (*Question) GetQuestions(ctx context.Context, questionnaireID int) ([]Questions, error) {
 	questions := []Questions{}
 
-	err := db.
+	err := getTx(ctx).
 		Where("questionnaire_id = ?", questionnaireID).
 		Order("question_num").
 		Find(&questions).Error
@@ -130,12 +145,31 @@ func (*Question) GetQuestions(questionnaireID int) ([]Questions, error) {
 	return questions, nil
 }
 
-// CheckQuestionAdmin Questionの管理者か
-func (*Question) CheckQuestionAdmin(userID string, questionID int) (bool, error) {
-	err := db.
+// GetAnsweredQuestionIDs アンケートの質問のうち回答が存在するもののIDの取得
+func (*Question) GetAnsweredQuestionIDs(ctx context.Context, questionnaireID int) ([]int, error) {
+	questionIDs := []int{}
+
+	err := getTx(ctx).
+		Table("response").
+		Joins("INNER JOIN respondents ON response.response_id = respondents.response_id").
+		Joins("INNER JOIN question ON response.question_id = question.id").
+		Where("question.questionnaire_id = ? AND response.deleted_at IS NULL AND respondents.deleted_at IS NULL", questionnaireID).
+		Group("response.question_id").
+		Pluck("response.question_id", &questionIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answered questionIDs: %w", err)
+	}
+
+	return questionIDs, nil
+}
+
+// CheckQuestionAdmin Questionを編集できる管理者 (オーナーか編集者) か
+func (*Question) CheckQuestionAdmin(ctx context.Context, userID string, questionID int) (bool, error) {
+	err := getTx(ctx).
 		Table("question").
+		Joins("INNER JOIN questionnaires ON question.questionnaire_id = questionnaires.id").
 		Joins("INNER JOIN administrators ON question.questionnaire_id = administrators.questionnaire_id").
-		Where("question.id = ? AND administrators.user_traqid = ?", questionID, userID).
+		Where("question.id = ? AND questionnaires.deleted_at IS NULL AND administrators.user_traqid = ? AND administrators.role IN (?)", questionID, userID, []string{RoleOwner, RoleEditor}).
 		Select("question.id").
 		Find(&Questions{}).Error
 	if gorm.IsRecordNotFoundError(err) {
@@ -147,3 +181,18 @@ func (*Question) CheckQuestionAdmin(userID string, questionID int) (bool, error)
 
 	return true, nil
 }
+
+// CheckQuestionAnswered 質問に対する回答が存在するか
+func (*Question) CheckQuestionAnswered(ctx context.Context, questionID int) (bool, error) {
+	count := 0
+	err := getTx(ctx).
+		Table("response").
+		Joins("INNER JOIN respondents ON response.response_id = respondents.response_id").
+		Where("response.question_id = ? AND response.deleted_at IS NULL AND respondents.deleted_at IS NULL", questionID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to count responses: %w", err)
+	}
+
+	return count != 0, nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"sort"
 	"testing"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
 )
 
 const questionsTestUserID = "questionsUser"
@@ -199,6 +202,8 @@ func setupQuestionsTest(t *testing.T) {
 
 func insertQuestionTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -394,7 +399,7 @@ func insertQuestionTest(t *testing.T) {
 
 	for _, testCase := range testCases {
 		createdAt := time.Now()
-		questionID, err := questionImpl.InsertQuestion(testCase.args.QuestionnaireID, testCase.args.PageNum, testCase.args.QuestionNum, testCase.args.Type, testCase.args.Body, testCase.args.IsRequired)
+		questionID, err := questionImpl.InsertQuestion(ctx, testCase.args.QuestionnaireID, testCase.args.PageNum, testCase.args.QuestionNum, testCase.args.Type, testCase.args.Body, testCase.args.IsRequired)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -425,6 +430,8 @@ func insertQuestionTest(t *testing.T) {
 
 func updateQuestionTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -657,7 +664,7 @@ func updateQuestionTest(t *testing.T) {
 			t.Errorf("failed to insert question(%s): %w", testCase.description, err)
 		}
 
-		err = questionImpl.UpdateQuestion(testCase.after.QuestionnaireID, testCase.after.PageNum, testCase.after.QuestionNum, testCase.after.Type, testCase.after.Body, testCase.after.IsRequired, question.ID)
+		err = questionImpl.UpdateQuestion(ctx, testCase.after.QuestionnaireID, testCase.after.PageNum, testCase.after.QuestionNum, testCase.after.Type, testCase.after.Body, testCase.after.IsRequired, question.ID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -688,6 +695,8 @@ func updateQuestionTest(t *testing.T) {
 
 func deleteQuestionTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -757,7 +766,7 @@ func deleteQuestionTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		err := questionImpl.DeleteQuestion(testCase.args.questionID)
+		err := questionImpl.DeleteQuestion(ctx, testCase.args.questionID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -780,6 +789,8 @@ func deleteQuestionTest(t *testing.T) {
 
 func getQuestionsTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -827,7 +838,7 @@ func getQuestionsTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questions, err := questionImpl.GetQuestions(testCase.args.questionnaireID)
+		questions, err := questionImpl.GetQuestions(ctx, testCase.args.questionnaireID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -873,6 +884,8 @@ func getQuestionsTest(t *testing.T) {
 
 func checkQuestionAdminTest(t *testing.T) {
 	t.Helper()
+
+	ctx := context.Background()
 	t.Parallel()
 
 	assertion := assert.New(t)
@@ -935,7 +948,7 @@ func checkQuestionAdminTest(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualIsAdmin, err := questionImpl.CheckQuestionAdmin(testCase.args.userID, testCase.args.questionID)
+		actualIsAdmin, err := questionImpl.CheckQuestionAdmin(ctx, testCase.args.userID, testCase.args.questionID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -949,3 +962,117 @@ func checkQuestionAdminTest(t *testing.T) {
 		assertion.Equal(testCase.expect.isAdmin, actualIsAdmin, testCase.description, "isAdmin")
 	}
 }
+
+func TestCheckQuestionAnswered(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	answeredQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
+	require.NoError(t, err)
+	unansweredQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 2, "Text", "質問文", true)
+	require.NoError(t, err)
+	deletedQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 3, "Text", "質問文", true)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+		{QuestionID: answeredQuestionID, Data: "リマインダーBOTを作った話"},
+	})
+	require.NoError(t, err)
+
+	deletedResponseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, deletedResponseID, []*ResponseMeta{
+		{QuestionID: deletedQuestionID, Data: "リマインダーBOTを作った話"},
+	})
+	require.NoError(t, err)
+	err = respondentImpl.DeleteRespondent(ctx, userTwo, deletedResponseID)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		questionID  int
+		isAnswered  bool
+	}{
+		{
+			description: "answered",
+			questionID:  answeredQuestionID,
+			isAnswered:  true,
+		},
+		{
+			description: "unanswered",
+			questionID:  unansweredQuestionID,
+			isAnswered:  false,
+		},
+		{
+			description: "response deleted",
+			questionID:  deletedQuestionID,
+			isAnswered:  false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		isAnswered, err := questionImpl.CheckQuestionAnswered(ctx, testCase.questionID)
+		assertion.NoError(err, testCase.description, "no error")
+		assertion.Equal(testCase.isAnswered, isAnswered, testCase.description, "isAnswered")
+	}
+
+	answeredQuestionIDs, err := questionImpl.GetAnsweredQuestionIDs(ctx, questionnaireID)
+	assertion.NoError(err, "GetAnsweredQuestionIDs", "no error")
+	assertion.Equal([]int{answeredQuestionID}, answeredQuestionIDs, "GetAnsweredQuestionIDs", "questionIDs")
+}
+
+func TestCheckQuestionAdminWithRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回集会らん☆ぷろ参加者募集", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userTwo}, RoleEditor)
+	require.NoError(t, err)
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userThree}, RoleViewer)
+	require.NoError(t, err)
+
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description string
+		userID      string
+		isAdmin     bool
+	}{
+		{
+			description: "owner",
+			userID:      userOne,
+			isAdmin:     true,
+		},
+		{
+			description: "editor",
+			userID:      userTwo,
+			isAdmin:     true,
+		},
+		{
+			description: "viewer cannot edit questions",
+			userID:      userThree,
+			isAdmin:     false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		isAdmin, err := questionImpl.CheckQuestionAdmin(ctx, testCase.userID, questionID)
+		assertion.NoError(err, testCase.description, "no error")
+		assertion.Equal(testCase.isAdmin, isAdmin, testCase.description, "isAdmin")
+	}
+}
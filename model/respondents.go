@@ -2,17 +2,30 @@
 
 package model
 
-import "gopkg.in/guregu/null.v3"
+import (
+	"context"
+
+	"gopkg.in/guregu/null.v3"
+)
 
 // IRespondent RespondentのRepository
 type IRespondent interface {
-	InsertRespondent(userID string, questionnaireID int, submitedAt null.Time) (int, error)
-	UpdateSubmittedAt(responseID int) error
-	DeleteRespondent(userID string, responseID int) error
-	GetRespondentInfos(userID string, questionnaireIDs ...int) ([]RespondentInfo, error)
-	GetRespondentDetail(responseID int) (RespondentDetail, error)
-	GetRespondentDetails(questionnaireID int, sort string) ([]RespondentDetail, error)
-	GetRespondentsUserIDs(questionnaireIDs []int) ([]Respondents, error)
-	CheckRespondent(userID string, questionnaireID int) (bool, error)
-	CheckRespondentByResponseID(userID string, responseID int) (bool, error)
+	// InsertRespondent 回答上限に達している場合は既存の回答のIDとErrResponseAlreadyExistsを返す
+	InsertRespondent(ctx context.Context, userID string, questionnaireID int, submitedAt null.Time) (int, error)
+	// InsertProxyRespondent 回答上限に達している場合は既存の回答のIDとErrResponseAlreadyExistsを返す
+	InsertProxyRespondent(ctx context.Context, userID string, enteredBy string, questionnaireID int) (int, error)
+	UpdateSubmittedAt(ctx context.Context, responseID int) error
+	// UpdateEditedAt 送信したことのない回答では何もしない
+	UpdateEditedAt(ctx context.Context, responseID int) error
+	DeleteRespondent(ctx context.Context, userID string, responseID int) error
+	GetRespondentInfos(ctx context.Context, userID string, questionnaireIDs ...int) ([]RespondentInfo, error)
+	GetRespondentDetail(ctx context.Context, responseID int) (RespondentDetail, error)
+	GetRespondentDetails(ctx context.Context, questionnaireID int, sort string) ([]RespondentDetail, error)
+	GetRespondentDetailsPage(ctx context.Context, questionnaireID int, sort string, filter string, limit int, after string) ([]RespondentDetail, string, error)
+	GetRespondentProgress(ctx context.Context, questionnaireID int) ([]RespondentProgress, error)
+	GetRespondentsUserIDs(ctx context.Context, questionnaireIDs []int) ([]Respondents, error)
+	CheckRespondent(ctx context.Context, userID string, questionnaireID int) (bool, error)
+	CheckRespondentByResponseID(ctx context.Context, userID string, responseID int) (bool, error)
+	// GetSubmittedRespondentID 回答数に制限が無い場合や送信済みの回答の枠が空いている場合はnullを返す
+	GetSubmittedRespondentID(ctx context.Context, userID string, questionnaireID int) (null.Int, error)
 }
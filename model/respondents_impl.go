@@ -1,10 +1,15 @@
 package model
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
-	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -14,6 +19,19 @@ import (
 // Respondent RespondentRepositoryの実装
 type Respondent struct{}
 
+// anonymousSecret 匿名のアンケートの回答者をハッシュ化するときに加える秘密の値
+var anonymousSecret string
+
+// SetAnonymousSecret 匿名のアンケートの回答者のハッシュ化に使う秘密の値の設定
+func SetAnonymousSecret(secret string) error {
+	if secret == "" {
+		return ErrNoAnonymousSecret
+	}
+	anonymousSecret = secret
+
+	return nil
+}
+
 // NewRespondent Respondentのコンストラクター
 func NewRespondent() *Respondent {
 	return new(Respondent)
@@ -21,12 +39,19 @@ func NewRespondent() *Respondent {
 
 //Respondents respondentsテーブルの構造体
 type Respondents struct {
-	ResponseID      int       `json:"responseID" gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
-	QuestionnaireID int       `json:"questionnaireID" gorm:"type:int(11) NOT NULL;"`
-	UserTraqid      string    `json:"user_traq_id,omitempty" gorm:"type:char(30) NOT NULL;"`
-	ModifiedAt      time.Time `json:"modified_at,omitempty" gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
-	SubmittedAt     null.Time `json:"submitted_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
-	DeletedAt       null.Time `json:"deleted_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
+	ResponseID      int         `json:"responseID" gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	QuestionnaireID int         `json:"questionnaireID" gorm:"type:int(11) NOT NULL;"`
+	UserTraqid      string      `json:"user_traq_id,omitempty" gorm:"type:char(30) NOT NULL;"`
+	RevisionID      null.Int    `json:"revisionID,omitempty" gorm:"type:int(11) NULL;default:NULL;"`
+	EnteredBy       null.String `json:"entered_by,omitempty" gorm:"type:char(30) NULL;default:NULL;"`
+	ResponseSlot    null.String `json:"-" gorm:"type:char(20) NULL;default:NULL;"`
+	ModifiedAt      time.Time   `json:"modified_at,omitempty" gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	SubmittedAt     null.Time   `json:"submitted_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
+	// FirstSubmittedAt 最初に送信した日時 (送信済みの回答を編集しても変わらない)
+	FirstSubmittedAt null.Time `json:"first_submitted_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
+	// EditedAt 送信済みの回答を最後に編集した日時
+	EditedAt  null.Time `json:"edited_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
+	DeletedAt null.Time `json:"deleted_at,omitempty" gorm:"type:timestamp NULL;default:NULL;"`
 }
 
 //BeforeCreate insert時に自動でmodifiedAt更新
@@ -63,27 +88,70 @@ type RespondentDetail struct {
 	QuestionnaireID int            `json:"questionnaireID,omitempty"`
 	SubmittedAt     null.Time      `json:"submitted_at,omitempty"`
 	ModifiedAt      time.Time      `json:"modified_at,omitempty"`
+	EnteredBy       null.String    `json:"entered_by"`
 	Responses       []ResponseBody `json:"body"`
 }
 
 //InsertRespondent 回答の追加
-func (*Respondent) InsertRespondent(userID string, questionnaireID int, submitedAt null.Time) (int, error) {
+func (*Respondent) InsertRespondent(ctx context.Context, userID string, questionnaireID int, submitedAt null.Time) (int, error) {
+	return insertRespondent(ctx, userID, questionnaireID, submitedAt, null.String{})
+}
+
+// InsertProxyRespondent 管理者が代理で入力した送信済みの回答の追加
+func (*Respondent) InsertProxyRespondent(ctx context.Context, userID string, enteredBy string, questionnaireID int) (int, error) {
+	return insertRespondent(ctx, userID, questionnaireID, null.TimeFrom(time.Now()), null.StringFrom(enteredBy))
+}
+
+func insertRespondent(ctx context.Context, userID string, questionnaireID int, submitedAt null.Time, enteredBy null.String) (int, error) {
 	var respondent Respondents
 	if submitedAt.Valid {
 		respondent = Respondents{
-			QuestionnaireID: questionnaireID,
-			UserTraqid:      userID,
-			SubmittedAt:     submitedAt,
+			QuestionnaireID:  questionnaireID,
+			UserTraqid:       userID,
+			EnteredBy:        enteredBy,
+			SubmittedAt:      submitedAt,
+			FirstSubmittedAt: submitedAt,
 		}
 	} else {
 		respondent = Respondents{
 			QuestionnaireID: questionnaireID,
 			UserTraqid:      userID,
+			EnteredBy:       enteredBy,
 		}
 	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
-		err := tx.Create(&respondent).Error
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		slot, err := getResponseSlot(tx, questionnaireID, submitedAt.Valid)
+		if err != nil {
+			return fmt.Errorf("failed to get the response slot: %w", err)
+		}
+
+		respondentKey, err := getRespondentKey(tx, questionnaireID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get the respondent key: %w", err)
+		}
+		respondent.UserTraqid = respondentKey
+
+		if slot.Valid {
+			existingID, err := getRespondentIDBySlot(tx, respondentKey, questionnaireID, slot.String)
+			if err != nil {
+				return fmt.Errorf("failed to get the existing respondent: %w", err)
+			}
+			if existingID.Valid {
+				respondent.ResponseID = int(existingID.Int64)
+				return ErrResponseAlreadyExists
+			}
+		}
+		respondent.ResponseSlot = slot
+
+		// 回答時点のアンケートの内容を辿れるようにリビジョンを紐づける
+		revisionID, err := getLastRevisionID(tx, questionnaireID)
+		if err != nil {
+			return fmt.Errorf("failed to get the last revision: %w", err)
+		}
+		respondent.RevisionID = revisionID
+
+		err = tx.Create(&respondent).Error
 		if err != nil {
 			return fmt.Errorf("failed to insert a respondent record: %w", err)
 		}
@@ -95,6 +163,9 @@ func (*Respondent) InsertRespondent(userID string, questionnaireID int, submited
 
 		return nil
 	})
+	if errors.Is(err, ErrResponseAlreadyExists) {
+		return respondent.ResponseID, err
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed in transaction: %w", err)
 	}
@@ -103,22 +174,83 @@ func (*Respondent) InsertRespondent(userID string, questionnaireID int, submited
 }
 
 // UpdateSubmittedAt 投稿日時更新
-func (*Respondent) UpdateSubmittedAt(responseID int) error {
-	err := db.
+// 最初に送信した日時は変えない
+func (*Respondent) UpdateSubmittedAt(ctx context.Context, responseID int) error {
+	return runInTx(ctx, func(tx *gorm.DB) error {
+		respondent := Respondents{}
+		err := tx.
+			Where("response_id = ?", responseID).
+			Select("questionnaire_id, user_traqid, response_slot, first_submitted_at").
+			First(&respondent).Error
+		if err != nil {
+			return fmt.Errorf("failed to get a respondent: %w", err)
+		}
+
+		// 下書きを送信する場合は送信済みの回答の枠に移す
+		slot := respondent.ResponseSlot
+		if slot.Valid {
+			slot, err = getResponseSlot(tx, respondent.QuestionnaireID, true)
+			if err != nil {
+				return fmt.Errorf("failed to get the response slot: %w", err)
+			}
+		}
+		if slot.Valid && slot != respondent.ResponseSlot {
+			existingID, err := getRespondentIDBySlot(tx, respondent.UserTraqid, respondent.QuestionnaireID, slot.String)
+			if err != nil {
+				return fmt.Errorf("failed to get the existing respondent: %w", err)
+			}
+			if existingID.Valid {
+				return ErrResponseAlreadyExists
+			}
+		}
+
+		// 回答を送信した時点のリビジョンに紐づけ直す
+		revisionID, err := getLastRevisionID(tx, respondent.QuestionnaireID)
+		if err != nil {
+			return fmt.Errorf("failed to get the last revision: %w", err)
+		}
+
+		now := time.Now()
+		firstSubmittedAt := respondent.FirstSubmittedAt
+		if !firstSubmittedAt.Valid {
+			firstSubmittedAt = null.TimeFrom(now)
+		}
+
+		err = tx.
+			Model(&Respondents{}).
+			Where("response_id = ?", responseID).
+			Update(map[string]interface{}{
+				"submitted_at":       now,
+				"first_submitted_at": firstSubmittedAt,
+				"revision_id":        revisionID,
+				"response_slot":      slot,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update response's submitted_at: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UpdateEditedAt 送信済みの回答を編集した日時の記録
+func (*Respondent) UpdateEditedAt(ctx context.Context, responseID int) error {
+	err := getTx(ctx).
 		Model(&Respondents{}).
-		Where("response_id = ?", responseID).
-		Update("submitted_at", time.Now()).Error
+		Where("response_id = ? AND first_submitted_at IS NOT NULL", responseID).
+		Update("edited_at", time.Now()).Error
 	if err != nil {
-		return fmt.Errorf("failed to update response's submitted_at: %w", err)
+		return fmt.Errorf("failed to update response's edited_at: %w", err)
 	}
 
 	return nil
 }
 
 // DeleteRespondent 回答の削除
-func (*Respondent) DeleteRespondent(userID string, responseID int) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		result := tx.Exec("UPDATE `respondents` INNER JOIN administrators ON administrators.questionnaire_id = respondents.questionnaire_id SET `respondents`.`deleted_at` = ? WHERE (respondents.response_id = ? AND (administrators.user_traqid = ? OR respondents.user_traqid = ?))", time.Now(), responseID, userID, userID)
+func (*Respondent) DeleteRespondent(ctx context.Context, userID string, responseID int) error {
+	return runInTx(ctx, func(tx *gorm.DB) error {
+		args := append([]interface{}{time.Now(), responseID, userID}, respondentUserArgs(userID)...)
+		result := tx.Exec("UPDATE `respondents` INNER JOIN administrators ON administrators.questionnaire_id = respondents.questionnaire_id SET `respondents`.`deleted_at` = ?, `respondents`.`response_slot` = NULL WHERE (respondents.response_id = ? AND (administrators.user_traqid = ? OR "+respondentUserCondition+"))", args...)
 		err := result.Error
 		if err != nil {
 			return fmt.Errorf("failed to delete respondents: %w", err)
@@ -137,15 +269,107 @@ func (*Respondent) DeleteRespondent(userID string, responseID int) error {
 	})
 }
 
+// getResponseSlot アンケートの回答モードから回答の枠を決める
+// 回答数に制限がない場合はNULLを返す
+func getResponseSlot(tx *gorm.DB, questionnaireID int, submitted bool) (null.String, error) {
+	questionnaire := Questionnaires{}
+	// 同時に回答された場合に備えてアンケートの行をロックする
+	err := tx.
+		Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ?", questionnaireID).
+		Select("response_mode").
+		First(&questionnaire).Error
+	if err != nil {
+		return null.String{}, fmt.Errorf("failed to get the questionnaire: %w", err)
+	}
+
+	return responseSlotOf(questionnaire.ResponseMode, submitted), nil
+}
+
+// responseSlotOf 回答モードで回答が入る枠
+func responseSlotOf(responseMode string, submitted bool) null.String {
+	switch responseMode {
+	case ResponseModeOnce:
+		return null.StringFrom("once")
+	case ResponseModeOnceWithDraft:
+		if submitted {
+			return null.StringFrom("submitted")
+		}
+		return null.StringFrom("draft")
+	}
+
+	return null.String{}
+}
+
+// respondentUserCondition respondentsのうちユーザー自身の回答を絞り込む条件
+// 引数はrespondentUserArgsで渡す
+const respondentUserCondition = "respondents.user_traqid IN (?, LEFT(SHA2(CONCAT(?, ':', respondents.questionnaire_id, ':', ?), 256), 30))"
+
+// 秘密の値が無い場合はNULLにしてハッシュ化した回答者には一致させない
+func respondentUserArgs(userID string) []interface{} {
+	return []interface{}{userID, null.NewString(anonymousSecret, anonymousSecret != ""), userID}
+}
+
+// anonymousUserTraqidColumn 匿名のアンケートでは回答者を空文字列にしたuser_traqid
+// questionnairesと結合して使う
+const anonymousUserTraqidColumn = "IF(questionnaires.is_anonymous, '', respondents.user_traqid) AS user_traqid"
+
+// anonymizeUserID 匿名のアンケートで回答者の代わりに保存する値
+// traQIDから復元できないよう秘密の値を加えてハッシュ化する(respondentUserConditionと同じ計算)
+func anonymizeUserID(questionnaireID int, userID string) (string, error) {
+	if anonymousSecret == "" {
+		return "", ErrNoAnonymousSecret
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", anonymousSecret, questionnaireID, userID)))
+	return hex.EncodeToString(hash[:])[:30], nil
+}
+
+// getRespondentKey respondents.user_traqidに保存する回答者の値を取得
+func getRespondentKey(tx *gorm.DB, questionnaireID int, userID string) (string, error) {
+	questionnaire := Questionnaires{}
+	err := tx.
+		Where("id = ?", questionnaireID).
+		Select("is_anonymous").
+		First(&questionnaire).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get the questionnaire: %w", err)
+	}
+
+	if questionnaire.IsAnonymous {
+		return anonymizeUserID(questionnaireID, userID)
+	}
+
+	return userID, nil
+}
+
+// getRespondentIDBySlot 同じ枠の削除されていない回答のIDを取得
+func getRespondentIDBySlot(tx *gorm.DB, userID string, questionnaireID int, slot string) (null.Int, error) {
+	respondent := Respondents{}
+	err := tx.
+		Where("questionnaire_id = ? AND user_traqid = ? AND response_slot = ? AND deleted_at IS NULL", questionnaireID, userID, slot).
+		Select("response_id").
+		First(&respondent).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return null.Int{}, nil
+	}
+	if err != nil {
+		return null.Int{}, fmt.Errorf("failed to get the respondent: %w", err)
+	}
+
+	return null.IntFrom(int64(respondent.ResponseID)), nil
+}
+
 // GetRespondentInfos ユーザーの回答とその周辺情報一覧の取得
-func (*Respondent) GetRespondentInfos(userID string, questionnaireIDs ...int) ([]RespondentInfo, error) {
+func (*Respondent) GetRespondentInfos(ctx context.Context, userID string, questionnaireIDs ...int) ([]RespondentInfo, error) {
 	respondentInfos := []RespondentInfo{}
 
-	query := db.
+	query := getTx(ctx).
 		Table("respondents").
 		Joins("LEFT OUTER JOIN questionnaires ON respondents.questionnaire_id = questionnaires.id").
 		Order("respondents.submitted_at DESC").
-		Where("user_traqid = ? AND respondents.deleted_at IS NULL AND questionnaires.deleted_at IS NULL", userID)
+		Where(respondentUserCondition, respondentUserArgs(userID)...).
+		Where("respondents.deleted_at IS NULL AND questionnaires.deleted_at IS NULL")
 
 	if len(questionnaireIDs) != 0 {
 		questionnaireID := questionnaireIDs[0]
@@ -168,7 +392,7 @@ func (*Respondent) GetRespondentInfos(userID string, questionnaireIDs ...int) ([
 			Respondents: Respondents{},
 		}
 
-		err := db.ScanRows(rows, &respondentInfo)
+		err := getTx(ctx).ScanRows(rows, &respondentInfo)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan responses: %w", err)
 		}
@@ -180,13 +404,14 @@ func (*Respondent) GetRespondentInfos(userID string, questionnaireIDs ...int) ([
 }
 
 // GetRespondentDetail 回答のIDから回答の詳細情報を取得
-func (*Respondent) GetRespondentDetail(responseID int) (RespondentDetail, error) {
-	rows, err := db.
+// 削除された質問への回答は含めない
+func (*Respondent) GetRespondentDetail(ctx context.Context, responseID int) (RespondentDetail, error) {
+	rows, err := getTx(ctx).
 		Table("respondents").
-		Joins("LEFT OUTER JOIN question ON respondents.questionnaire_id = question.questionnaire_id").
+		Joins("LEFT OUTER JOIN question ON respondents.questionnaire_id = question.questionnaire_id AND question.deleted_at IS NULL").
 		Joins("LEFT OUTER JOIN response ON respondents.response_id = response.response_id AND question.id = response.question_id AND response.deleted_at IS NULL").
 		Where("respondents.response_id = ? AND respondents.deleted_at IS NULL", responseID).
-		Select("respondents.questionnaire_id, respondents.modified_at, respondents.submitted_at, question.id, question.type, response.body").
+		Select("respondents.questionnaire_id, respondents.entered_by, respondents.modified_at, respondents.submitted_at, question.id, question.type, response.body, response.modified_at AS response_modified_at").
 		Rows()
 	if err != nil {
 		return RespondentDetail{}, fmt.Errorf("failed to get respondents: %w", err)
@@ -200,9 +425,9 @@ func (*Respondent) GetRespondentDetail(responseID int) (RespondentDetail, error)
 		isNoRows = false
 		res := struct {
 			Respondents  `gorm:"embedded"`
-			ResponseBody `gorm:"embedded"`
+			ResponseBody `gorm:"embedded" json:"-"`
 		}{}
-		err := db.ScanRows(rows, &res)
+		err := getTx(ctx).ScanRows(rows, &res)
 		if err != nil {
 			return RespondentDetail{}, fmt.Errorf("failed to scan response detail: %w", err)
 		}
@@ -210,12 +435,18 @@ func (*Respondent) GetRespondentDetail(responseID int) (RespondentDetail, error)
 			respondentDetail.QuestionnaireID = res.Respondents.QuestionnaireID
 			respondentDetail.SubmittedAt = res.Respondents.SubmittedAt
 			respondentDetail.ModifiedAt = res.Respondents.ModifiedAt
+			respondentDetail.EnteredBy = res.Respondents.EnteredBy
 			isRespondentSetted = true
 		}
+		// 質問が無いアンケートの回答者の行
+		if res.ResponseBody.QuestionID == 0 {
+			continue
+		}
 
 		respondentDetail.Responses = append(respondentDetail.Responses, ResponseBody{
 			QuestionID:   res.ResponseBody.QuestionID,
 			QuestionType: res.ResponseBody.QuestionType,
+			ModifiedAt:   res.ResponseBody.ModifiedAt,
 		})
 
 		if res.ResponseBody.Body.Valid {
@@ -245,106 +476,260 @@ func (*Respondent) GetRespondentDetail(responseID int) (RespondentDetail, error)
 }
 
 // GetRespondentDetails アンケートの回答の詳細情報一覧の取得
-func (*Respondent) GetRespondentDetails(questionnaireID int, sort string) ([]RespondentDetail, error) {
-	query := db.
+func (*Respondent) GetRespondentDetails(ctx context.Context, questionnaireID int, sort string) ([]RespondentDetail, error) {
+	respondentDetails, _, err := getRespondentDetails(ctx, questionnaireID, sort, "", 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return respondentDetails, nil
+}
+
+// GetRespondentDetailsPage アンケートの回答の詳細情報一覧をfilterで絞り込んでlimit件ずつ取得
+// afterには前のページのカーソルを指定し，次のページが無い場合は空文字列のカーソルを返す
+func (*Respondent) GetRespondentDetailsPage(ctx context.Context, questionnaireID int, sort string, filter string, limit int, after string) ([]RespondentDetail, string, error) {
+	return getRespondentDetails(ctx, questionnaireID, sort, filter, limit, after)
+}
+
+// respondentsCursor 回答一覧のページのカーソル
+type respondentsCursor struct {
+	Sort       string `json:"sort"`
+	Key        string `json:"key"`
+	ResponseID int    `json:"responseID"`
+}
+
+func encodeRespondentsCursor(cursor respondentsCursor) (string, error) {
+	rawCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(rawCursor), nil
+}
+
+func decodeRespondentsCursor(strCursor string) (respondentsCursor, error) {
+	rawCursor, err := base64.RawURLEncoding.DecodeString(strCursor)
+	if err != nil {
+		return respondentsCursor{}, fmt.Errorf("failed to decode cursor: %w", ErrInvalidCursor)
+	}
+
+	var cursor respondentsCursor
+	err = json.Unmarshal(rawCursor, &cursor)
+	if err != nil {
+		return respondentsCursor{}, fmt.Errorf("failed to unmarshal cursor: %w", ErrInvalidCursor)
+	}
+
+	return cursor, nil
+}
+
+func getRespondentDetails(ctx context.Context, questionnaireID int, sort string, filter string, limit int, after string) ([]RespondentDetail, string, error) {
+	query := getTx(ctx).
 		Table("respondents").
-		Joins("LEFT OUTER JOIN question ON respondents.questionnaire_id = question.questionnaire_id").
-		Joins("LEFT OUTER JOIN response ON respondents.response_id = response.response_id AND question.id = response.question_id")
-	query, sortNum, err := setRespondentsOrder(query, sort)
+		Where("respondents.questionnaire_id = ? AND respondents.deleted_at IS NULL AND respondents.submitted_at IS NOT NULL", questionnaireID)
+	query, order, err := setRespondentsOrder(ctx, query, questionnaireID, sort)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set order: %w", err)
+		return nil, "", fmt.Errorf("failed to set order: %w", err)
+	}
+	if filter != "" {
+		query, err = setRespondentsFilter(ctx, query, questionnaireID, filter)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to set filter: %w", err)
+		}
 	}
 
-	rows, err := query.
-		Where("respondents.questionnaire_id = ? AND respondents.deleted_at IS NULL AND respondents.submitted_at IS NOT NULL AND question.deleted_at IS NULL AND response.deleted_at IS NULL", questionnaireID).
-		Select("respondents.response_id, respondents.user_traqid, respondents.modified_at, respondents.submitted_at, question.id, question.type, response.body").
+	operator := ">"
+	if order.desc {
+		operator = "<"
+	}
+	if after != "" {
+		cursor, err := decodeRespondentsCursor(after)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursor.Sort != sort {
+			return nil, "", fmt.Errorf("the cursor is for sort(%s): %w", cursor.Sort, ErrInvalidCursor)
+		}
+
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND respondents.response_id %[2]s ?))", order.key, operator),
+			cursor.Key, cursor.Key, cursor.ResponseID,
+		)
+	}
+	if limit > 0 {
+		// 次のページの有無を調べるために1件多く取得する
+		query = query.Limit(limit + 1)
+	}
+
+	respondentKeys := []struct {
+		ResponseID int
+		SortKey    string
+	}{}
+	err = query.
+		Select("respondents.response_id, CAST(" + order.key + " AS CHAR) AS sort_key").
+		Scan(&respondentKeys).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, "", fmt.Errorf("failed to get respondents: %w", err)
+	}
+
+	nextCursor := ""
+	if limit > 0 && len(respondentKeys) > limit {
+		respondentKeys = respondentKeys[:limit]
+		lastKey := respondentKeys[limit-1]
+		nextCursor, err = encodeRespondentsCursor(respondentsCursor{
+			Sort:       sort,
+			Key:        lastKey.SortKey,
+			ResponseID: lastKey.ResponseID,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if len(respondentKeys) == 0 {
+		return []RespondentDetail{}, "", nil
+	}
+
+	responseIDs := make([]int, 0, len(respondentKeys))
+	for _, respondentKey := range respondentKeys {
+		responseIDs = append(responseIDs, respondentKey.ResponseID)
+	}
+
+	rows, err := getTx(ctx).
+		Table("respondents").
+		Joins("LEFT OUTER JOIN question ON respondents.questionnaire_id = question.questionnaire_id").
+		Joins("LEFT OUTER JOIN response ON respondents.response_id = response.response_id AND question.id = response.question_id").
+		Joins("INNER JOIN questionnaires ON respondents.questionnaire_id = questionnaires.id").
+		Where("respondents.response_id IN (?) AND question.deleted_at IS NULL AND response.deleted_at IS NULL", responseIDs).
+		Select("respondents.response_id, " + anonymousUserTraqidColumn + ", respondents.questionnaire_id, respondents.entered_by, respondents.modified_at, respondents.submitted_at, question.id, question.type, response.body, response.modified_at AS response_modified_at").
 		Order("respondents.response_id, question.question_num").
 		Rows()
 	if err != nil {
-		if !gorm.IsRecordNotFoundError(err) {
-			return []RespondentDetail{}, nil
-		}
+		return nil, "", fmt.Errorf("failed to get respondent details: %w", err)
 	}
+	defer rows.Close()
 
-	respondentDetails := []RespondentDetail{}
+	respondentDetailMap := make(map[int]*RespondentDetail, len(responseIDs))
 	responseBodyMap := map[int][]ResponseBody{}
 	for rows.Next() {
 		res := struct {
 			Respondents  `gorm:"embedded"`
-			ResponseBody `gorm:"embedded"`
+			ResponseBody `gorm:"embedded" json:"-"`
 		}{}
-		err := db.ScanRows(rows, &res)
+		err := getTx(ctx).ScanRows(rows, &res)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan response detail: %w", err)
+			return nil, "", fmt.Errorf("failed to scan response detail: %w", err)
 		}
 
-		if _, ok := responseBodyMap[res.ResponseID]; !ok {
-			respondentDetails = append(respondentDetails, RespondentDetail{
+		if _, ok := respondentDetailMap[res.Respondents.ResponseID]; !ok {
+			respondentDetailMap[res.Respondents.ResponseID] = &RespondentDetail{
 				ResponseID:      res.Respondents.ResponseID,
 				TraqID:          res.UserTraqid,
 				QuestionnaireID: res.Respondents.QuestionnaireID,
 				SubmittedAt:     res.Respondents.SubmittedAt,
-				ModifiedAt:      res.ModifiedAt,
-			})
+				ModifiedAt:      res.Respondents.ModifiedAt,
+				EnteredBy:       res.Respondents.EnteredBy,
+			}
 		}
 
-		responseBodyMap[res.ResponseID] = append(responseBodyMap[res.ResponseID], res.ResponseBody)
+		if res.ResponseBody.QuestionID != 0 {
+			responseBodyMap[res.Respondents.ResponseID] = append(responseBodyMap[res.Respondents.ResponseID], res.ResponseBody)
+		}
 	}
 
-	for i := range respondentDetails {
-		responseDetail := &respondentDetails[i]
-		responseBodies := responseBodyMap[responseDetail.ResponseID]
+	// 1つ目のクエリで求めた順に並べる
+	respondentDetails := make([]RespondentDetail, 0, len(respondentKeys))
+	for _, respondentKey := range respondentKeys {
+		respondentDetail, ok := respondentDetailMap[respondentKey.ResponseID]
+		if !ok {
+			continue
+		}
+		respondentDetail.Responses = createResponseBodyList(responseBodyMap[respondentKey.ResponseID])
+		respondentDetails = append(respondentDetails, *respondentDetail)
+	}
 
-		responseBodyList := []ResponseBody{}
-		bodyMap := map[int][]string{}
-		for _, v := range responseBodies {
-			if _, ok := bodyMap[v.QuestionID]; !ok {
-				responseBodyList = append(responseBodyList, ResponseBody{
-					QuestionID:   v.QuestionID,
-					QuestionType: v.QuestionType,
-				})
-			}
+	return respondentDetails, nextCursor, nil
+}
 
-			if v.Body.Valid {
-				bodyMap[v.QuestionID] = append(bodyMap[v.QuestionID], v.Body.String)
-			}
+// createResponseBodyList 質問ごとの回答の行を質問ごとの回答にまとめる
+func createResponseBodyList(responseBodies []ResponseBody) []ResponseBody {
+	responseBodyList := []ResponseBody{}
+	bodyMap := map[int][]string{}
+	for _, v := range responseBodies {
+		if _, ok := bodyMap[v.QuestionID]; !ok {
+			responseBodyList = append(responseBodyList, ResponseBody{
+				QuestionID:   v.QuestionID,
+				QuestionType: v.QuestionType,
+				ModifiedAt:   v.ModifiedAt,
+			})
+			bodyMap[v.QuestionID] = nil
+		}
+
+		if v.Body.Valid {
+			bodyMap[v.QuestionID] = append(bodyMap[v.QuestionID], v.Body.String)
 		}
+	}
 
-		for i := range responseBodyList {
-			responseBody := &responseBodyList[i]
-			body := bodyMap[responseBody.QuestionID]
-			switch responseBody.QuestionType {
-			case "MultipleChoice", "Checkbox", "Dropdown":
-				if body == nil {
-					responseBody.OptionResponse = []string{}
-				} else {
-					responseBody.OptionResponse = body
-				}
-			default:
-				if len(body) == 0 {
-					responseBody.Body = null.NewString("", false)
-				} else {
-					responseBody.Body = null.NewString(body[0], true)
-				}
+	for i := range responseBodyList {
+		responseBody := &responseBodyList[i]
+		body := bodyMap[responseBody.QuestionID]
+		switch responseBody.QuestionType {
+		case "MultipleChoice", "Checkbox", "Dropdown":
+			if body == nil {
+				responseBody.OptionResponse = []string{}
+			} else {
+				responseBody.OptionResponse = body
+			}
+		default:
+			if len(body) == 0 {
+				responseBody.Body = null.NewString("", false)
+			} else {
+				responseBody.Body = null.NewString(body[0], true)
 			}
 		}
-		responseDetail.Responses = responseBodyList
 	}
 
-	respondentDetails, err = sortRespondentDetail(sortNum, respondentDetails)
+	return responseBodyList
+}
+
+// RespondentProgress ユーザーごとの回答の状況
+type RespondentProgress struct {
+	UserTraqid     string
+	DraftCount     int
+	SubmittedCount int
+	// EditedCount 送信後に編集された送信済みの回答の数
+	EditedCount int
+	ModifiedAt  null.Time
+	SubmittedAt null.Time
+}
+
+// GetRespondentProgress アンケートのユーザーごとの回答の状況の取得
+func (*Respondent) GetRespondentProgress(ctx context.Context, questionnaireID int) ([]RespondentProgress, error) {
+	progresses := []RespondentProgress{}
+	err := getTx(ctx).
+		Table("respondents").
+		Where("respondents.questionnaire_id = ? AND respondents.deleted_at IS NULL", questionnaireID).
+		Select("respondents.user_traqid, " +
+			"SUM(respondents.submitted_at IS NULL) AS draft_count, " +
+			"SUM(respondents.submitted_at IS NOT NULL) AS submitted_count, " +
+			"SUM(respondents.submitted_at IS NOT NULL AND respondents.edited_at IS NOT NULL) AS edited_count, " +
+			"MAX(respondents.modified_at) AS modified_at, " +
+			"MAX(respondents.submitted_at) AS submitted_at").
+		Group("respondents.user_traqid").
+		Scan(&progresses).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to sort RespondentDetails: %w", err)
+		return nil, fmt.Errorf("failed to get respondent progress: %w", err)
 	}
 
-	return respondentDetails, nil
+	return progresses, nil
 }
 
 // GetRespondentsUserIDs 回答者のユーザーID取得
-func (*Respondent) GetRespondentsUserIDs(questionnaireIDs []int) ([]Respondents, error) {
+func (*Respondent) GetRespondentsUserIDs(ctx context.Context, questionnaireIDs []int) ([]Respondents, error) {
 	respondents := []Respondents{}
-	err := db.
-		Where("questionnaire_id IN (?)", questionnaireIDs).
-		Select("questionnaire_id, user_traqid").
+	err := getTx(ctx).
+		Joins("INNER JOIN questionnaires ON respondents.questionnaire_id = questionnaires.id").
+		Where("respondents.questionnaire_id IN (?)", questionnaireIDs).
+		Select("respondents.questionnaire_id, " + anonymousUserTraqidColumn).
 		Find(&respondents).Error
 	if err != nil {
 		return []Respondents{}, nil
@@ -354,9 +739,10 @@ func (*Respondent) GetRespondentsUserIDs(questionnaireIDs []int) ([]Respondents,
 }
 
 // CheckRespondent 回答者かどうかの確認
-func (*Respondent) CheckRespondent(userID string, questionnaireID int) (bool, error) {
-	err := db.
-		Where("user_traqid = ? AND questionnaire_id = ?", userID, questionnaireID).
+func (*Respondent) CheckRespondent(ctx context.Context, userID string, questionnaireID int) (bool, error) {
+	err := getTx(ctx).
+		Where(respondentUserCondition, respondentUserArgs(userID)...).
+		Where("questionnaire_id = ?", questionnaireID).
 		First(&Respondents{}).Error
 	if gorm.IsRecordNotFoundError(err) {
 		return false, nil
@@ -368,10 +754,37 @@ func (*Respondent) CheckRespondent(userID string, questionnaireID int) (bool, er
 	return true, nil
 }
 
+// GetSubmittedRespondentID ユーザーの送信済みの回答の枠にある回答のIDを取得
+func (*Respondent) GetSubmittedRespondentID(ctx context.Context, userID string, questionnaireID int) (null.Int, error) {
+	tx := getTx(ctx)
+
+	questionnaire := Questionnaires{}
+	err := tx.
+		Where("id = ?", questionnaireID).
+		Select("response_mode").
+		First(&questionnaire).Error
+	if err != nil {
+		return null.Int{}, fmt.Errorf("failed to get the questionnaire: %w", err)
+	}
+
+	slot := responseSlotOf(questionnaire.ResponseMode, true)
+	if !slot.Valid {
+		return null.Int{}, nil
+	}
+
+	respondentKey, err := getRespondentKey(tx, questionnaireID, userID)
+	if err != nil {
+		return null.Int{}, fmt.Errorf("failed to get the respondent key: %w", err)
+	}
+
+	return getRespondentIDBySlot(tx, respondentKey, questionnaireID, slot.String)
+}
+
 // CheckRespondentByResponseID 回答者かどうかの確認
-func (*Respondent) CheckRespondentByResponseID(userID string, responseID int) (bool, error) {
-	err := db.
-		Where("user_traqid = ? AND response_id = ?", userID, responseID).
+func (*Respondent) CheckRespondentByResponseID(ctx context.Context, userID string, responseID int) (bool, error) {
+	err := getTx(ctx).
+		Where(respondentUserCondition, respondentUserArgs(userID)...).
+		Where("response_id = ?", responseID).
 		First(&Respondents{}).Error
 	if gorm.IsRecordNotFoundError(err) {
 		return false, nil
@@ -383,56 +796,60 @@ func (*Respondent) CheckRespondentByResponseID(userID string, responseID int) (b
 	return true, nil
 }
 
-func setRespondentsOrder(query *gorm.DB, sort string) (*gorm.DB, int, error) {
-	var sortNum int
+// respondentsOrder 回答一覧の並び順
+type respondentsOrder struct {
+	// key 並べ替えに使うSQLの式 (同じ値の場合はresponse_idで並べる)
+	key  string
+	desc bool
+}
+
+func setRespondentsOrder(ctx context.Context, query *gorm.DB, questionnaireID int, sort string) (*gorm.DB, respondentsOrder, error) {
+	order := respondentsOrder{
+		key: "respondents.response_id",
+	}
 	switch sort {
-	case "traqid":
-		query = query.Order("respondents.user_traqid")
-	case "-traqid":
-		query = query.Order("respondents.user_traqid DESC")
-	case "submitted_at":
-		query = query.Order("respondents.submitted_at")
-	case "-submitted_at":
-		query = query.Order("respondents.submitted_at DESC")
+	case "traqid", "-traqid":
+		order.key = "respondents.user_traqid"
+	case "submitted_at", "-submitted_at":
+		order.key = "respondents.submitted_at"
 	case "":
 	default:
-		var err error
-		sortNum, err = strconv.Atoi(sort)
+		sortNum, err := strconv.Atoi(strings.TrimPrefix(sort, "-"))
+		if err != nil || sortNum <= 0 {
+			return nil, respondentsOrder{}, fmt.Errorf("failed to convert sort param(%s) to int: %w", sort, ErrInvalidSortParam)
+		}
+
+		// sortNum番目の質問の回答で並べる
+		question := Questions{}
+		err = getTx(ctx).
+			Where("questionnaire_id = ? AND deleted_at IS NULL", questionnaireID).
+			Order("question_num").
+			Offset(sortNum - 1).
+			Limit(1).
+			Select("id, type").
+			Find(&question).Error
+		if gorm.IsRecordNotFoundError(err) {
+			break
+		}
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to convert sort param to int: %w", err)
+			return nil, respondentsOrder{}, fmt.Errorf("failed to get question: %w", err)
 		}
-	}
 
-	return query, sortNum, nil
-}
+		// 複数の回答がある質問では最小の回答で並べる
+		query = query.Joins("LEFT OUTER JOIN (SELECT response_id, MIN(body) AS body FROM response WHERE question_id = ? AND deleted_at IS NULL GROUP BY response_id) AS sort_response ON respondents.response_id = sort_response.response_id", question.ID)
+		if question.Type == "Number" {
+			order.key = "COALESCE(sort_response.body + 0, -1.7976931348623157e308)"
+		} else {
+			order.key = "COALESCE(sort_response.body, '')"
+		}
+	}
+	order.desc = strings.HasPrefix(sort, "-")
 
-func sortRespondentDetail(sortNum int, respondentDetails []RespondentDetail) ([]RespondentDetail, error) {
-	if sortNum == 0 {
-		return respondentDetails, nil
+	if order.desc {
+		query = query.Order(order.key + " DESC").Order("respondents.response_id DESC")
+	} else {
+		query = query.Order(order.key).Order("respondents.response_id")
 	}
-	sortNumAbs := int(math.Abs(float64(sortNum)))
-	sort.Slice(respondentDetails, func(i, j int) bool {
-		bodyI := respondentDetails[i].Responses[sortNumAbs-1]
-		bodyJ := respondentDetails[j].Responses[sortNumAbs-1]
-		if bodyI.QuestionType == "Number" {
-			numi, err := strconv.ParseFloat(bodyI.Body.String, 64)
-			if err != nil {
-				return true
-			}
-			numj, err := strconv.ParseFloat(bodyJ.Body.String, 64)
-			if err != nil {
-				return true
-			}
-			if sortNum < 0 {
-				return numi > numj
-			}
-			return numi < numj
-		}
-		if sortNum < 0 {
-			return bodyI.Body.String > bodyJ.Body.String
-		}
-		return bodyI.Body.String < bodyJ.Body.String
-	})
 
-	return respondentDetails, nil
+	return query, order, nil
 }
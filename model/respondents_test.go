@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -14,12 +15,14 @@ import (
 func TestInsertRespondent(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -92,7 +95,7 @@ func TestInsertRespondent(t *testing.T) {
 			questionnaireID = -1
 		}
 
-		responseID, err := respondentImpl.InsertRespondent(testCase.args.userID, questionnaireID, testCase.args.submittedAt)
+		responseID, err := respondentImpl.InsertRespondent(ctx, testCase.args.userID, questionnaireID, testCase.args.submittedAt)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -120,12 +123,14 @@ func TestInsertRespondent(t *testing.T) {
 func TestUpdateSubmittedAt(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -156,13 +161,13 @@ func TestUpdateSubmittedAt(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		responseID, err := respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), false))
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), false))
 		require.NoError(t, err)
 		if !testCase.args.validresponseID {
 			responseID = -1
 		}
 
-		err = respondentImpl.UpdateSubmittedAt(responseID)
+		err = respondentImpl.UpdateSubmittedAt(ctx, responseID)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -182,20 +187,264 @@ func TestUpdateSubmittedAt(t *testing.T) {
 		assertion.Equal(questionnaireID, respondent.QuestionnaireID, testCase.description, "questionnaireID")
 		assertion.Equal(testCase.args.userID, respondent.UserTraqid, testCase.description, "userID")
 		assertion.WithinDuration(testCase.args.submittedAt.ValueOrZero(), respondent.SubmittedAt.ValueOrZero(), 2*time.Second, testCase.description, "submittedAt")
+		assertion.Equal(respondent.SubmittedAt, respondent.FirstSubmittedAt, testCase.description, "first_submitted_at")
+		assertion.False(respondent.EditedAt.Valid, testCase.description, "edited_at")
 		assertion.WithinDuration(time.Now(), respondent.ModifiedAt, 2*time.Second, testCase.description, "modified_at")
 		assertion.WithinDuration(null.NewTime(time.Time{}, false).ValueOrZero(), respondent.DeletedAt.ValueOrZero(), 2*time.Second, testCase.description, "deleted_at")
+
+		// 送信済みの回答を送信し直しても最初に送信した日時は変わらない
+		firstSubmittedAt := respondent.FirstSubmittedAt
+		time.Sleep(time.Second)
+		err = respondentImpl.UpdateSubmittedAt(ctx, responseID)
+		assertion.NoError(err, testCase.description, "resubmit")
+
+		respondent = Respondents{}
+		err = db.Where("response_id = ?", responseID).First(&respondent).Error
+		assertion.NoError(err, testCase.description, "get respondent")
+		assertion.Equal(firstSubmittedAt, respondent.FirstSubmittedAt, testCase.description, "first_submitted_at after resubmit")
+		assertion.True(respondent.SubmittedAt.Time.After(firstSubmittedAt.Time), testCase.description, "submitted_at after resubmit")
 	}
 }
 
+func TestInsertRespondentResponseMode(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	onceQuestionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnce, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, onceQuestionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, onceQuestionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	existingID, err := respondentImpl.InsertRespondent(ctx, userTwo, onceQuestionnaireID, null.NewTime(time.Now(), true))
+	assertion.True(errors.Is(err, ErrResponseAlreadyExists), "once: second response")
+	assertion.Equal(responseID, existingID, "once: existing responseID")
+
+	_, err = respondentImpl.InsertRespondent(ctx, userThree, onceQuestionnaireID, null.NewTime(time.Now(), true))
+	assertion.NoError(err, "once: another user")
+
+	err = respondentImpl.DeleteRespondent(ctx, userTwo, responseID)
+	require.NoError(t, err)
+
+	_, err = respondentImpl.InsertRespondent(ctx, userTwo, onceQuestionnaireID, null.NewTime(time.Now(), true))
+	assertion.NoError(err, "once: after delete")
+
+	draftQuestionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnceWithDraft, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, draftQuestionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	submittedID, err := respondentImpl.InsertRespondent(ctx, userTwo, draftQuestionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	draftID, err := respondentImpl.InsertRespondent(ctx, userTwo, draftQuestionnaireID, null.NewTime(time.Time{}, false))
+	assertion.NoError(err, "once_with_draft: draft")
+
+	existingID, err = respondentImpl.InsertRespondent(ctx, userTwo, draftQuestionnaireID, null.NewTime(time.Now(), true))
+	assertion.True(errors.Is(err, ErrResponseAlreadyExists), "once_with_draft: second submitted response")
+	assertion.Equal(submittedID, existingID, "once_with_draft: existing responseID")
+
+	err = respondentImpl.UpdateSubmittedAt(ctx, draftID)
+	assertion.True(errors.Is(err, ErrResponseAlreadyExists), "once_with_draft: submit draft")
+
+	err = respondentImpl.DeleteRespondent(ctx, userTwo, submittedID)
+	require.NoError(t, err)
+
+	err = respondentImpl.UpdateSubmittedAt(ctx, draftID)
+	assertion.NoError(err, "once_with_draft: submit draft after delete")
+}
+
+func TestUpdateResponseMode(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	submittedID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	_, err = respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	err = questionnaireImpl.UpdateQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnce, false, questionnaireID)
+	assertion.True(errors.Is(err, ErrResponseModeConflict), "once: submitted response and draft")
+
+	err = questionnaireImpl.UpdateQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnceWithDraft, false, questionnaireID)
+	require.NoError(t, err, "once_with_draft: submitted response and draft")
+
+	existingID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	assertion.True(errors.Is(err, ErrResponseAlreadyExists), "once_with_draft: existing response")
+	assertion.Equal(submittedID, existingID, "once_with_draft: existing responseID")
+
+	err = questionnaireImpl.UpdateQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false, questionnaireID)
+	require.NoError(t, err, "multiple")
+
+	_, err = respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	assertion.NoError(err, "multiple: another response")
+}
+
+func TestInsertProxyRespondent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnce, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertProxyRespondent(ctx, userTwo, userOne, questionnaireID)
+	require.NoError(t, err)
+
+	respondentDetail, err := respondentImpl.GetRespondentDetail(ctx, responseID)
+	require.NoError(t, err)
+	assertion.Equal(null.StringFrom(userOne), respondentDetail.EnteredBy, "entered_by")
+	assertion.True(respondentDetail.SubmittedAt.Valid, "submitted_at")
+
+	respondentDetails, err := respondentImpl.GetRespondentDetails(ctx, questionnaireID, "")
+	require.NoError(t, err)
+	if assertion.Len(respondentDetails, 1) {
+		assertion.Equal(userTwo, respondentDetails[0].TraqID, "traqID")
+		assertion.Equal(null.StringFrom(userOne), respondentDetails[0].EnteredBy, "entered_by")
+	}
+
+	existingID, err := respondentImpl.InsertProxyRespondent(ctx, userTwo, userOne, questionnaireID)
+	assertion.True(errors.Is(err, ErrResponseAlreadyExists), "second proxy response")
+	assertion.Equal(responseID, existingID, "existing responseID")
+
+	selfResponseID, err := respondentImpl.InsertRespondent(ctx, userThree, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	respondentDetail, err = respondentImpl.GetRespondentDetail(ctx, selfResponseID)
+	require.NoError(t, err)
+	assertion.False(respondentDetail.EnteredBy.Valid, "entered_by of self response")
+}
+
+func TestGetSubmittedRespondentID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeOnceWithDraft, false)
+	require.NoError(t, err)
+
+	_, err = respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), false))
+	require.NoError(t, err)
+
+	existingID, err := respondentImpl.GetSubmittedRespondentID(ctx, userTwo, questionnaireID)
+	require.NoError(t, err)
+	assertion.False(existingID.Valid, "draft")
+
+	responseID, err := respondentImpl.InsertProxyRespondent(ctx, userTwo, userOne, questionnaireID)
+	require.NoError(t, err)
+
+	existingID, err = respondentImpl.GetSubmittedRespondentID(ctx, userTwo, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal(null.IntFrom(int64(responseID)), existingID, "submitted")
+
+	existingID, err = respondentImpl.GetSubmittedRespondentID(ctx, userThree, questionnaireID)
+	require.NoError(t, err)
+	assertion.False(existingID.Valid, "other user")
+
+	multipleQuestionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	_, err = respondentImpl.InsertProxyRespondent(ctx, userTwo, userOne, multipleQuestionnaireID)
+	require.NoError(t, err)
+
+	existingID, err = respondentImpl.GetSubmittedRespondentID(ctx, userTwo, multipleQuestionnaireID)
+	require.NoError(t, err)
+	assertion.False(existingID.Valid, "multiple")
+}
+
+func TestAnonymousRespondent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, true)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	respondent := Respondents{}
+	err = db.Where("response_id = ?", responseID).First(&respondent).Error
+	require.NoError(t, err)
+	assertion.NotEqual(userTwo, respondent.UserTraqid, "stored user_traqid")
+
+	isRespondent, err := respondentImpl.CheckRespondent(ctx, userTwo, questionnaireID)
+	assertion.NoError(err)
+	assertion.True(isRespondent, "CheckRespondent")
+
+	isRespondent, err = respondentImpl.CheckRespondentByResponseID(ctx, userTwo, responseID)
+	assertion.NoError(err)
+	assertion.True(isRespondent, "CheckRespondentByResponseID")
+
+	isRespondent, err = respondentImpl.CheckRespondentByResponseID(ctx, userThree, responseID)
+	assertion.NoError(err)
+	assertion.False(isRespondent, "CheckRespondentByResponseID other user")
+
+	respondentInfos, err := respondentImpl.GetRespondentInfos(ctx, userTwo, questionnaireID)
+	assertion.NoError(err)
+	assertion.Len(respondentInfos, 1, "GetRespondentInfos")
+
+	respondentDetails, err := respondentImpl.GetRespondentDetails(ctx, questionnaireID, "")
+	assertion.NoError(err)
+	if assertion.Len(respondentDetails, 1, "GetRespondentDetails") {
+		assertion.Equal("", respondentDetails[0].TraqID, "GetRespondentDetails traqID")
+	}
+
+	_, _, _, respondents, err := questionnaireImpl.GetQuestionnaireInfo(ctx, questionnaireID)
+	assertion.NoError(err)
+	assertion.Empty(respondents, "GetQuestionnaireInfo respondents")
+
+	respondentCount, err := questionnaireImpl.GetRespondentCount(ctx, questionnaireID)
+	assertion.NoError(err)
+	assertion.Equal(1, respondentCount, "GetRespondentCount")
+
+	err = questionnaireImpl.UpdateQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false, questionnaireID)
+	assertion.True(errors.Is(err, ErrAnonymityLocked), "UpdateQuestionnaire anonymity")
+
+	err = respondentImpl.DeleteRespondent(ctx, userTwo, responseID)
+	assertion.NoError(err, "DeleteRespondent")
+}
+
 func TestDeleteRespondent(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -258,13 +507,13 @@ func TestDeleteRespondent(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		responseID, err := respondentImpl.InsertRespondent(testCase.args.insertUserID, questionnaireID, null.NewTime(time.Now(), true))
+		responseID, err := respondentImpl.InsertRespondent(ctx, testCase.args.insertUserID, questionnaireID, null.NewTime(time.Now(), true))
 		require.NoError(t, err)
 		if !testCase.args.validresponseID {
 			responseID = -1
 		}
 
-		err = respondentImpl.DeleteRespondent(testCase.args.deleteUserID, responseID)
+		err = respondentImpl.DeleteRespondent(ctx, testCase.args.deleteUserID, responseID)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -280,6 +529,8 @@ func TestDeleteRespondent(t *testing.T) {
 
 func TestGetRespondentInfos(t *testing.T) {
 	t.Parallel()
+
+	ctx := context.Background()
 	assertion := assert.New(t)
 
 	type args struct {
@@ -296,9 +547,9 @@ func TestGetRespondentInfos(t *testing.T) {
 		args
 		expect
 	}
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第2回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第2回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
-	questionnaireID2, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第2回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID2, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第2回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
 	questionnaire := Questionnaires{}
@@ -333,7 +584,7 @@ func TestGetRespondentInfos(t *testing.T) {
 
 	respondentMap := make(map[int]Respondents)
 	for _, respondent := range respondents {
-		responseID, err := respondentImpl.InsertRespondent(respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
+		responseID, err := respondentImpl.InsertRespondent(ctx, respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
 		require.NoError(t, err)
 		respondent.ResponseID = responseID
 		respondentMap[responseID] = respondent
@@ -385,7 +636,7 @@ func TestGetRespondentInfos(t *testing.T) {
 
 	for _, testCase := range testCases {
 
-		respondentInfos, err := respondentImpl.GetRespondentInfos(testCase.args.userID, testCase.args.questionnaireIDs...)
+		respondentInfos, err := respondentImpl.GetRespondentInfos(ctx, testCase.args.userID, testCase.args.questionnaireIDs...)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -423,9 +674,11 @@ func TestGetRespondentInfos(t *testing.T) {
 func TestGetRespondentDetail(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
 	questionnaire := Questionnaires{}
@@ -435,7 +688,7 @@ func TestGetRespondentDetail(t *testing.T) {
 		Find(&questionnaire).Error
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -454,14 +707,17 @@ func TestGetRespondentDetail(t *testing.T) {
 
 	questionIDs := make([]int, 0, 2)
 
-	questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "Text", "質問文", true)
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
 	require.NoError(t, err)
 	questionIDs = append(questionIDs, questionID)
 
-	questionID, err = questionImpl.InsertQuestion(questionnaireID, 1, 3, "MultipleChoice", "radio", true)
+	questionID, err = questionImpl.InsertQuestion(ctx, questionnaireID, 1, 3, "MultipleChoice", "radio", true)
 	require.NoError(t, err)
 	questionIDs = append(questionIDs, questionID)
 
+	deletedQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 4, "Text", "削除される質問", true)
+	require.NoError(t, err)
+
 	testCases := []test{
 		{
 			description: "valid",
@@ -470,22 +726,26 @@ func TestGetRespondentDetail(t *testing.T) {
 				responseMetas: []*ResponseMeta{
 					{QuestionID: questionIDs[0], Data: "リマインダーBOTを作った話"},
 					{QuestionID: questionIDs[1], Data: "選択肢1"},
+					{QuestionID: deletedQuestionID, Data: "削除される回答"},
 				},
 			},
 		},
 	}
 
 	for _, testCase := range testCases {
-		responseID, err := respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), false))
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), false))
 		require.NoError(t, err)
 		if !testCase.args.validresponseID {
 			responseID = -1
 		} else {
-			err := responseImpl.InsertResponses(responseID, testCase.args.responseMetas)
+			err := responseImpl.InsertResponses(ctx, responseID, testCase.args.responseMetas)
 			require.NoError(t, err)
 		}
 
-		respondentDetail, err := respondentImpl.GetRespondentDetail(responseID)
+		err = questionImpl.DeleteQuestion(ctx, deletedQuestionID)
+		require.NoError(t, err)
+
+		respondentDetail, err := respondentImpl.GetRespondentDetail(ctx, responseID)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -498,6 +758,7 @@ func TestGetRespondentDetail(t *testing.T) {
 		}
 
 		assertion.Equal(questionnaireID, respondentDetail.QuestionnaireID, testCase.description, "questionnaireID")
+		assertion.Len(respondentDetail.Responses, len(questionIDs), testCase.description, "deleted question")
 
 		questionID := questionIDs[0]
 		responseBody := respondentDetail.Responses[0]
@@ -518,9 +779,11 @@ func TestGetRespondentDetail(t *testing.T) {
 func TestGetRespondentDetails(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
 	questionnaire := Questionnaires{}
@@ -530,7 +793,7 @@ func TestGetRespondentDetails(t *testing.T) {
 		Find(&questionnaire).Error
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -579,7 +842,7 @@ func TestGetRespondentDetails(t *testing.T) {
 	questionIDs := make([]int, 0, questionLength)
 
 	for _, question := range questions {
-		questionID, err := questionImpl.InsertQuestion(question.QuestionnaireID, question.PageNum, question.QuestionNum, question.Type, question.Body, question.IsRequired)
+		questionID, err := questionImpl.InsertQuestion(ctx, question.QuestionnaireID, question.PageNum, question.QuestionNum, question.Type, question.Body, question.IsRequired)
 		require.NoError(t, err)
 		questionIDs = append(questionIDs, questionID)
 
@@ -624,11 +887,11 @@ func TestGetRespondentDetails(t *testing.T) {
 	responseLength := len(respondents)
 	responseIDs := make([]int, 0, responseLength)
 	for i, respondent := range respondents {
-		responseID, err := respondentImpl.InsertRespondent(respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
+		responseID, err := respondentImpl.InsertRespondent(ctx, respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
 		require.NoError(t, err)
 		responseIDs = append(responseIDs, responseID)
 
-		err = responseImpl.InsertResponses(responseIDs[i], responseMetasList[i])
+		err = responseImpl.InsertResponses(ctx, responseIDs[i], responseMetasList[i])
 		require.NoError(t, err)
 
 	}
@@ -757,7 +1020,7 @@ func TestGetRespondentDetails(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		respondentDetails, err := respondentImpl.GetRespondentDetails(testCase.args.questionnaireID, testCase.args.sort)
+		respondentDetails, err := respondentImpl.GetRespondentDetails(ctx, testCase.args.questionnaireID, testCase.args.sort)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -777,8 +1040,150 @@ func TestGetRespondentDetails(t *testing.T) {
 	}
 }
 
+func TestGetRespondentDetailsPage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Number", "number", true)
+	require.NoError(t, err)
+
+	// 同じ値の回答を含めてresponse_idで並ぶことを確認する
+	numbers := []string{"10", "5", "10", "", "-3"}
+	for _, number := range numbers {
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+		require.NoError(t, err)
+
+		err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+			{QuestionID: questionID, Data: number},
+		})
+		require.NoError(t, err)
+	}
+
+	for _, sort := range []string{"", "traqid", "-submitted_at", "1", "-1"} {
+		expected, err := respondentImpl.GetRespondentDetails(ctx, questionnaireID, sort)
+		require.NoError(t, err)
+		assertion.Len(expected, len(numbers), sort)
+
+		actual := []RespondentDetail{}
+		after := ""
+		for i := 0; i < len(numbers); i++ {
+			respondentDetails, nextCursor, err := respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, sort, "", 2, after)
+			require.NoError(t, err)
+			assertion.LessOrEqual(len(respondentDetails), 2, sort)
+
+			actual = append(actual, respondentDetails...)
+			if nextCursor == "" {
+				break
+			}
+			after = nextCursor
+		}
+
+		expectedIDs := make([]int, 0, len(expected))
+		for _, respondentDetail := range expected {
+			expectedIDs = append(expectedIDs, respondentDetail.ResponseID)
+		}
+		actualIDs := make([]int, 0, len(actual))
+		for _, respondentDetail := range actual {
+			actualIDs = append(actualIDs, respondentDetail.ResponseID)
+		}
+		assertion.Equal(expectedIDs, actualIDs, sort)
+	}
+
+	_, nextCursor, err := respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, "1", "", 2, "")
+	require.NoError(t, err)
+
+	_, _, err = respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, "-1", "", 2, nextCursor)
+	assertion.True(errors.Is(err, ErrInvalidCursor), "cursor for another sort")
+
+	_, _, err = respondentImpl.GetRespondentDetailsPage(ctx, questionnaireID, "1", "", 2, "invalid cursor")
+	assertion.True(errors.Is(err, ErrInvalidCursor), "invalid cursor")
+}
+
+func TestGetRespondentProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
+	require.NoError(t, err)
+
+	draftID, err := respondentImpl.InsertRespondent(ctx, userOne, questionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, draftID, []*ResponseMeta{{QuestionID: questionID, Data: "下書き"}})
+	require.NoError(t, err)
+
+	submittedID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, submittedID, []*ResponseMeta{{QuestionID: questionID, Data: "回答"}})
+	require.NoError(t, err)
+
+	editedID, err := respondentImpl.InsertRespondent(ctx, userThree, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, editedID, []*ResponseMeta{{QuestionID: questionID, Data: "回答"}})
+	require.NoError(t, err)
+	err = respondentImpl.UpdateEditedAt(ctx, editedID)
+	require.NoError(t, err)
+	err = responseImpl.DeleteResponse(ctx, editedID)
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, editedID, []*ResponseMeta{{QuestionID: questionID, Data: "編集後の回答"}})
+	require.NoError(t, err)
+
+	// 下書きの回答を置き換えて初めて送信しても編集として数えない
+	submittedDraftID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, submittedDraftID, []*ResponseMeta{{QuestionID: questionID, Data: "下書き"}})
+	require.NoError(t, err)
+	err = respondentImpl.UpdateEditedAt(ctx, submittedDraftID)
+	require.NoError(t, err)
+	err = respondentImpl.UpdateSubmittedAt(ctx, submittedDraftID)
+	require.NoError(t, err)
+	err = responseImpl.DeleteResponse(ctx, submittedDraftID)
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, submittedDraftID, []*ResponseMeta{{QuestionID: questionID, Data: "回答"}})
+	require.NoError(t, err)
+
+	progresses, err := respondentImpl.GetRespondentProgress(ctx, questionnaireID)
+	require.NoError(t, err)
+
+	progressMap := map[string]RespondentProgress{}
+	for _, progress := range progresses {
+		progressMap[progress.UserTraqid] = progress
+	}
+	assertion.Len(progressMap, 3)
+
+	assertion.Equal(1, progressMap[userOne].DraftCount, "draft")
+	assertion.Equal(0, progressMap[userOne].SubmittedCount, "draft")
+	assertion.False(progressMap[userOne].SubmittedAt.Valid, "draft")
+
+	assertion.Equal(2, progressMap[userTwo].SubmittedCount, "submitted")
+	assertion.Equal(0, progressMap[userTwo].EditedCount, "submitted")
+	assertion.True(progressMap[userTwo].SubmittedAt.Valid, "submitted")
+
+	assertion.Equal(1, progressMap[userThree].SubmittedCount, "edited")
+	assertion.Equal(1, progressMap[userThree].EditedCount, "edited")
+}
+
 func TestGetRespondentsUserIDs(t *testing.T) {
 	t.Parallel()
+
+	ctx := context.Background()
 	assertion := assert.New(t)
 
 	type args struct {
@@ -797,7 +1202,7 @@ func TestGetRespondentsUserIDs(t *testing.T) {
 	}
 	questionnaireIDs := make([]int, 0, 3)
 	for i := 0; i < 3; i++ {
-		questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+		questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 		require.NoError(t, err)
 		questionnaireIDs = append(questionnaireIDs, questionnaireID)
 	}
@@ -817,7 +1222,7 @@ func TestGetRespondentsUserIDs(t *testing.T) {
 
 	respondentMap := make(map[int]Respondents)
 	for _, respondent := range respondents {
-		responseID, err := respondentImpl.InsertRespondent(respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
+		responseID, err := respondentImpl.InsertRespondent(ctx, respondent.UserTraqid, respondent.QuestionnaireID, respondent.SubmittedAt)
 		require.NoError(t, err)
 		respondent.ResponseID = responseID
 		respondentMap[responseID] = respondent
@@ -856,7 +1261,7 @@ func TestGetRespondentsUserIDs(t *testing.T) {
 
 	for _, testCase := range testCases {
 
-		respondents, err := respondentImpl.GetRespondentsUserIDs(testCase.args.questionnaireIDs)
+		respondents, err := respondentImpl.GetRespondentsUserIDs(ctx, testCase.args.questionnaireIDs)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -882,15 +1287,17 @@ func TestGetRespondentsUserIDs(t *testing.T) {
 func TestTestCheckRespondent(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
-	_, err = respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	_, err = respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
 	require.NoError(t, err)
 
 	type args struct {
@@ -943,7 +1350,7 @@ func TestTestCheckRespondent(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		isRespondent, err := respondentImpl.CheckRespondent(testCase.args.userID, testCase.args.questionnaireID)
+		isRespondent, err := respondentImpl.CheckRespondent(ctx, testCase.args.userID, testCase.args.questionnaireID)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -962,15 +1369,17 @@ func TestTestCheckRespondent(t *testing.T) {
 func TestCheckRespondentByResponseID(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
-	responseID, err := respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
 	require.NoError(t, err)
 
 	type args struct {
@@ -1023,7 +1432,7 @@ func TestCheckRespondentByResponseID(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		isRespondent, err := respondentImpl.CheckRespondentByResponseID(testCase.args.userID, testCase.args.responseID)
+		isRespondent, err := respondentImpl.CheckRespondentByResponseID(ctx, testCase.args.userID, testCase.args.responseID)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
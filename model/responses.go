@@ -2,8 +2,15 @@
 
 package model
 
+import (
+	"context"
+)
+
 // IResponse ResponseのRepository
 type IResponse interface {
-	InsertResponses(responseID int, responseMetas []*ResponseMeta) error
-	DeleteResponse(responseID int) error
+	InsertResponses(ctx context.Context, responseID int, responseMetas []*ResponseMeta) error
+	DeleteResponse(ctx context.Context, responseID int) error
+	MergeResponses(ctx context.Context, responseID int, questionIDs []int, responseMetas []*ResponseMeta) ([]int, error)
+	GetResponseHistory(ctx context.Context, responseID int) ([]ResponseHistory, error)
+	GetCrossTabulation(ctx context.Context, questionnaireID int, rowQuestionID int, colQuestionID int, filter string) ([]CrossTabulationCell, error)
 }
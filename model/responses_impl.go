@@ -1,9 +1,12 @@
 package model
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	gormbulk "github.com/t-tiger/gorm-bulk-insert/v2"
 	"gopkg.in/guregu/null.v3"
 )
@@ -36,6 +39,7 @@ type ResponseBody struct {
 	QuestionType   string      `json:"question_type" gorm:"column:type"`
 	Body           null.String `json:"response"`
 	OptionResponse []string    `json:"option_response"`
+	ModifiedAt     null.Time   `json:"modified_at" gorm:"column:response_modified_at"`
 }
 
 // ResponseMeta 質問に対する回答の構造体
@@ -44,8 +48,28 @@ type ResponseMeta struct {
 	Data       string
 }
 
+// ResponseHistory 回答の編集1回分の履歴
+type ResponseHistory struct {
+	EditedAt time.Time        `json:"edited_at"`
+	Changes  []ResponseChange `json:"changes"`
+}
+
+// ResponseChange 質問ごとの回答の変更前後の値
+type ResponseChange struct {
+	QuestionID int      `json:"questionID"`
+	Before     []string `json:"before"`
+	After      []string `json:"after"`
+}
+
+// CrossTabulationCell 2つの質問の回答の組み合わせごとの回答数
+type CrossTabulationCell struct {
+	RowValue string
+	ColValue string
+	Count    int
+}
+
 // InsertResponses 質問に対する回答の追加
-func (*Response) InsertResponses(responseID int, responseMetas []*ResponseMeta) error {
+func (*Response) InsertResponses(ctx context.Context, responseID int, responseMetas []*ResponseMeta) error {
 	responses := make([]interface{}, 0, len(responseMetas))
 	for _, responseMeta := range responseMetas {
 		responses = append(responses, Responses{
@@ -54,7 +78,7 @@ func (*Response) InsertResponses(responseID int, responseMetas []*ResponseMeta)
 			Body:       null.NewString(responseMeta.Data, true),
 		})
 	}
-	err := gormbulk.BulkInsert(db, responses, len(responses), "ModifiedAt", "DeletedAt")
+	err := gormbulk.BulkInsert(getTx(ctx), responses, len(responses), "ModifiedAt", "DeletedAt")
 	if err != nil {
 		return fmt.Errorf("failed to insert response: %w", err)
 	}
@@ -63,8 +87,8 @@ func (*Response) InsertResponses(responseID int, responseMetas []*ResponseMeta)
 }
 
 // DeleteResponse 質問に対する回答の削除
-func (*Response) DeleteResponse(responseID int) error {
-	result := db.
+func (*Response) DeleteResponse(ctx context.Context, responseID int) error {
+	result := getTx(ctx).
 		Where("response_id = ?", responseID).
 		Delete(&Responses{})
 	err := result.Error
@@ -77,3 +101,218 @@ func (*Response) DeleteResponse(responseID int) error {
 
 	return nil
 }
+
+// MergeResponses 指定した質問の回答のうち変更があったものだけを置き換える
+// 置き換えた質問のIDを返す
+func (*Response) MergeResponses(ctx context.Context, responseID int, questionIDs []int, responseMetas []*ResponseMeta) ([]int, error) {
+	changedQuestionIDs := []int{}
+	if len(questionIDs) == 0 {
+		return changedQuestionIDs, nil
+	}
+
+	newBodies := make(map[int][]string, len(questionIDs))
+	for _, responseMeta := range responseMetas {
+		newBodies[responseMeta.QuestionID] = append(newBodies[responseMeta.QuestionID], responseMeta.Data)
+	}
+
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		currentResponses := []Responses{}
+		err := tx.
+			Where("response_id = ? AND question_id IN (?) AND deleted_at IS NULL", responseID, questionIDs).
+			Find(&currentResponses).Error
+		if err != nil {
+			return fmt.Errorf("failed to get responses: %w", err)
+		}
+
+		currentBodies := make(map[int][]string, len(questionIDs))
+		for _, response := range currentResponses {
+			currentBodies[response.QuestionID] = append(currentBodies[response.QuestionID], response.Body.String)
+		}
+
+		for _, questionID := range questionIDs {
+			if !isSameResponseBodies(currentBodies[questionID], newBodies[questionID]) {
+				changedQuestionIDs = append(changedQuestionIDs, questionID)
+			}
+		}
+		if len(changedQuestionIDs) == 0 {
+			return nil
+		}
+
+		// 変更前の回答は履歴として論理削除で残す
+		err = tx.
+			Where("response_id = ? AND question_id IN (?)", responseID, changedQuestionIDs).
+			Delete(&Responses{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete responses: %w", err)
+		}
+
+		responses := []interface{}{}
+		for _, questionID := range changedQuestionIDs {
+			for _, body := range newBodies[questionID] {
+				responses = append(responses, Responses{
+					ResponseID: responseID,
+					QuestionID: questionID,
+					Body:       null.NewString(body, true),
+				})
+			}
+		}
+		if len(responses) != 0 {
+			err = gormbulk.BulkInsert(tx, responses, len(responses), "ModifiedAt", "DeletedAt")
+			if err != nil {
+				return fmt.Errorf("failed to insert responses: %w", err)
+			}
+		}
+
+		err = tx.
+			Model(&Respondents{}).
+			Where("response_id = ?", responseID).
+			Update("modified_at", time.Now()).Error
+		if err != nil {
+			return fmt.Errorf("failed to update respondent's modified_at: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed in transaction: %w", err)
+	}
+
+	return changedQuestionIDs, nil
+}
+
+// isSameResponseBodies 選択肢の順番を無視して回答が同じかどうか
+func isSameResponseBodies(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetResponseHistory 論理削除された回答から編集履歴を取得
+func (*Response) GetResponseHistory(ctx context.Context, responseID int) ([]ResponseHistory, error) {
+	responses := []Responses{}
+	err := getTx(ctx).
+		Unscoped().
+		Where("response_id = ?", responseID).
+		Order("question_id, modified_at, deleted_at IS NULL, deleted_at").
+		Find(&responses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get responses: %w", err)
+	}
+
+	// 同時に追加・削除された行を質問ごとの回答の版にまとめる
+	type responseVersion struct {
+		modifiedAt time.Time
+		deletedAt  null.Time
+		bodies     []string
+	}
+	questionIDs := []int{}
+	versionMap := map[int][]*responseVersion{}
+	for _, response := range responses {
+		versions, ok := versionMap[response.QuestionID]
+		if !ok {
+			questionIDs = append(questionIDs, response.QuestionID)
+		}
+
+		if len(versions) != 0 {
+			last := versions[len(versions)-1]
+			if last.modifiedAt.Equal(response.ModifiedAt) && last.deletedAt.Equal(response.DeletedAt) {
+				last.bodies = append(last.bodies, response.Body.String)
+				continue
+			}
+		}
+
+		versionMap[response.QuestionID] = append(versions, &responseVersion{
+			modifiedAt: response.ModifiedAt,
+			deletedAt:  response.DeletedAt,
+			bodies:     []string{response.Body.String},
+		})
+	}
+
+	historyMap := map[int64]*ResponseHistory{}
+	appendChange := func(editedAt time.Time, change ResponseChange) {
+		history, ok := historyMap[editedAt.Unix()]
+		if !ok {
+			history = &ResponseHistory{
+				EditedAt: editedAt,
+				Changes:  []ResponseChange{},
+			}
+			historyMap[editedAt.Unix()] = history
+		}
+		history.Changes = append(history.Changes, change)
+	}
+
+	for _, questionID := range questionIDs {
+		before := []string{}
+		for i, version := range versionMap[questionID] {
+			// 全消し&追加で同じ回答が入り直しただけのものは変更に含めない
+			if i == 0 || !isSameResponseBodies(before, version.bodies) {
+				appendChange(version.modifiedAt, ResponseChange{
+					QuestionID: questionID,
+					Before:     before,
+					After:      version.bodies,
+				})
+			}
+			before = version.bodies
+
+			// 後の版が無いまま削除されたものは回答が消されたものとする
+			isLast := i == len(versionMap[questionID])-1
+			if isLast && version.deletedAt.Valid {
+				appendChange(version.deletedAt.Time, ResponseChange{
+					QuestionID: questionID,
+					Before:     before,
+					After:      []string{},
+				})
+			}
+		}
+	}
+
+	histories := make([]ResponseHistory, 0, len(historyMap))
+	for _, history := range historyMap {
+		histories = append(histories, *history)
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].EditedAt.Before(histories[j].EditedAt)
+	})
+
+	return histories, nil
+}
+
+// GetCrossTabulation 送信済みの回答について2つの質問の回答の組み合わせごとの回答数を取得
+// Checkboxのように複数の回答がある質問では全ての組み合わせを数える
+func (*Response) GetCrossTabulation(ctx context.Context, questionnaireID int, rowQuestionID int, colQuestionID int, filter string) ([]CrossTabulationCell, error) {
+	query := getTx(ctx).
+		Table("respondents").
+		Joins("INNER JOIN response AS row_response ON respondents.response_id = row_response.response_id AND row_response.question_id = ? AND row_response.deleted_at IS NULL AND row_response.body <> ''", rowQuestionID).
+		Joins("INNER JOIN response AS col_response ON respondents.response_id = col_response.response_id AND col_response.question_id = ? AND col_response.deleted_at IS NULL AND col_response.body <> ''", colQuestionID).
+		Where("respondents.questionnaire_id = ? AND respondents.deleted_at IS NULL AND respondents.submitted_at IS NOT NULL", questionnaireID)
+	if filter != "" {
+		var err error
+		query, err = setRespondentsFilter(ctx, query, questionnaireID, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set filter: %w", err)
+		}
+	}
+
+	cells := []CrossTabulationCell{}
+	err := query.
+		Select("row_response.body AS row_value, col_response.body AS col_value, COUNT(*) AS count").
+		Group("row_response.body, col_response.body").
+		Scan(&cells).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cross tabulation: %w", err)
+	}
+
+	return cells, nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -15,15 +16,17 @@ import (
 func TestInsertResponses(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
-	questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "Text", "質問文", true)
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
 	require.NoError(t, err)
 
 	type args struct {
@@ -99,12 +102,12 @@ func TestInsertResponses(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		responseID, err := respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), true))
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
 		require.NoError(t, err)
 		if !testCase.args.validID {
 			responseID = -1
 		}
-		err = responseImpl.InsertResponses(responseID, testCase.args.responseMetas)
+		err = responseImpl.InsertResponses(ctx, responseID, testCase.args.responseMetas)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -134,15 +137,17 @@ func TestInsertResponses(t *testing.T) {
 func TestDeleteResponse(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
-	questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "Text", "質問文", true)
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
 	require.NoError(t, err)
 
 	type args struct {
@@ -186,15 +191,15 @@ func TestDeleteResponse(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		responseID, err := respondentImpl.InsertRespondent(userTwo, questionnaireID, null.NewTime(time.Now(), true))
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
 		require.NoError(t, err)
-		err = responseImpl.InsertResponses(responseID, testCase.args.responseMetas)
+		err = responseImpl.InsertResponses(ctx, responseID, testCase.args.responseMetas)
 		require.NoError(t, err)
 		if !testCase.args.validID {
 			responseID = -1
 		}
 
-		err = responseImpl.DeleteResponse(responseID)
+		err = responseImpl.DeleteResponse(ctx, responseID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -220,3 +225,185 @@ func TestDeleteResponse(t *testing.T) {
 		assertion.WithinDuration(time.Now(), response.DeletedAt.ValueOrZero(), 2*time.Second)
 	}
 }
+
+func TestMergeResponses(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	textQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
+	require.NoError(t, err)
+
+	checkboxQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 2, "Checkbox", "質問文", true)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+		{QuestionID: textQuestionID, Data: "リマインダーBOTを作った話"},
+		{QuestionID: checkboxQuestionID, Data: "選択肢1"},
+		{QuestionID: checkboxQuestionID, Data: "選択肢2"},
+	})
+	require.NoError(t, err)
+
+	changedQuestionIDs, err := responseImpl.MergeResponses(ctx, responseID, []int{textQuestionID, checkboxQuestionID}, []*ResponseMeta{
+		{QuestionID: textQuestionID, Data: "リマインダーBOTを作った話"},
+		{QuestionID: checkboxQuestionID, Data: "選択肢2"},
+		{QuestionID: checkboxQuestionID, Data: "選択肢1"},
+	})
+	assertion.NoError(err, "not changed")
+	assertion.Empty(changedQuestionIDs, "not changed")
+
+	changedQuestionIDs, err = responseImpl.MergeResponses(ctx, responseID, []int{checkboxQuestionID}, []*ResponseMeta{
+		{QuestionID: checkboxQuestionID, Data: "選択肢3"},
+	})
+	assertion.NoError(err, "changed")
+	assertion.Equal([]int{checkboxQuestionID}, changedQuestionIDs, "changed")
+
+	responses := []Responses{}
+	err = db.
+		Where("response_id = ? AND deleted_at IS NULL", responseID).
+		Order("question_id").
+		Find(&responses).Error
+	require.NoError(t, err)
+	if assertion.Len(responses, 2, "responses") {
+		assertion.Equal("リマインダーBOTを作った話", responses[0].Body.String, "text response")
+		assertion.Equal("選択肢3", responses[1].Body.String, "checkbox response")
+	}
+
+	changedQuestionIDs, err = responseImpl.MergeResponses(ctx, responseID, []int{textQuestionID}, []*ResponseMeta{})
+	assertion.NoError(err, "cleared")
+	assertion.Equal([]int{textQuestionID}, changedQuestionIDs, "cleared")
+}
+
+func TestGetResponseHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	textQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "質問文", true)
+	require.NoError(t, err)
+
+	numberQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 2, "Number", "質問文", true)
+	require.NoError(t, err)
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+		{QuestionID: textQuestionID, Data: "リマインダーBOTを作った話"},
+		{QuestionID: numberQuestionID, Data: "10"},
+	})
+	require.NoError(t, err)
+
+	// 同じ秒の編集と区別するために待つ
+	time.Sleep(time.Second)
+
+	err = responseImpl.DeleteResponse(ctx, responseID)
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, responseID, []*ResponseMeta{
+		{QuestionID: textQuestionID, Data: "リマインダーBOTを作った話"},
+		{QuestionID: numberQuestionID, Data: "20"},
+	})
+	require.NoError(t, err)
+
+	histories, err := responseImpl.GetResponseHistory(ctx, responseID)
+	require.NoError(t, err)
+
+	if !assertion.Len(histories, 2, "histories") {
+		return
+	}
+	assertion.Len(histories[0].Changes, 2, "first answer")
+	if assertion.Len(histories[1].Changes, 1, "edit") {
+		assertion.Equal(numberQuestionID, histories[1].Changes[0].QuestionID, "edit questionID")
+		assertion.Equal([]string{"10"}, histories[1].Changes[0].Before, "edit before")
+		assertion.Equal([]string{"20"}, histories[1].Changes[0].After, "edit after")
+	}
+}
+
+func TestGetCrossTabulation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	rowQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Checkbox", "第一希望", true)
+	require.NoError(t, err)
+	colQuestionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 2, "MultipleChoice", "新規/継続", true)
+	require.NoError(t, err)
+
+	responseMetasList := [][]*ResponseMeta{
+		{
+			{QuestionID: rowQuestionID, Data: "CTF班"},
+			{QuestionID: rowQuestionID, Data: "SysAd班"},
+			{QuestionID: colQuestionID, Data: "新規"},
+		},
+		{
+			{QuestionID: rowQuestionID, Data: "CTF班"},
+			{QuestionID: colQuestionID, Data: "新規"},
+		},
+		{
+			{QuestionID: rowQuestionID, Data: "SysAd班"},
+			{QuestionID: colQuestionID, Data: "継続"},
+		},
+		{
+			{QuestionID: rowQuestionID, Data: "CTF班"},
+		},
+	}
+	for _, responseMetas := range responseMetasList {
+		responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+		require.NoError(t, err)
+
+		err = responseImpl.InsertResponses(ctx, responseID, responseMetas)
+		require.NoError(t, err)
+	}
+
+	// 未送信の回答は数えない
+	draftID, err := respondentImpl.InsertRespondent(ctx, userThree, questionnaireID, null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	err = responseImpl.InsertResponses(ctx, draftID, []*ResponseMeta{
+		{QuestionID: rowQuestionID, Data: "CTF班"},
+		{QuestionID: colQuestionID, Data: "継続"},
+	})
+	require.NoError(t, err)
+
+	cells, err := responseImpl.GetCrossTabulation(ctx, questionnaireID, rowQuestionID, colQuestionID, "")
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for _, cell := range cells {
+		counts[cell.RowValue+"/"+cell.ColValue] = cell.Count
+	}
+	assertion.Equal(map[string]int{
+		"CTF班/新規":   2,
+		"SysAd班/新規": 1,
+		"SysAd班/継続": 1,
+	}, counts)
+
+	cells, err = responseImpl.GetCrossTabulation(ctx, questionnaireID, rowQuestionID, colQuestionID, `q2 contains "継続"`)
+	require.NoError(t, err)
+	if assertion.Len(cells, 1, "filter") {
+		assertion.Equal(CrossTabulationCell{RowValue: "SysAd班", ColValue: "継続", Count: 1}, cells[0], "filter")
+	}
+
+	_, err = responseImpl.GetCrossTabulation(ctx, questionnaireID, rowQuestionID, colQuestionID, `q2 contains`)
+	assertion.True(errors.Is(err, ErrInvalidFilter), "invalid filter")
+}
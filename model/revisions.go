@@ -0,0 +1,14 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+)
+
+// IRevision RevisionのRepository
+type IRevision interface {
+	InsertRevision(ctx context.Context, questionnaireID int, userID string) (int, error)
+	GetRevisions(ctx context.Context, questionnaireID int) ([]Revisions, error)
+	GetRevision(ctx context.Context, questionnaireID int, revision int) (*Revisions, *QuestionnaireSnapshot, error)
+}
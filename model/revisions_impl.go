@@ -0,0 +1,296 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"gopkg.in/guregu/null.v3"
+)
+
+// Revision RevisionRepositoryの実装
+type Revision struct{}
+
+// NewRevision Revisionのコンストラクター
+func NewRevision() *Revision {
+	return new(Revision)
+}
+
+// Revisions questionnaire_revisionsテーブルの構造体
+// 一度作成したレコードは更新・削除しない
+type Revisions struct {
+	ID              int       `json:"revisionID"      gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	QuestionnaireID int       `json:"questionnaireID" gorm:"type:int(11) NOT NULL;"`
+	Revision        int       `json:"revision"        gorm:"type:int(11) NOT NULL;"`
+	UserTraqid      string    `json:"user_traq_id"    gorm:"type:char(30) NOT NULL;"`
+	Snapshot        string    `json:"-"               gorm:"type:mediumtext NOT NULL;"`
+	CreatedAt       time.Time `json:"created_at"      gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+}
+
+// TableName テーブル名をquestionnaire_revisionsにする
+func (*Revisions) TableName() string {
+	return "questionnaire_revisions"
+}
+
+// QuestionnaireSnapshot ある時点でのアンケートの内容
+type QuestionnaireSnapshot struct {
+	Title          string             `json:"title"`
+	Description    string             `json:"description"`
+	ResTimeLimit   null.Time          `json:"res_time_limit"`
+	ResSharedTo    string             `json:"res_shared_to"`
+	ResponseMode   string             `json:"response_mode"`
+	IsAnonymous    bool               `json:"is_anonymous"`
+	Targets        []string           `json:"targets"`
+	Administrators []string           `json:"administrators"`
+	Questions      []QuestionSnapshot `json:"questions"`
+}
+
+// QuestionSnapshot ある時点での質問の内容
+type QuestionSnapshot struct {
+	QuestionID      int      `json:"questionID"`
+	PageNum         int      `json:"page_num"`
+	QuestionNum     int      `json:"question_num"`
+	QuestionType    string   `json:"question_type"`
+	Body            string   `json:"body"`
+	IsRequired      bool     `json:"is_required"`
+	Options         []string `json:"options"`
+	ScaleLabelRight string   `json:"scale_label_right"`
+	ScaleLabelLeft  string   `json:"scale_label_left"`
+	ScaleMin        int      `json:"scale_min"`
+	ScaleMax        int      `json:"scale_max"`
+	RegexPattern    string   `json:"regex_pattern"`
+	MinBound        string   `json:"min_bound"`
+	MaxBound        string   `json:"max_bound"`
+}
+
+// InsertRevision アンケートの現在の内容をリビジョンとして記録
+func (*Revision) InsertRevision(ctx context.Context, questionnaireID int, userID string) (int, error) {
+	revision := Revisions{
+		QuestionnaireID: questionnaireID,
+		UserTraqid:      userID,
+	}
+
+	err := runInTx(ctx, func(tx *gorm.DB) error {
+		questionnaire := Questionnaires{}
+		// 同じアンケートのリビジョン番号の採番を直列化するためにロックをとる
+		err := tx.
+			Set("gorm:query_option", "FOR UPDATE").
+			Where("id = ?", questionnaireID).
+			First(&questionnaire).Error
+		if err != nil {
+			return fmt.Errorf("failed to get a questionnaire: %w", err)
+		}
+
+		snapshot, err := getQuestionnaireSnapshot(tx, &questionnaire)
+		if err != nil {
+			return fmt.Errorf("failed to get a snapshot: %w", err)
+		}
+
+		snapshotJSON, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encode a snapshot: %w", err)
+		}
+		revision.Snapshot = string(snapshotJSON)
+
+		lastRevision := struct {
+			Revision null.Int
+		}{}
+		err = tx.
+			Model(&Revisions{}).
+			Where("questionnaire_id = ?", questionnaireID).
+			Select("MAX(revision) AS revision").
+			Scan(&lastRevision).Error
+		if err != nil {
+			return fmt.Errorf("failed to get the last revision: %w", err)
+		}
+		revision.Revision = int(lastRevision.Revision.ValueOrZero()) + 1
+
+		err = tx.Create(&revision).Error
+		if err != nil {
+			return fmt.Errorf("failed to insert a revision: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed in the transaction: %w", err)
+	}
+
+	return revision.Revision, nil
+}
+
+// GetRevisions アンケートのリビジョン一覧の取得
+func (*Revision) GetRevisions(ctx context.Context, questionnaireID int) ([]Revisions, error) {
+	revisions := []Revisions{}
+
+	err := getTx(ctx).
+		Where("questionnaire_id = ?", questionnaireID).
+		Select("id, questionnaire_id, revision, user_traqid, created_at").
+		Order("revision DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision アンケートの特定のリビジョンの取得
+func (*Revision) GetRevision(ctx context.Context, questionnaireID int, revisionNum int) (*Revisions, *QuestionnaireSnapshot, error) {
+	revision := Revisions{}
+
+	err := getTx(ctx).
+		Where("questionnaire_id = ? AND revision = ?", questionnaireID, revisionNum).
+		First(&revision).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get a revision: %w", err)
+	}
+
+	snapshot := QuestionnaireSnapshot{}
+	err = json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode a snapshot: %w", err)
+	}
+
+	return &revision, &snapshot, nil
+}
+
+// getLastRevisionID アンケートの最新のリビジョンのIDの取得
+func getLastRevisionID(tx *gorm.DB, questionnaireID int) (null.Int, error) {
+	revision := Revisions{}
+
+	err := tx.
+		Where("questionnaire_id = ?", questionnaireID).
+		Select("id").
+		Order("revision DESC").
+		First(&revision).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return null.NewInt(0, false), nil
+	}
+	if err != nil {
+		return null.NewInt(0, false), fmt.Errorf("failed to get the last revision: %w", err)
+	}
+
+	return null.IntFrom(int64(revision.ID)), nil
+}
+
+func getQuestionnaireSnapshot(tx *gorm.DB, questionnaire *Questionnaires) (*QuestionnaireSnapshot, error) {
+	snapshot := QuestionnaireSnapshot{
+		Title:          questionnaire.Title,
+		Description:    questionnaire.Description,
+		ResTimeLimit:   questionnaire.ResTimeLimit,
+		ResSharedTo:    questionnaire.ResSharedTo,
+		ResponseMode:   questionnaire.ResponseMode,
+		IsAnonymous:    questionnaire.IsAnonymous,
+		Targets:        []string{},
+		Administrators: []string{},
+		Questions:      []QuestionSnapshot{},
+	}
+
+	err := tx.
+		Table("targets").
+		Where("questionnaire_id = ?", questionnaire.ID).
+		Order("user_traqid").
+		Pluck("user_traqid", &snapshot.Targets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets: %w", err)
+	}
+
+	err = tx.
+		Table("administrators").
+		Where("questionnaire_id = ? AND role = ?", questionnaire.ID, RoleOwner).
+		Order("user_traqid").
+		Pluck("user_traqid", &snapshot.Administrators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get administrators: %w", err)
+	}
+
+	questions := []Questions{}
+	err = tx.
+		Where("questionnaire_id = ?", questionnaire.ID).
+		Order("question_num").
+		Find(&questions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questions: %w", err)
+	}
+	if len(questions) == 0 {
+		return &snapshot, nil
+	}
+
+	questionIDs := make([]int, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.ID)
+	}
+
+	options := []struct {
+		QuestionID int
+		Body       null.String
+	}{}
+	err = tx.
+		Model(Options{}).
+		Where("question_id IN (?)", questionIDs).
+		Order("option_num").
+		Select("question_id, body").
+		Find(&options).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get options: %w", err)
+	}
+	optionMap := map[int][]string{}
+	for _, option := range options {
+		optionMap[option.QuestionID] = append(optionMap[option.QuestionID], option.Body.ValueOrZero())
+	}
+
+	scaleLabels := []ScaleLabels{}
+	err = tx.
+		Where("question_id IN (?)", questionIDs).
+		Find(&scaleLabels).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale labels: %w", err)
+	}
+	scaleLabelMap := make(map[int]ScaleLabels, len(scaleLabels))
+	for _, scaleLabel := range scaleLabels {
+		scaleLabelMap[scaleLabel.QuestionID] = scaleLabel
+	}
+
+	validations := []Validations{}
+	err = tx.
+		Where("question_id IN (?)", questionIDs).
+		Find(&validations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validations: %w", err)
+	}
+	validationMap := make(map[int]Validations, len(validations))
+	for _, validation := range validations {
+		validationMap[validation.QuestionID] = validation
+	}
+
+	for _, question := range questions {
+		questionOptions, ok := optionMap[question.ID]
+		if !ok {
+			questionOptions = []string{}
+		}
+		scaleLabel := scaleLabelMap[question.ID]
+		validation := validationMap[question.ID]
+
+		snapshot.Questions = append(snapshot.Questions, QuestionSnapshot{
+			QuestionID:      question.ID,
+			PageNum:         question.PageNum,
+			QuestionNum:     question.QuestionNum,
+			QuestionType:    question.Type,
+			Body:            question.Body,
+			IsRequired:      question.IsRequired,
+			Options:         questionOptions,
+			ScaleLabelRight: scaleLabel.ScaleLabelRight,
+			ScaleLabelLeft:  scaleLabel.ScaleLabelLeft,
+			ScaleMin:        scaleLabel.ScaleMin,
+			ScaleMax:        scaleLabel.ScaleMax,
+			RegexPattern:    validation.RegexPattern,
+			MinBound:        validation.MinBound,
+			MaxBound:        validation.MaxBound,
+		})
+	}
+
+	return &snapshot, nil
+}
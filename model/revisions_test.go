@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestInsertRevision(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
+	require.NoError(t, err)
+
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
+	require.NoError(t, err)
+
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "MultipleChoice", "質問文", true)
+	require.NoError(t, err)
+	err = optionImpl.InsertOption(ctx, questionID, 1, "選択肢1")
+	require.NoError(t, err)
+
+	revision, err := revisionImpl.InsertRevision(ctx, questionnaireID, userOne)
+	assertion.NoError(err, "first revision")
+	assertion.Equal(1, revision, "first revision")
+
+	err = questionnaireImpl.UpdateQuestionnaire(ctx, "第2回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false, questionnaireID)
+	require.NoError(t, err)
+
+	revision, err = revisionImpl.InsertRevision(ctx, questionnaireID, userTwo)
+	assertion.NoError(err, "second revision")
+	assertion.Equal(2, revision, "second revision")
+
+	_, err = revisionImpl.InsertRevision(ctx, -1, userOne)
+	assertion.Equal(true, errors.Is(err, gorm.ErrRecordNotFound), "questionnaire not found")
+
+	revisions, err := revisionImpl.GetRevisions(ctx, questionnaireID)
+	assertion.NoError(err, "GetRevisions")
+	if assertion.Len(revisions, 2, "GetRevisions") {
+		assertion.Equal(2, revisions[0].Revision, "GetRevisions", "revision")
+		assertion.Equal(userTwo, revisions[0].UserTraqid, "GetRevisions", "user")
+		assertion.Equal(1, revisions[1].Revision, "GetRevisions", "revision")
+		assertion.Equal(userOne, revisions[1].UserTraqid, "GetRevisions", "user")
+	}
+
+	_, snapshot, err := revisionImpl.GetRevision(ctx, questionnaireID, 1)
+	assertion.NoError(err, "GetRevision")
+	assertion.Equal("第1回集会らん☆ぷろ募集アンケート", snapshot.Title, "GetRevision", "title")
+	assertion.Equal([]string{userOne}, snapshot.Administrators, "GetRevision", "administrators")
+	if assertion.Len(snapshot.Questions, 1, "GetRevision", "questions") {
+		assertion.Equal(questionID, snapshot.Questions[0].QuestionID, "GetRevision", "questionID")
+		assertion.Equal([]string{"選択肢1"}, snapshot.Questions[0].Options, "GetRevision", "options")
+	}
+
+	_, snapshot, err = revisionImpl.GetRevision(ctx, questionnaireID, 2)
+	assertion.NoError(err, "GetRevision")
+	assertion.Equal("第2回集会らん☆ぷろ募集アンケート", snapshot.Title, "GetRevision", "title")
+
+	_, _, err = revisionImpl.GetRevision(ctx, questionnaireID, 3)
+	assertion.Equal(true, errors.Is(err, gorm.ErrRecordNotFound), "GetRevision", "revision not found")
+
+	responseID, err := respondentImpl.InsertRespondent(ctx, userTwo, questionnaireID, null.NewTime(time.Now(), true))
+	require.NoError(t, err)
+
+	respondent := Respondents{}
+	err = db.Where("response_id = ?", responseID).First(&respondent).Error
+	require.NoError(t, err)
+	assertion.Equal(true, respondent.RevisionID.Valid, "respondent revisionID")
+	assertion.Equal(int64(revisions[0].ID), respondent.RevisionID.ValueOrZero(), "respondent revisionID")
+}
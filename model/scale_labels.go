@@ -2,11 +2,15 @@
 
 package model
 
+import (
+	"context"
+)
+
 // IScaleLabel ScaleLabelのRepository
 type IScaleLabel interface {
-	InsertScaleLabel(lastID int, label ScaleLabels) error
-	UpdateScaleLabel(questionID int, label ScaleLabels) error
-	DeleteScaleLabel(questionID int) error
-	GetScaleLabels(questionIDs []int) ([]ScaleLabels, error)
+	InsertScaleLabel(ctx context.Context, lastID int, label ScaleLabels) error
+	UpdateScaleLabel(ctx context.Context, questionID int, label ScaleLabels) error
+	DeleteScaleLabel(ctx context.Context, questionID int) error
+	GetScaleLabels(ctx context.Context, questionIDs []int) ([]ScaleLabels, error)
 	CheckScaleLabel(label ScaleLabels, response string) error
 }
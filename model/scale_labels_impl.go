@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 )
@@ -23,17 +24,17 @@ type ScaleLabels struct {
 }
 
 // InsertScaleLabel IDを指定してlabelを挿入する
-func (*ScaleLabel) InsertScaleLabel(lastID int, label ScaleLabels) error {
+func (*ScaleLabel) InsertScaleLabel(ctx context.Context, lastID int, label ScaleLabels) error {
 	label.QuestionID = lastID
-	if err := db.Create(&label).Error; err != nil {
+	if err := getTx(ctx).Create(&label).Error; err != nil {
 		return fmt.Errorf("failed to insert the scale label (lastID: %d): %w", lastID, err)
 	}
 	return nil
 }
 
 // UpdateScaleLabel questionIDを指定してlabelを更新する
-func (*ScaleLabel) UpdateScaleLabel(questionID int, label ScaleLabels) error {
-	result := db.
+func (*ScaleLabel) UpdateScaleLabel(ctx context.Context, questionID int, label ScaleLabels) error {
+	result := getTx(ctx).
 		Model(&ScaleLabels{}).
 		Where("question_id = ?", questionID).
 		Update(map[string]interface{}{
@@ -53,8 +54,8 @@ func (*ScaleLabel) UpdateScaleLabel(questionID int, label ScaleLabels) error {
 }
 
 // DeleteScaleLabel questionIDを指定してlabelを削除する
-func (*ScaleLabel) DeleteScaleLabel(questionID int) error {
-	result := db.
+func (*ScaleLabel) DeleteScaleLabel(ctx context.Context, questionID int) error {
+	result := getTx(ctx).
 		Where("question_id = ?", questionID).
 		Delete(&ScaleLabels{})
 	err := result.Error
@@ -68,9 +69,9 @@ func (*ScaleLabel) DeleteScaleLabel(questionID int) error {
 }
 
 // GetScaleLabels 指定されたquestionIDの配列のlabelを取得する
-func (*ScaleLabel) GetScaleLabels(questionIDs []int) ([]ScaleLabels, error) {
+func (*ScaleLabel) GetScaleLabels(ctx context.Context, questionIDs []int) ([]ScaleLabels, error) {
 	labels := []ScaleLabels{}
-	err := db.
+	err := getTx(ctx).
 		Where("question_id IN (?)", questionIDs).
 		Find(&labels).Error
 	if err != nil {
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"math"
 	"strings"
@@ -15,12 +16,14 @@ import (
 func TestInsertScaleLabel(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -113,7 +116,7 @@ func TestInsertScaleLabel(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "LinearScale", "Linear", true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "LinearScale", "Linear", true)
 		require.NoError(t, err)
 		if !testCase.args.validID {
 			questionID = -1
@@ -127,7 +130,7 @@ func TestInsertScaleLabel(t *testing.T) {
 			ScaleMax:        testCase.args.ScaleMax,
 		}
 
-		err = scaleLabelImpl.InsertScaleLabel(questionID, label)
+		err = scaleLabelImpl.InsertScaleLabel(ctx, questionID, label)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -154,12 +157,14 @@ func TestInsertScaleLabel(t *testing.T) {
 func TestUpdateScaleLabel(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -216,7 +221,7 @@ func TestUpdateScaleLabel(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "LinearScale", "Linear", true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "LinearScale", "Linear", true)
 		require.NoError(t, err)
 
 		label := ScaleLabels{
@@ -227,7 +232,7 @@ func TestUpdateScaleLabel(t *testing.T) {
 			ScaleMax:        5,
 		}
 
-		err = scaleLabelImpl.InsertScaleLabel(questionID, label)
+		err = scaleLabelImpl.InsertScaleLabel(ctx, questionID, label)
 		require.NoError(t, err)
 
 		if !testCase.args.validID {
@@ -242,7 +247,7 @@ func TestUpdateScaleLabel(t *testing.T) {
 			ScaleMax:        testCase.args.ScaleMax,
 		}
 
-		err = scaleLabelImpl.UpdateScaleLabel(questionID, label)
+		err = scaleLabelImpl.UpdateScaleLabel(ctx, questionID, label)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -270,12 +275,14 @@ func TestUpdateScaleLabel(t *testing.T) {
 func TestDeleteScaleLabel(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -322,7 +329,7 @@ func TestDeleteScaleLabel(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "LinearScale", "Linear", true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "LinearScale", "Linear", true)
 		require.NoError(t, err)
 
 		label := ScaleLabels{
@@ -333,14 +340,14 @@ func TestDeleteScaleLabel(t *testing.T) {
 			ScaleMax:        testCase.args.ScaleMax,
 		}
 
-		err = scaleLabelImpl.InsertScaleLabel(questionID, label)
+		err = scaleLabelImpl.InsertScaleLabel(ctx, questionID, label)
 		require.NoError(t, err)
 
 		if !testCase.args.validID {
 			questionID = -1
 		}
 
-		err = scaleLabelImpl.DeleteScaleLabel(questionID)
+		err = scaleLabelImpl.DeleteScaleLabel(ctx, questionID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -357,12 +364,14 @@ func TestDeleteScaleLabel(t *testing.T) {
 
 func TestGetScaleLabels(t *testing.T) {
 	t.Parallel()
+
+	ctx := context.Background()
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -401,9 +410,9 @@ func TestGetScaleLabels(t *testing.T) {
 	questionIDs := make([]int, 0, 3)
 	labelMap := make(map[int]ScaleLabels)
 	for _, label := range labels {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "LinearScale", "Linear", true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "LinearScale", "Linear", true)
 		require.NoError(t, err)
-		err = scaleLabelImpl.InsertScaleLabel(questionID, label)
+		err = scaleLabelImpl.InsertScaleLabel(ctx, questionID, label)
 		require.NoError(t, err)
 		label.QuestionID = questionID
 		questionIDs = append(questionIDs, questionID)
@@ -442,7 +451,7 @@ func TestGetScaleLabels(t *testing.T) {
 
 	for _, testCase := range testCases {
 
-		labels, err := scaleLabelImpl.GetScaleLabels(testCase.args.questionIDs)
+		labels, err := scaleLabelImpl.GetScaleLabels(ctx, testCase.args.questionIDs)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -474,15 +483,17 @@ func TestGetScaleLabels(t *testing.T) {
 func TestCheckScaleLabel(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
-	questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "LinearScale", "Linear", true)
+	questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "LinearScale", "Linear", true)
 	require.NoError(t, err)
 
 	label := ScaleLabels{
@@ -493,7 +504,7 @@ func TestCheckScaleLabel(t *testing.T) {
 		ScaleMax:        5,
 	}
 
-	err = scaleLabelImpl.InsertScaleLabel(questionID, label)
+	err = scaleLabelImpl.InsertScaleLabel(ctx, questionID, label)
 	require.NoError(t, err)
 
 	type args struct {
@@ -0,0 +1,16 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// ISession SessionのRepository
+type ISession interface {
+	InsertSession(ctx context.Context, sessionID string, userID string, expiresAt time.Time) error
+	GetSessionUserID(ctx context.Context, sessionID string) (string, error)
+	DeleteSession(ctx context.Context, sessionID string) error
+	DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int, error)
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Session SessionRepositoryの実装
+type Session struct{}
+
+// NewSession Sessionのコンストラクター
+func NewSession() *Session {
+	return new(Session)
+}
+
+// Sessions sessionsテーブルの構造体
+type Sessions struct {
+	// SessionHash セッションIDが漏れないようにSHA-256のハッシュのみを保存する
+	SessionHash string    `gorm:"type:char(64) NOT NULL PRIMARY KEY;"`
+	UserTraqid  string    `gorm:"type:char(30) NOT NULL;"`
+	CreatedAt   time.Time `gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+	ExpiresAt   time.Time `gorm:"type:timestamp NOT NULL;"`
+}
+
+// hashSecret セッションIDやAPIトークンをDBに保存するためのハッシュ
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// InsertSession ログインしたユーザーのセッションの追加
+func (*Session) InsertSession(ctx context.Context, sessionID string, userID string, expiresAt time.Time) error {
+	session := Sessions{
+		SessionHash: hashSecret(sessionID),
+		UserTraqid:  userID,
+		ExpiresAt:   expiresAt,
+	}
+
+	err := getTx(ctx).Create(&session).Error
+	if err != nil {
+		return fmt.Errorf("failed to insert a session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSessionUserID 有効期限内のセッションのユーザーの取得
+func (*Session) GetSessionUserID(ctx context.Context, sessionID string) (string, error) {
+	session := Sessions{}
+	err := getTx(ctx).
+		Where("session_hash = ? AND expires_at > ?", hashSecret(sessionID), time.Now()).
+		Select("user_traqid").
+		First(&session).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get a session: %w", err)
+	}
+
+	return session.UserTraqid, nil
+}
+
+// DeleteSession ログアウトしたセッションの削除
+func (*Session) DeleteSession(ctx context.Context, sessionID string) error {
+	err := getTx(ctx).
+		Where("session_hash = ?", hashSecret(sessionID)).
+		Delete(&Sessions{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete a session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions 有効期限を過ぎたセッションの削除
+func (*Session) DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int, error) {
+	result := getTx(ctx).
+		Where("expires_at < ?", expiredBefore).
+		Delete(&Sessions{})
+	err := result.Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return int(result.RowsAffected), nil
+}
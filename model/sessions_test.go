@@ -0,0 +1,71 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	sessionID := "insert-session"
+	err := sessionImpl.InsertSession(ctx, sessionID, userOne, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	userID, err := sessionImpl.GetSessionUserID(ctx, sessionID)
+	assertion.NoError(err, "get")
+	assertion.Equal(userOne, userID, "userID")
+
+	session := Sessions{}
+	err = db.
+		Where("user_traqid = ?", userOne).
+		First(&session).Error
+	require.NoError(t, err)
+	assertion.NotEqual(sessionID, session.SessionHash, "session id is hashed")
+
+	_, err = sessionImpl.GetSessionUserID(ctx, "unknown-session")
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "unknown session")
+
+	err = sessionImpl.DeleteSession(ctx, sessionID)
+	assertion.NoError(err, "delete")
+
+	_, err = sessionImpl.GetSessionUserID(ctx, sessionID)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "deleted session")
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	expiredID := "expired-session"
+	err := sessionImpl.InsertSession(ctx, expiredID, userTwo, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	validID := "valid-session"
+	err = sessionImpl.InsertSession(ctx, validID, userTwo, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = sessionImpl.GetSessionUserID(ctx, expiredID)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "expired session")
+
+	count, err := sessionImpl.DeleteExpiredSessions(ctx, time.Now())
+	assertion.NoError(err, "delete expired")
+	assertion.GreaterOrEqual(count, 1, "deleted count")
+
+	userID, err := sessionImpl.GetSessionUserID(ctx, validID)
+	assertion.NoError(err, "valid session")
+	assertion.Equal(userTwo, userID, "valid session")
+}
@@ -0,0 +1,18 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// IShareLink ShareLinkのRepository
+type IShareLink interface {
+	InsertShareLink(ctx context.Context, questionnaireID int, createdBy string, maxUses null.Int, expiresAt null.Time) (int, error)
+	GetShareLinks(ctx context.Context, questionnaireID int) ([]ShareLinks, error)
+	GetShareLink(ctx context.Context, shareLinkID int) (*ShareLinks, error)
+	UseShareLink(ctx context.Context, shareLinkID int) error
+	RevokeShareLink(ctx context.Context, questionnaireID int, shareLinkID int) error
+}
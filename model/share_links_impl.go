@@ -0,0 +1,133 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"gopkg.in/guregu/null.v3"
+)
+
+// ShareLink ShareLinkRepositoryの実装
+type ShareLink struct{}
+
+// NewShareLink ShareLinkのコンストラクター
+func NewShareLink() *ShareLink {
+	return new(ShareLink)
+}
+
+// ShareLinks share_linksテーブルの構造体
+type ShareLinks struct {
+	ID              int    `json:"id"              gorm:"type:int(11) AUTO_INCREMENT NOT NULL PRIMARY KEY;"`
+	QuestionnaireID int    `json:"questionnaireID" gorm:"type:int(11) NOT NULL;"`
+	CreatedBy       string `json:"created_by"      gorm:"type:char(30) NOT NULL;"`
+	// MaxUses 回答できる回数の上限 (NULLの場合は無制限)
+	MaxUses   null.Int  `json:"max_uses"   gorm:"type:int(11) NULL;default:NULL;"`
+	UseCount  int       `json:"use_count"  gorm:"type:int(11) NOT NULL;default:0;"`
+	ExpiresAt null.Time `json:"expires_at" gorm:"type:timestamp NULL;default:NULL;"`
+	RevokedAt null.Time `json:"revoked_at" gorm:"type:timestamp NULL;default:NULL;"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp NOT NULL;default:CURRENT_TIMESTAMP;"`
+}
+
+// IsAvailable 失効しておらず，期限内で，回数の上限に達していないか
+func (shareLink *ShareLinks) IsAvailable(now time.Time) bool {
+	if shareLink.RevokedAt.Valid {
+		return false
+	}
+	if shareLink.ExpiresAt.Valid && !shareLink.ExpiresAt.Time.After(now) {
+		return false
+	}
+	if shareLink.MaxUses.Valid && int64(shareLink.UseCount) >= shareLink.MaxUses.Int64 {
+		return false
+	}
+
+	return true
+}
+
+// InsertShareLink 共有リンクの追加
+func (*ShareLink) InsertShareLink(ctx context.Context, questionnaireID int, createdBy string, maxUses null.Int, expiresAt null.Time) (int, error) {
+	shareLink := ShareLinks{
+		QuestionnaireID: questionnaireID,
+		CreatedBy:       createdBy,
+		MaxUses:         maxUses,
+		ExpiresAt:       expiresAt,
+	}
+
+	err := getTx(ctx).Create(&shareLink).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert a share link: %w", err)
+	}
+
+	return shareLink.ID, nil
+}
+
+// GetShareLinks アンケートの共有リンクの取得 (失効したものも含む)
+func (*ShareLink) GetShareLinks(ctx context.Context, questionnaireID int) ([]ShareLinks, error) {
+	shareLinks := []ShareLinks{}
+	err := getTx(ctx).
+		Where("questionnaire_id = ?", questionnaireID).
+		Order("created_at DESC").
+		Find(&shareLinks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share links: %w", err)
+	}
+
+	return shareLinks, nil
+}
+
+// GetShareLink 削除されていないアンケートの共有リンクの取得
+func (*ShareLink) GetShareLink(ctx context.Context, shareLinkID int) (*ShareLinks, error) {
+	shareLink := ShareLinks{}
+	err := getTx(ctx).
+		Table("share_links").
+		Joins("INNER JOIN questionnaires ON share_links.questionnaire_id = questionnaires.id").
+		Where("share_links.id = ? AND questionnaires.deleted_at IS NULL", shareLinkID).
+		Select("share_links.*").
+		First(&shareLink).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a share link: %w", err)
+	}
+
+	return &shareLink, nil
+}
+
+/*
+UseShareLink 共有リンクの使用回数を増やす
+失効している，期限切れ，回数の上限に達している場合はErrNoRecordUpdatedを返す
+*/
+func (*ShareLink) UseShareLink(ctx context.Context, shareLinkID int) error {
+	// 同時に使われても上限を超えないように条件付きで更新する
+	result := getTx(ctx).
+		Model(&ShareLinks{}).
+		Where("id = ? AND revoked_at IS NULL", shareLinkID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("max_uses IS NULL OR use_count < max_uses").
+		UpdateColumn("use_count", gorm.Expr("use_count + 1"))
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to use a share link: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to use a share link: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
+
+// RevokeShareLink 共有リンクの失効
+func (*ShareLink) RevokeShareLink(ctx context.Context, questionnaireID int, shareLinkID int) error {
+	result := getTx(ctx).
+		Model(&ShareLinks{}).
+		Where("id = ? AND questionnaire_id = ? AND revoked_at IS NULL", shareLinkID, questionnaireID).
+		UpdateColumn("revoked_at", time.Now())
+	err := result.Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke a share link: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to revoke a share link: %w", ErrNoRecordUpdated)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func createShareLinkTestQuestionnaire(t *testing.T) int {
+	t.Helper()
+
+	questionnaire := Questionnaires{
+		Title:       "第1回集会らん☆ぷろ募集アンケート",
+		Description: "第1回集会らん☆ぷろ参加者募集",
+	}
+	err := db.Create(&questionnaire).Error
+	require.NoError(t, err)
+
+	return questionnaire.ID
+}
+
+func TestInsertShareLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createShareLinkTestQuestionnaire(t)
+
+	shareLinkID, err := shareLinkImpl.InsertShareLink(ctx, questionnaireID, userOne, null.IntFrom(10), null.TimeFrom(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	shareLink, err := shareLinkImpl.GetShareLink(ctx, shareLinkID)
+	require.NoError(t, err)
+	assertion.Equal(questionnaireID, shareLink.QuestionnaireID, "questionnaireID")
+	assertion.Equal(userOne, shareLink.CreatedBy, "createdBy")
+	assertion.Equal(int64(10), shareLink.MaxUses.Int64, "maxUses")
+	assertion.Equal(0, shareLink.UseCount, "useCount")
+	assertion.True(shareLink.IsAvailable(time.Now()), "available")
+
+	shareLinks, err := shareLinkImpl.GetShareLinks(ctx, questionnaireID)
+	require.NoError(t, err)
+	if assertion.Len(shareLinks, 1) {
+		assertion.Equal(shareLinkID, shareLinks[0].ID)
+	}
+
+	_, err = shareLinkImpl.GetShareLink(ctx, shareLinkID+1000000)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "unknown share link")
+}
+
+func TestUseShareLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createShareLinkTestQuestionnaire(t)
+
+	shareLinkID, err := shareLinkImpl.InsertShareLink(ctx, questionnaireID, userOne, null.IntFrom(2), null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		err = shareLinkImpl.UseShareLink(ctx, shareLinkID)
+		assertion.NoError(err, "use %d", i)
+	}
+
+	err = shareLinkImpl.UseShareLink(ctx, shareLinkID)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "max uses")
+
+	shareLink, err := shareLinkImpl.GetShareLink(ctx, shareLinkID)
+	require.NoError(t, err)
+	assertion.Equal(2, shareLink.UseCount, "useCount")
+	assertion.False(shareLink.IsAvailable(time.Now()), "used up")
+
+	expiredShareLinkID, err := shareLinkImpl.InsertShareLink(ctx, questionnaireID, userOne, null.NewInt(0, false), null.TimeFrom(time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+
+	err = shareLinkImpl.UseShareLink(ctx, expiredShareLinkID)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "expired")
+}
+
+func TestRevokeShareLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	questionnaireID := createShareLinkTestQuestionnaire(t)
+
+	shareLinkID, err := shareLinkImpl.InsertShareLink(ctx, questionnaireID, userOne, null.NewInt(0, false), null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+
+	err = shareLinkImpl.RevokeShareLink(ctx, questionnaireID+1000000, shareLinkID)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "another questionnaire")
+
+	err = shareLinkImpl.RevokeShareLink(ctx, questionnaireID, shareLinkID)
+	require.NoError(t, err)
+
+	err = shareLinkImpl.UseShareLink(ctx, shareLinkID)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "revoked")
+
+	shareLink, err := shareLinkImpl.GetShareLink(ctx, shareLinkID)
+	require.NoError(t, err)
+	assertion.False(shareLink.IsAvailable(time.Now()), "revoked")
+
+	err = shareLinkImpl.RevokeShareLink(ctx, questionnaireID, shareLinkID)
+	assertion.True(errors.Is(err, ErrNoRecordUpdated), "already revoked")
+}
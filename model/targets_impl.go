@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jinzhu/gorm"
@@ -17,12 +18,13 @@ func NewTarget() *Target {
 
 //Targets targetsテーブルの構造体
 type Targets struct {
-	QuestionnaireID int    `sql:"type:int(11);not null;primary_key;"`
-	UserTraqid      string `gorm:"type:char(30);not null;primary_key;"`
+	QuestionnaireID int `sql:"type:int(11);not null;primary_key;"`
+	// UserTraqid traQID，traQのグループのIDまたは全員を表すtraP
+	UserTraqid string `gorm:"type:char(36);not null;primary_key;"`
 }
 
 // InsertTargets アンケートの対象を追加
-func (*Target) InsertTargets(questionnaireID int, targets []string) error {
+func (*Target) InsertTargets(ctx context.Context, questionnaireID int, targets []string) error {
 	rowTargets := make([]interface{}, 0, len(targets))
 	for _, target := range targets {
 		rowTargets = append(rowTargets, Targets{
@@ -31,7 +33,7 @@ func (*Target) InsertTargets(questionnaireID int, targets []string) error {
 		})
 	}
 
-	err := gormbulk.BulkInsert(db, rowTargets, len(rowTargets))
+	err := gormbulk.BulkInsert(getTx(ctx), rowTargets, len(rowTargets))
 	if err != nil {
 		return fmt.Errorf("failed to insert target: %w", err)
 	}
@@ -40,8 +42,8 @@ func (*Target) InsertTargets(questionnaireID int, targets []string) error {
 }
 
 // DeleteTargets アンケートの対象を削除
-func (*Target) DeleteTargets(questionnaireID int) error {
-	err := db.
+func (*Target) DeleteTargets(ctx context.Context, questionnaireID int) error {
+	err := getTx(ctx).
 		Where("questionnaire_id = ?", questionnaireID).
 		Delete(&Targets{}).Error
 	if err != nil {
@@ -52,9 +54,9 @@ func (*Target) DeleteTargets(questionnaireID int) error {
 }
 
 // GetTargets アンケートの対象一覧を取得
-func (*Target) GetTargets(questionnaireIDs []int) ([]Targets, error) {
+func (*Target) GetTargets(ctx context.Context, questionnaireIDs []int) ([]Targets, error) {
 	targets := []Targets{}
-	err := db.
+	err := getTx(ctx).
 		Where("questionnaire_id IN (?)", questionnaireIDs).
 		Find(&targets).Error
 	if err != nil && !gorm.IsRecordNotFoundError(err) {
@@ -0,0 +1,13 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package model
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ITransaction Transactionのinterface
+type ITransaction interface {
+	Do(ctx context.Context, txOption *sql.TxOptions, f func(ctx context.Context) error) error
+}
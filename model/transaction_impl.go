@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Transaction ITransactionの実装
+type Transaction struct{}
+
+// NewTransaction Transactionのコンストラクター
+func NewTransaction() *Transaction {
+	return new(Transaction)
+}
+
+type ctxKey string
+
+const txKey ctxKey = "transaction"
+
+// Do トランザクション内でfを実行する
+// fに渡されるctxを使ったRepositoryの操作は全て同じトランザクションで行われる
+// ctxが既にトランザクション内の場合はそのトランザクションでfを実行する
+func (*Transaction) Do(ctx context.Context, txOption *sql.TxOptions, f func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return f(ctx)
+	}
+
+	tx := db.BeginTx(ctx, txOption)
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin a transaction: %w", tx.Error)
+	}
+
+	err := func() (err error) {
+		panicked := true
+		defer func() {
+			// fがpanicした場合もロールバックする
+			if panicked || err != nil {
+				tx.Rollback()
+			}
+		}()
+
+		err = f(context.WithValue(ctx, txKey, tx))
+		panicked = false
+		return err
+	}()
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit().Error
+	if err != nil {
+		return fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+
+	return nil
+}
+
+// getTx ctxのトランザクション(トランザクション外ではdb)
+func getTx(ctx context.Context) *gorm.DB {
+	tx, ok := ctx.Value(txKey).(*gorm.DB)
+	if ok {
+		return tx
+	}
+
+	return db
+}
+
+// runInTx ctxのトランザクション内でfを実行する
+// トランザクション外では新しいトランザクションを開始する
+func runInTx(ctx context.Context, f func(tx *gorm.DB) error) error {
+	tx, ok := ctx.Value(txKey).(*gorm.DB)
+	if ok {
+		return f(tx)
+	}
+
+	return db.Transaction(f)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestTransactionDo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assertion := assert.New(t)
+
+	var questionnaireID int
+	rollbackErr := errors.New("rollback")
+	err := transactionImpl.Do(ctx, nil, func(ctx context.Context) error {
+		var err error
+		questionnaireID, err = questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+		if err != nil {
+			return err
+		}
+
+		return rollbackErr
+	})
+	assertion.Equal(rollbackErr, err, "the error of f is returned as it is")
+
+	_, _, _, _, err = questionnaireImpl.GetQuestionnaireInfo(ctx, questionnaireID)
+	assertion.True(errors.Is(err, gorm.ErrRecordNotFound), "rolled back")
+
+	err = transactionImpl.Do(ctx, nil, func(ctx context.Context) error {
+		var err error
+		questionnaireID, err = questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "private", ResponseModeMultiple, false)
+		if err != nil {
+			return err
+		}
+
+		// 入れ子のトランザクションは外側のトランザクションで実行される
+		return transactionImpl.Do(ctx, nil, func(ctx context.Context) error {
+			return targetImpl.InsertTargets(ctx, questionnaireID, []string{userOne})
+		})
+	})
+	require.NoError(t, err)
+
+	_, targets, _, _, err := questionnaireImpl.GetQuestionnaireInfo(ctx, questionnaireID)
+	require.NoError(t, err)
+	assertion.Equal([]string{userOne}, targets, "committed")
+}
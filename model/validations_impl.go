@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -23,17 +24,17 @@ type Validations struct {
 }
 
 // InsertValidation IDを指定してvalidationsを挿入する
-func (*Validation) InsertValidation(lastID int, validation Validations) error {
+func (*Validation) InsertValidation(ctx context.Context, lastID int, validation Validations) error {
 	validation.QuestionID = lastID
-	if err := db.Create(&validation).Error; err != nil {
+	if err := getTx(ctx).Create(&validation).Error; err != nil {
 		return fmt.Errorf("failed to insert the validation (lastID: %d): %w", lastID, err)
 	}
 	return nil
 }
 
 // UpdateValidation questionIDを指定してvalidationを更新する
-func (*Validation) UpdateValidation(questionID int, validation Validations) error {
-	result := db.
+func (*Validation) UpdateValidation(ctx context.Context, questionID int, validation Validations) error {
+	result := getTx(ctx).
 		Model(&Validations{}).
 		Where("question_id = ?", questionID).
 		Update(map[string]interface{}{
@@ -52,8 +53,8 @@ func (*Validation) UpdateValidation(questionID int, validation Validations) erro
 }
 
 // DeleteValidation questionIDを指定してvalidationを削除する
-func (*Validation) DeleteValidation(questionID int) error {
-	result := db.
+func (*Validation) DeleteValidation(ctx context.Context, questionID int) error {
+	result := getTx(ctx).
 		Where("question_id = ?", questionID).
 		Delete(&Validations{})
 	err := result.Error
@@ -67,9 +68,9 @@ func (*Validation) DeleteValidation(questionID int) error {
 }
 
 // GetValidations qustionIDのリストから対応するvalidationsのリストを取得する
-func (*Validation) GetValidations(qustionIDs []int) ([]Validations, error) {
+func (*Validation) GetValidations(ctx context.Context, qustionIDs []int) ([]Validations, error) {
 	validations := []Validations{}
-	err := db.
+	err := getTx(ctx).
 		Where("question_id IN (?)", qustionIDs).
 		Find(&validations).
 		Error
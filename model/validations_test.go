@@ -2,6 +2,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -15,12 +16,14 @@ import (
 func TestInsertValidation(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -164,7 +167,7 @@ func TestInsertValidation(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, testCase.QuestionType, testCase.QuestionType, true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, testCase.QuestionType, testCase.QuestionType, true)
 		require.NoError(t, err)
 		if !testCase.args.validID {
 			questionID = -1
@@ -177,7 +180,7 @@ func TestInsertValidation(t *testing.T) {
 			MaxBound:     testCase.args.MaxBound,
 		}
 
-		err = validationImpl.InsertValidation(questionID, validation)
+		err = validationImpl.InsertValidation(ctx, questionID, validation)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
@@ -203,12 +206,14 @@ func TestInsertValidation(t *testing.T) {
 func TestUpdateValidation(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -285,7 +290,7 @@ func TestUpdateValidation(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, testCase.args.QuestionType, testCase.args.QuestionType, true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, testCase.args.QuestionType, testCase.args.QuestionType, true)
 		require.NoError(t, err)
 
 		validation := Validations{}
@@ -306,7 +311,7 @@ func TestUpdateValidation(t *testing.T) {
 			}
 		}
 
-		err = validationImpl.InsertValidation(questionID, validation)
+		err = validationImpl.InsertValidation(ctx, questionID, validation)
 		require.NoError(t, err)
 
 		if !testCase.args.validID {
@@ -320,7 +325,7 @@ func TestUpdateValidation(t *testing.T) {
 			MaxBound:     testCase.args.MaxBound,
 		}
 
-		err = validationImpl.UpdateValidation(questionID, validation)
+		err = validationImpl.UpdateValidation(ctx, questionID, validation)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -347,12 +352,14 @@ func TestUpdateValidation(t *testing.T) {
 func TestDeleteValidation(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -409,7 +416,7 @@ func TestDeleteValidation(t *testing.T) {
 		},
 	}
 	for _, testCase := range testCases {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, testCase.args.QuestionType, testCase.args.QuestionType, true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, testCase.args.QuestionType, testCase.args.QuestionType, true)
 		require.NoError(t, err)
 
 		validation := Validations{
@@ -419,14 +426,14 @@ func TestDeleteValidation(t *testing.T) {
 			MaxBound:     testCase.args.MaxBound,
 		}
 
-		err = validationImpl.InsertValidation(questionID, validation)
+		err = validationImpl.InsertValidation(ctx, questionID, validation)
 		require.NoError(t, err)
 
 		if !testCase.args.validID {
 			questionID = -1
 		}
 
-		err = validationImpl.DeleteValidation(questionID)
+		err = validationImpl.DeleteValidation(ctx, questionID)
 
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
@@ -444,12 +451,14 @@ func TestDeleteValidation(t *testing.T) {
 func TestGetValidations(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	assertion := assert.New(t)
 
-	questionnaireID, err := questionnaireImpl.InsertQuestionnaire("第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public")
+	questionnaireID, err := questionnaireImpl.InsertQuestionnaire(ctx, "第1回集会らん☆ぷろ募集アンケート", "第1回メンバー集会でのらん☆ぷろで発表したい人を募集します らん☆ぷろで発表したい人あつまれー！", null.NewTime(time.Now(), false), "public", ResponseModeMultiple, false)
 	require.NoError(t, err)
 
-	err = administratorImpl.InsertAdministrators(questionnaireID, []string{userOne})
+	err = administratorImpl.InsertAdministrators(ctx, questionnaireID, []string{userOne}, RoleOwner)
 	require.NoError(t, err)
 
 	type args struct {
@@ -487,9 +496,9 @@ func TestGetValidations(t *testing.T) {
 	questionIDs := make([]int, 0, 3)
 	validationMap := make(map[int]Validations)
 	for _, validation := range validations {
-		questionID, err := questionImpl.InsertQuestion(questionnaireID, 1, 1, "Text", "Text", true)
+		questionID, err := questionImpl.InsertQuestion(ctx, questionnaireID, 1, 1, "Text", "Text", true)
 		require.NoError(t, err)
-		err = validationImpl.InsertValidation(questionID, validation)
+		err = validationImpl.InsertValidation(ctx, questionID, validation)
 		require.NoError(t, err)
 		validation.QuestionID = questionID
 		questionIDs = append(questionIDs, questionID)
@@ -528,7 +537,7 @@ func TestGetValidations(t *testing.T) {
 
 	for _, testCase := range testCases {
 
-		validations, err := validationImpl.GetValidations(testCase.args.questionIDs)
+		validations, err := validationImpl.GetValidations(ctx, testCase.args.questionIDs)
 		if !testCase.expect.isErr {
 			assertion.NoError(err, testCase.description, "no error")
 		} else if testCase.expect.err != nil {
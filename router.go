@@ -1,15 +1,19 @@
 package main
 
 import (
+	"path/filepath"
+
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+
+	"github.com/traPtitech/anke-to/router"
 )
 
 // SetRouting ルーティングの設定
-func SetRouting(port string) {
+func SetRouting(api *router.API) {
 	e := echo.New()
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins:     []string{"http://localhost:8080"},
+		AllowOrigins:     api.Server.AllowOrigins,
 		AllowCredentials: true,
 	}))
 
@@ -17,32 +21,67 @@ func SetRouting(port string) {
 	e.Use(middleware.Recover())
 	e.Use(middleware.Logger())
 
-	api := InjectAPIServer()
-
 	// Static Files
-	e.Static("/", "client/dist")
-	e.Static("/js", "client/dist/js")
-	e.Static("/img", "client/dist/img")
-	e.Static("/fonts", "client/dist/fonts")
-	e.Static("/css", "client/dist/css")
+	staticRoot := api.Server.StaticRoot
+	e.Static("/", staticRoot)
+	e.Static("/js", filepath.Join(staticRoot, "js"))
+	e.Static("/img", filepath.Join(staticRoot, "img"))
+	e.Static("/fonts", filepath.Join(staticRoot, "fonts"))
+	e.Static("/css", filepath.Join(staticRoot, "css"))
+
+	e.File("/app.js", filepath.Join(staticRoot, "app.js"))
+	e.File("/favicon.ico", filepath.Join(staticRoot, "favicon.ico"))
+	e.File("*", filepath.Join(staticRoot, "index.html"))
+
+	api.Authenticator.SetRouting(e)
+
+	echoAPI := e.Group("/api", api.UserAuthenticate, api.RateLimit)
 
-	e.File("/app.js", "client/dist/app.js")
-	e.File("/favicon.ico", "client/dist/favicon.ico")
-	e.File("*", "client/dist/index.html")
+	// traQのアカウントがない回答者は共有リンクのトークンで回答のみができる
+	apiShare := e.Group("/api/share/:token", api.ShareLinkAuthenticate, api.GuestRateLimit)
+	{
+		apiShare.GET("", api.GetSharedQuestionnaire)
+		apiShare.POST("/responses", api.PostGuestResponse)
+	}
+
+	// APIトークンでのリクエストに必要なスコープ
+	readResults := api.RequireScope(router.ScopeReadResults)
+	manageQuestionnaires := api.RequireScope(router.ScopeManageQuestionnaires)
+	respond := api.RequireScope(router.ScopeRespond)
+	respondOrReadResults := api.RequireScope(router.ScopeRespond, router.ScopeReadResults)
+
+	// アンケートの管理者の役割で許可される操作
+	canViewResults := api.QuestionnairePermission(router.PermissionViewResults)
+	canEdit := api.QuestionnairePermission(router.PermissionEdit)
+	canManage := api.QuestionnairePermission(router.PermissionManage)
 
-	echoAPI := e.Group("/api", api.UserAuthenticate)
 	{
 		apiQuestionnnaires := echoAPI.Group("/questionnaires")
 		{
 			apiQuestionnnaires.GET("", api.GetQuestionnaires)
-			apiQuestionnnaires.POST("", api.PostQuestionnaire)
+			apiQuestionnnaires.POST("", api.PostQuestionnaire, manageQuestionnaires, api.IdempotencyKey)
 			apiQuestionnnaires.GET("/:questionnaireID", api.GetQuestionnaire)
-			apiQuestionnnaires.PATCH("/:questionnaireID", api.EditQuestionnaire, api.QuestionnaireAdministratorAuthenticate)
-			apiQuestionnnaires.DELETE("/:questionnaireID", api.DeleteQuestionnaire, api.QuestionnaireAdministratorAuthenticate)
+			apiQuestionnnaires.PATCH("/:questionnaireID", api.EditQuestionnaire, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.DELETE("/:questionnaireID", api.DeleteQuestionnaire, manageQuestionnaires, canManage)
+			apiQuestionnnaires.POST("/:questionnaireID/restore", api.RestoreQuestionnaire, manageQuestionnaires, api.DeletedQuestionnairePermission(router.PermissionManage))
+			apiQuestionnnaires.POST("/:questionnaireID/transfer", api.TransferQuestionnaireOwnership, manageQuestionnaires, canManage)
+			apiQuestionnnaires.GET("/:questionnaireID/invitations", api.GetQuestionnaireInvitations, manageQuestionnaires, canManage)
+			apiQuestionnnaires.POST("/:questionnaireID/invitations", api.PostInvitation, manageQuestionnaires, canManage)
+			apiQuestionnnaires.GET("/:questionnaireID/share-links", api.GetQuestionnaireShareLinks, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.POST("/:questionnaireID/share-links", api.PostShareLink, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.DELETE("/:questionnaireID/share-links/:shareLinkID", api.RevokeQuestionnaireShareLink, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.POST("/:questionnaireID/close", api.CloseQuestionnaire, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.POST("/:questionnaireID/reopen", api.ReopenQuestionnaire, manageQuestionnaires, canEdit)
 			apiQuestionnnaires.GET("/:questionnaireID/questions", api.GetQuestions)
+			apiQuestionnnaires.GET("/:questionnaireID/progress", api.GetQuestionnaireProgress, readResults, canViewResults)
+			apiQuestionnnaires.POST("/:questionnaireID/responses/proxy", api.PostProxyResponse, manageQuestionnaires, canEdit, api.IdempotencyKey)
+			apiQuestionnnaires.POST("/:questionnaireID/responses/import", api.ImportResponses, manageQuestionnaires, canEdit, api.IdempotencyKey)
+			apiQuestionnnaires.GET("/:questionnaireID/revisions", api.GetRevisions, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.GET("/:questionnaireID/revisions/diff", api.GetRevisionDiff, manageQuestionnaires, canEdit)
+			apiQuestionnnaires.GET("/:questionnaireID/revisions/:revision", api.GetRevision, manageQuestionnaires, canEdit)
 		}
 
-		apiQuestions := echoAPI.Group("/questions")
+		apiQuestions := echoAPI.Group("/questions", manageQuestionnaires)
 		{
 			apiQuestions.POST("", api.PostQuestion)
 			apiQuestions.PATCH("/:questionID", api.EditQuestion, api.QuestionAdministratorAuthenticate)
@@ -51,34 +90,47 @@ func SetRouting(port string) {
 
 		apiResponses := echoAPI.Group("/responses")
 		{
-			apiResponses.POST("", api.PostResponse)
-			apiResponses.GET("/:responseID", api.GetResponse)
-			apiResponses.PATCH("/:responseID", api.EditResponse, api.RespondentAuthenticate)
-			apiResponses.DELETE("/:responseID", api.DeleteResponse, api.RespondentAuthenticate)
+			apiResponses.POST("", api.PostResponse, respond, api.IdempotencyKey)
+			apiResponses.GET("/:responseID", api.GetResponse, respondOrReadResults)
+			apiResponses.PATCH("/:responseID", api.EditResponse, respond, api.RespondentAuthenticate)
+			apiResponses.DELETE("/:responseID", api.DeleteResponse, respond, api.RespondentAuthenticate)
+			apiResponses.PATCH("/:responseID/draft", api.EditResponseDraft, respond, api.RespondentAuthenticate)
+			apiResponses.POST("/:responseID/submit", api.SubmitResponse, respond, api.RespondentAuthenticate)
+			apiResponses.GET("/:responseID/history", api.GetResponseHistory, respondOrReadResults)
 		}
 
 		apiUsers := echoAPI.Group("/users")
 		{
-			/*
-				TODO
-				apiUsers.GET("")
-			*/
+			apiUsers.GET("", api.GetUsers)
 			apiUsersMe := apiUsers.Group("/me")
 			{
 				apiUsersMe.GET("", api.GetUsersMe)
-				apiUsersMe.GET("/responses", api.GetMyResponses)
-				apiUsersMe.GET("/responses/:questionnaireID", api.GetMyResponsesByID)
+				apiUsersMe.GET("/responses", api.GetMyResponses, respond)
+				apiUsersMe.GET("/responses/:questionnaireID", api.GetMyResponsesByID, respond)
 				apiUsersMe.GET("/targeted", api.GetTargetedQuestionnaire)
 				apiUsersMe.GET("/administrates", api.GetMyQuestionnaire)
+				apiUsersMe.GET("/trash", api.GetMyDeletedQuestionnaires, manageQuestionnaires)
+				apiUsersMe.GET("/tokens", api.GetMyAPITokens, api.RejectAPIToken)
+				apiUsersMe.POST("/tokens", api.PostMyAPIToken, api.RejectAPIToken)
+				apiUsersMe.DELETE("/tokens/:tokenID", api.DeleteMyAPIToken, api.RejectAPIToken)
+				apiUsersMe.GET("/invitations", api.GetMyInvitations, api.RejectAPIToken)
+				apiUsersMe.POST("/invitations/:invitationID/accept", api.AcceptMyInvitation, api.RejectAPIToken)
+				apiUsersMe.DELETE("/invitations/:invitationID", api.DeclineMyInvitation, api.RejectAPIToken)
 			}
 			apiUsers.GET("/:traQID/targeted", api.GetTargettedQuestionnairesBytraQID)
 		}
 
-		apiResults := echoAPI.Group("/results")
+		echoAPI.GET("/groups", api.GetGroups)
+
+		// 管理操作の監査ログはanke-to全体の管理者のみが閲覧できる
+		echoAPI.GET("/audit-logs", api.GetAuditLogs, api.RejectAPIToken, api.SystemAdministratorAuthenticate)
+
+		apiResults := echoAPI.Group("/results", readResults)
 		{
 			apiResults.GET("/:questionnaireID", api.GetResults)
+			apiResults.GET("/:questionnaireID/crosstab", api.GetCrossTabulation)
 		}
 	}
 
-	e.Logger.Fatal(e.Start(port))
+	e.Logger.Fatal(e.Start(api.Server.Port))
 }
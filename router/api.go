@@ -1,5 +1,9 @@
 package router
 
+import (
+	"github.com/traPtitech/anke-to/config"
+)
+
 // API api全体の構造体
 type API struct {
 	*Middleware
@@ -8,10 +12,15 @@ type API struct {
 	*Response
 	*Result
 	*User
+	*Revision
+	*Group
+	*AuditLog
+	// Server CORSや静的ファイルなどのサーバーの設定
+	Server config.ServerConfig
 }
 
 // NewAPI APIのコンストラクタ
-func NewAPI(middleware *Middleware, questionnaire *Questionnaire, question *Question, response *Response, result *Result, user *User) *API {
+func NewAPI(middleware *Middleware, questionnaire *Questionnaire, question *Question, response *Response, result *Result, user *User, revision *Revision, group *Group, auditLog *AuditLog, server config.ServerConfig) *API {
 	return &API{
 		Middleware:    middleware,
 		Questionnaire: questionnaire,
@@ -19,5 +28,9 @@ func NewAPI(middleware *Middleware, questionnaire *Questionnaire, question *Ques
 		Response:      response,
 		Result:        result,
 		User:          user,
+		Revision:      revision,
+		Group:         group,
+		AuditLog:      auditLog,
+		Server:        server,
 	}
 }
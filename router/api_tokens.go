@@ -0,0 +1,188 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+)
+
+// APIトークンのスコープ
+const (
+	// ScopeReadResults 結果と回答状況の閲覧
+	ScopeReadResults = "results:read"
+	// ScopeManageQuestionnaires アンケートの作成・編集・削除
+	ScopeManageQuestionnaires = "questionnaires:manage"
+	// ScopeRespond アンケートへの回答
+	ScopeRespond = "responses:write"
+)
+
+var apiTokenScopes = []string{ScopeReadResults, ScopeManageQuestionnaires, ScopeRespond}
+
+const (
+	// apiTokenPrefix 漏洩したトークンを見つけやすくするための接頭辞
+	apiTokenPrefix        = "anketo_"
+	maxAPITokenNameLength = 50
+)
+
+// APITokenInfo 発行済みのAPIトークンの情報 (トークン自体は含まない)
+type APITokenInfo struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  null.Time `json:"expires_at"`
+	LastUsedAt null.Time `json:"last_used_at"`
+}
+
+// CreatedAPIToken 発行したAPIトークン (トークンは発行時のみ返す)
+type CreatedAPIToken struct {
+	APITokenInfo
+	Token string `json:"token"`
+}
+
+// GetMyAPITokens GET /users/me/tokens
+func (u *User) GetMyAPITokens(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	apiTokens, err := u.GetAPITokens(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	apiTokenInfos := make([]APITokenInfo, 0, len(apiTokens))
+	for i := range apiTokens {
+		apiTokenInfos = append(apiTokenInfos, newAPITokenInfo(&apiTokens[i]))
+	}
+
+	return c.JSON(http.StatusOK, apiTokenInfos)
+}
+
+// PostMyAPIToken POST /users/me/tokens
+func (u *User) PostMyAPIToken(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	req := struct {
+		Name      string    `json:"name"`
+		Scopes    []string  `json:"scopes"`
+		ExpiresAt null.Time `json:"expires_at"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to bind request: %w", err))
+	}
+
+	nameLength := utf8.RuneCountInString(req.Name)
+	if nameLength == 0 || nameLength > maxAPITokenNameLength {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("name must be 1 to %d characters", maxAPITokenNameLength))
+	}
+
+	scopes, err := normalizeAPITokenScopes(req.Scopes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if req.ExpiresAt.Valid && !req.ExpiresAt.Time.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	randomString, err := generateRandomString()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to generate api token: %w", err))
+	}
+	token := apiTokenPrefix + randomString
+
+	tokenID, err := u.InsertAPIToken(ctx, userID, req.Name, token, scopes, req.ExpiresAt)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, CreatedAPIToken{
+		APITokenInfo: APITokenInfo{
+			ID:        tokenID,
+			Name:      req.Name,
+			Scopes:    scopes,
+			CreatedAt: time.Now(),
+			ExpiresAt: req.ExpiresAt,
+		},
+		Token: token,
+	})
+}
+
+// DeleteMyAPIToken DELETE /users/me/tokens/:tokenID
+func (u *User) DeleteMyAPIToken(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	strTokenID := c.Param("tokenID")
+	tokenID, err := strconv.Atoi(strTokenID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid tokenID:%s(error: %w)", strTokenID, err))
+	}
+
+	err = u.DeleteAPIToken(ctx, userID, tokenID)
+	if errors.Is(err, model.ErrNoRecordDeleted) {
+		return echo.NewHTTPError(http.StatusNotFound, "the api token does not exist")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// normalizeAPITokenScopes スコープが有効かの確認と重複の除去
+func normalizeAPITokenScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	scopeSet := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		scopeSet[scope] = struct{}{}
+	}
+
+	normalizedScopes := make([]string, 0, len(scopeSet))
+	for _, scope := range apiTokenScopes {
+		if _, ok := scopeSet[scope]; ok {
+			normalizedScopes = append(normalizedScopes, scope)
+			delete(scopeSet, scope)
+		}
+	}
+	for scope := range scopeSet {
+		return nil, fmt.Errorf("invalid scope: %s", scope)
+	}
+
+	return normalizedScopes, nil
+}
+
+func newAPITokenInfo(apiToken *model.APITokens) APITokenInfo {
+	return APITokenInfo{
+		ID:         apiToken.ID,
+		Name:       apiToken.Name,
+		Scopes:     apiToken.GetScopes(),
+		CreatedAt:  apiToken.CreatedAt,
+		ExpiresAt:  apiToken.ExpiresAt,
+		LastUsedAt: apiToken.LastUsedAt,
+	}
+}
@@ -0,0 +1,149 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+)
+
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 100
+)
+
+// AuditLog AuditLogの構造体
+type AuditLog struct {
+	model.IAuditLog
+}
+
+// NewAuditLog AuditLogのコンストラクタ
+func NewAuditLog(auditLog model.IAuditLog) *AuditLog {
+	return &AuditLog{
+		IAuditLog: auditLog,
+	}
+}
+
+// AuditLogInfo 操作前後の状態をJSONのまま返す監査ログ
+type AuditLogInfo struct {
+	ID         int             `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int             `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// GetAuditLogs GET /audit-logs
+func (a *AuditLog) GetAuditLogs(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	filter := model.AuditLogFilter{
+		Actor:      c.QueryParam("actor"),
+		Action:     c.QueryParam("action"),
+		TargetType: c.QueryParam("target_type"),
+	}
+	if strTargetID := c.QueryParam("target_id"); strTargetID != "" {
+		targetID, err := strconv.Atoi(strTargetID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid target_id: %s", strTargetID))
+		}
+		filter.TargetID = null.IntFrom(int64(targetID))
+	}
+	var err error
+	filter.Since, err = parseAuditLogTime(c.QueryParam("since"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid since: %w", err))
+	}
+	filter.Until, err = parseAuditLogTime(c.QueryParam("until"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid until: %w", err))
+	}
+
+	limit := defaultAuditLogLimit
+	if strLimit := c.QueryParam("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil || limit <= 0 || limit > maxAuditLogLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid limit: %s", strLimit))
+		}
+	}
+	// beforeには前のページの最後の監査ログのIDを指定する
+	before := 0
+	if strBefore := c.QueryParam("before"); strBefore != "" {
+		before, err = strconv.Atoi(strBefore)
+		if err != nil || before <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid before: %s", strBefore))
+		}
+	}
+
+	// 次のページがあるかを確認するために1件多く取得する
+	auditLogs, err := a.IAuditLog.GetAuditLogs(ctx, filter, before, limit+1)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if len(auditLogs) > limit {
+		auditLogs = auditLogs[:limit]
+
+		query := c.QueryParams()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("before", strconv.Itoa(auditLogs[limit-1].ID))
+		c.Response().Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", c.Request().URL.Path, query.Encode()))
+	}
+
+	auditLogInfos := make([]AuditLogInfo, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		auditLogInfos = append(auditLogInfos, AuditLogInfo{
+			ID:         auditLog.ID,
+			Actor:      auditLog.Actor,
+			Action:     auditLog.Action,
+			TargetType: auditLog.TargetType,
+			TargetID:   auditLog.TargetID,
+			Before:     rawSnapshot(auditLog.Before),
+			After:      rawSnapshot(auditLog.After),
+			CreatedAt:  auditLog.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, auditLogInfos)
+}
+
+func parseAuditLogTime(strTime string) (null.Time, error) {
+	if strTime == "" {
+		return null.NewTime(time.Time{}, false), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, strTime)
+	if err != nil {
+		return null.Time{}, err
+	}
+
+	return null.TimeFrom(t), nil
+}
+
+func rawSnapshot(snapshot null.String) json.RawMessage {
+	if !snapshot.Valid {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(snapshot.String)
+}
+
+// toHTTPError 監査ログの記録の失敗などのechoのエラーでないエラーを500にする
+func toHTTPError(err error) error {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, err)
+}
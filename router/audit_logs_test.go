@@ -0,0 +1,149 @@
+package router
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+)
+
+// fakeTransaction トランザクションを使わずにそのまま実行するテスト用のmodel.ITransactionの実装
+type fakeTransaction struct{}
+
+func (*fakeTransaction) Do(ctx context.Context, txOption *sql.TxOptions, f func(ctx context.Context) error) error {
+	return f(ctx)
+}
+
+// fakeAuditLog テスト用のメモリ上のmodel.IAuditLogの実装
+type fakeAuditLog struct {
+	auditLogs []model.AuditLogs
+}
+
+func (f *fakeAuditLog) InsertAuditLog(ctx context.Context, actor string, action string, targetType string, targetID int, before null.String, after null.String) error {
+	f.auditLogs = append(f.auditLogs, model.AuditLogs{
+		ID:         len(f.auditLogs) + 1,
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now(),
+	})
+
+	return nil
+}
+
+func (f *fakeAuditLog) GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, beforeID int, limit int) ([]model.AuditLogs, error) {
+	auditLogs := []model.AuditLogs{}
+	for i := len(f.auditLogs) - 1; i >= 0 && len(auditLogs) < limit; i-- {
+		auditLog := f.auditLogs[i]
+		if (filter.Actor != "" && auditLog.Actor != filter.Actor) ||
+			(filter.Action != "" && auditLog.Action != filter.Action) ||
+			(filter.TargetType != "" && auditLog.TargetType != filter.TargetType) ||
+			(filter.TargetID.Valid && int64(auditLog.TargetID) != filter.TargetID.Int64) ||
+			(beforeID != 0 && auditLog.ID >= beforeID) {
+			continue
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return auditLogs, nil
+}
+
+// newFakeAudit 招待と共有リンクのテストで使う操作のみを記録できるservice.Audit
+func newFakeAudit(auditLog model.IAuditLog, questionnaire model.IQuestionnaire, administrator model.IAdministrator, invitation model.IInvitation, shareLink model.IShareLink) *service.Audit {
+	return service.NewAudit(&fakeTransaction{}, auditLog, questionnaire, administrator, invitation, nil, nil, nil, nil, nil, shareLink)
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	auditLog := &fakeAuditLog{}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, auditLog.InsertAuditLog(ctx, "mazrean", service.ActionQuestionnaireUpdate, service.TargetQuestionnaire, 1, null.StringFrom(`{"title":"before"}`), null.StringFrom(`{"title":"after"}`)))
+	}
+	require.NoError(t, auditLog.InsertAuditLog(ctx, "mds_boy", service.ActionShareLinkCreate, service.TargetShareLink, 1, null.NewString("", false), null.StringFrom(`{"id":1}`)))
+	a := NewAuditLog(auditLog)
+
+	e := echo.New()
+	getAuditLogs := func(query string) ([]AuditLogInfo, *httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/audit-logs?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := a.GetAuditLogs(c)
+		if err != nil {
+			return nil, rec, err
+		}
+
+		auditLogInfos := []AuditLogInfo{}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&auditLogInfos))
+
+		return auditLogInfos, rec, nil
+	}
+
+	auditLogInfos, rec, err := getAuditLogs("")
+	require.NoError(t, err)
+	if assert.Len(t, auditLogInfos, 4) {
+		assert.Equal(t, 4, auditLogInfos[0].ID, "newest first")
+		assert.JSONEq(t, "null", string(auditLogInfos[0].Before))
+		assert.JSONEq(t, `{"title":"after"}`, string(auditLogInfos[1].After))
+	}
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	auditLogInfos, rec, err = getAuditLogs("actor=mazrean&limit=2")
+	require.NoError(t, err)
+	if assert.Len(t, auditLogInfos, 2) {
+		assert.Equal(t, 3, auditLogInfos[0].ID)
+		assert.Equal(t, 2, auditLogInfos[1].ID)
+	}
+	assert.Equal(t, `</api/audit-logs?actor=mazrean&before=2&limit=2>; rel="next"`, rec.Header().Get("Link"))
+
+	auditLogInfos, rec, err = getAuditLogs("actor=mazrean&limit=2&before=2")
+	require.NoError(t, err)
+	if assert.Len(t, auditLogInfos, 1) {
+		assert.Equal(t, 1, auditLogInfos[0].ID)
+	}
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	for _, query := range []string{"limit=0", "limit=101", "target_id=a", "since=yesterday", "before=-1"} {
+		_, rec, err = getAuditLogs(query)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(err, rec), query)
+	}
+}
+
+func TestSystemAdministratorAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{}
+	e := echo.New()
+
+	for userID, expectCode := range map[string]int{
+		"mazrean":     http.StatusOK,
+		"xxarupakaxx": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/audit-logs", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userIDKey, userID)
+
+		err := m.SystemAdministratorAuthenticate(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+		assert.Equal(t, expectCode, getStatusCode(err, rec), userID)
+	}
+}
@@ -0,0 +1,328 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"golang.org/x/oauth2"
+
+	"github.com/traPtitech/anke-to/config"
+	"github.com/traPtitech/anke-to/model"
+)
+
+// ErrUnauthenticated ログインしていない
+var ErrUnauthenticated = errors.New("not authenticated")
+
+// Authenticator リクエストを送ったユーザーの認証のinterface
+type Authenticator interface {
+	// Authenticate リクエストを送ったユーザーのtraQIDを返す
+	// ログインしていない場合はErrUnauthenticatedを返す
+	Authenticate(c echo.Context) (string, error)
+	// SetRouting ログインなどの認証に必要なエンドポイントの追加
+	SetRouting(e *echo.Echo)
+}
+
+// NewAuthenticator 設定で指定された方式のAuthenticatorのコンストラクタ
+func NewAuthenticator(authConfig config.AuthConfig, traQConfig config.TraQConfig, session model.ISession) (Authenticator, error) {
+	switch authConfig.Mode {
+	case config.AuthModeHeader:
+		return NewHeaderAuthenticator(), nil
+	case config.AuthModeOAuth:
+		oauthConfig := OAuthConfig{
+			ClientID:     authConfig.OAuthClientID,
+			ClientSecret: authConfig.OAuthClientSecret,
+			RedirectURL:  authConfig.OAuthRedirectURL,
+			TraQURL:      traQConfig.APIURL,
+		}
+		if oauthConfig.ClientID == "" || oauthConfig.RedirectURL == "" {
+			return nil, errors.New("client id and redirect url are required in oauth mode")
+		}
+
+		return NewOAuthAuthenticator(oauthConfig, session), nil
+	case config.AuthModeDev:
+		if authConfig.DevUser == "" {
+			return nil, errors.New("dev user is required in dev mode")
+		}
+
+		return NewFixedUserAuthenticator(authConfig.DevUser), nil
+	default:
+		// ヘッダーを偽装できる環境で誤って信頼しないよう既定の方式は設けない
+		return nil, fmt.Errorf("invalid auth mode: %q", authConfig.Mode)
+	}
+}
+
+// HeaderAuthenticator X-Showcase-Userヘッダーによる認証
+type HeaderAuthenticator struct{}
+
+// NewHeaderAuthenticator HeaderAuthenticatorのコンストラクタ
+func NewHeaderAuthenticator() *HeaderAuthenticator {
+	return new(HeaderAuthenticator)
+}
+
+// Authenticate X-Showcase-Userヘッダーのユーザーを返す
+func (*HeaderAuthenticator) Authenticate(c echo.Context) (string, error) {
+	userID := c.Request().Header.Get("X-Showcase-User")
+
+	// トークンを持たないユーザはアクセスできない
+	if userID == "" || userID == "-" {
+		return "", ErrUnauthenticated
+	}
+
+	return userID, nil
+}
+
+// SetRouting ログインはshowcaseが行うので何もしない
+func (*HeaderAuthenticator) SetRouting(*echo.Echo) {}
+
+// FixedUserAuthenticator 常に同じユーザーとして扱う開発用の認証
+type FixedUserAuthenticator struct {
+	userID string
+}
+
+// NewFixedUserAuthenticator FixedUserAuthenticatorのコンストラクタ
+func NewFixedUserAuthenticator(userID string) *FixedUserAuthenticator {
+	return &FixedUserAuthenticator{
+		userID: userID,
+	}
+}
+
+// Authenticate 固定のユーザーを返す
+func (a *FixedUserAuthenticator) Authenticate(echo.Context) (string, error) {
+	return a.userID, nil
+}
+
+// SetRouting ログインが無いので何もしない
+func (*FixedUserAuthenticator) SetRouting(*echo.Echo) {}
+
+// OAuthConfig traQのOAuth2の設定
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL /api/oauth2/callbackのURL
+	RedirectURL string
+	// TraQURL traQのAPIのURL
+	TraQURL string
+}
+
+const (
+	sessionCookieName    = "anke-to_session"
+	oauthStateCookieName = "anke-to_oauth_state"
+	sessionMaxAge        = 7 * 24 * time.Hour
+	oauthStateMaxAge     = 10 * time.Minute
+	oauthRequestTimeout  = 10 * time.Second
+)
+
+// OAuthAuthenticator traQのOAuth2の認可コードフローとセッションによる認証
+type OAuthAuthenticator struct {
+	model.ISession
+	config       *oauth2.Config
+	userInfoURL  string
+	secureCookie bool
+}
+
+// NewOAuthAuthenticator OAuthAuthenticatorのコンストラクタ
+func NewOAuthAuthenticator(config OAuthConfig, session model.ISession) *OAuthAuthenticator {
+	traQURL := strings.TrimSuffix(config.TraQURL, "/")
+
+	return &OAuthAuthenticator{
+		ISession: session,
+		config: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       []string{"read"},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  traQURL + "/oauth2/authorize",
+				TokenURL: traQURL + "/oauth2/token",
+			},
+		},
+		userInfoURL:  traQURL + "/users/me",
+		secureCookie: strings.HasPrefix(config.RedirectURL, "https://"),
+	}
+}
+
+// Authenticate セッションのユーザーを返す
+func (a *OAuthAuthenticator) Authenticate(c echo.Context) (string, error) {
+	ctx := c.Request().Context()
+
+	cookie, err := c.Cookie(sessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return "", ErrUnauthenticated
+	}
+
+	userID, err := a.GetSessionUserID(ctx, cookie.Value)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrUnauthenticated
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return userID, nil
+}
+
+// SetRouting ログイン，コールバック，ログアウトのエンドポイントの追加
+func (a *OAuthAuthenticator) SetRouting(e *echo.Echo) {
+	e.GET("/api/oauth2/login", a.Login)
+	e.GET("/api/oauth2/callback", a.Callback)
+	e.POST("/api/oauth2/logout", a.Logout)
+}
+
+// Login GET /api/oauth2/login
+func (a *OAuthAuthenticator) Login(c echo.Context) error {
+	state, err := generateRandomString()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to generate state: %w", err))
+	}
+
+	// ログイン後に戻るページとstateをCSRF対策のためにcookieに保存する
+	stateValues := url.Values{}
+	stateValues.Set("state", state)
+	stateValues.Set("redirect", getSafeRedirectPath(c.QueryParam("redirect")))
+	c.SetCookie(a.newCookie(oauthStateCookieName, stateValues.Encode(), oauthStateMaxAge))
+
+	return c.Redirect(http.StatusFound, a.config.AuthCodeURL(state))
+}
+
+// Callback GET /api/oauth2/callback
+func (a *OAuthAuthenticator) Callback(c echo.Context) error {
+	cookie, err := c.Cookie(oauthStateCookieName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the login session has expired")
+	}
+	c.SetCookie(a.newCookie(oauthStateCookieName, "", -1))
+
+	stateValues, err := url.ParseQuery(cookie.Value)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid state cookie: %w", err))
+	}
+	state := stateValues.Get("state")
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.QueryParam("state"))) != 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid state")
+	}
+
+	if oauthErr := c.QueryParam("error"); oauthErr != "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("failed to authorize: %s", oauthErr))
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), oauthRequestTimeout)
+	defer cancel()
+
+	token, err := a.config.Exchange(ctx, c.QueryParam("code"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to exchange code: %w", err))
+	}
+
+	userID, err := a.getUserID(ctx, token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get user: %w", err))
+	}
+
+	sessionID, err := generateRandomString()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to generate session id: %w", err))
+	}
+	err = a.InsertSession(ctx, sessionID, userID, time.Now().Add(sessionMaxAge))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	c.SetCookie(a.newCookie(sessionCookieName, sessionID, sessionMaxAge))
+
+	return c.Redirect(http.StatusFound, getSafeRedirectPath(stateValues.Get("redirect")))
+}
+
+// Logout POST /api/oauth2/logout
+func (a *OAuthAuthenticator) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	cookie, err := c.Cookie(sessionCookieName)
+	if err == nil && cookie.Value != "" {
+		err := a.DeleteSession(ctx, cookie.Value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+	}
+	c.SetCookie(a.newCookie(sessionCookieName, "", -1))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getUserID アクセストークンのユーザーのtraQIDの取得
+func (a *OAuthAuthenticator) getUserID(ctx context.Context, token *oauth2.Token) (string, error) {
+	req, err := http.NewRequest("GET", a.userInfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.config.Client(ctx, token).Do(req.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get user info: unexpected status %s", resp.Status)
+	}
+
+	user := struct {
+		Name string `json:"name"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&user)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode user info: %w", err)
+	}
+	if user.Name == "" {
+		return "", errors.New("empty user name")
+	}
+
+	return user.Name, nil
+}
+
+// newCookie maxAgeが負の場合はcookieを削除する
+func (a *OAuthAuthenticator) newCookie(name string, value string, maxAge time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   a.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(maxAge.Seconds())
+	}
+
+	return cookie
+}
+
+// getSafeRedirectPath オープンリダイレクトを防ぐため同じオリジンのパス以外は/にする
+func getSafeRedirectPath(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+
+	return redirect
+}
+
+func generateRandomString() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,431 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/anke-to/config"
+)
+
+// fakeSession テスト用のメモリ上のmodel.ISessionの実装
+type fakeSession struct {
+	sync.Mutex
+	sessions map[string]fakeSessionEntry
+}
+
+type fakeSessionEntry struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{
+		sessions: map[string]fakeSessionEntry{},
+	}
+}
+
+func (s *fakeSession) InsertSession(ctx context.Context, sessionID string, userID string, expiresAt time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.sessions[sessionID] = fakeSessionEntry{
+		userID:    userID,
+		expiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (s *fakeSession) GetSessionUserID(ctx context.Context, sessionID string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry, ok := s.sessions[sessionID]
+	if !ok || !entry.expiresAt.After(time.Now()) {
+		return "", fmt.Errorf("failed to get a session: %w", gorm.ErrRecordNotFound)
+	}
+
+	return entry.userID, nil
+}
+
+func (s *fakeSession) DeleteSession(ctx context.Context, sessionID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.sessions, sessionID)
+
+	return nil
+}
+
+func (s *fakeSession) DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	count := 0
+	for sessionID, entry := range s.sessions {
+		if entry.expiresAt.Before(expiredBefore) {
+			delete(s.sessions, sessionID)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+const (
+	fakeClientID    = "client"
+	fakeAuthCode    = "code"
+	fakeAccessToken = "token"
+	fakeUserID      = "mazrean"
+)
+
+// newFakeTraQServer traQのOAuth2のトークンエンドポイントと/users/meのみを持つサーバー
+func newFakeTraQServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != fakeAuthCode {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fakeAccessToken,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":   "0fa5d740-0841-4b88-b7c8-34a68774c784",
+			"name": fakeUserID,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestOAuthAuthenticator(t *testing.T) (*echo.Echo, *OAuthAuthenticator, *fakeSession) {
+	server := newFakeTraQServer(t)
+	session := newFakeSession()
+	authenticator := NewOAuthAuthenticator(OAuthConfig{
+		ClientID:     fakeClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/oauth2/callback",
+		TraQURL:      server.URL,
+	}, session)
+
+	e := echo.New()
+	authenticator.SetRouting(e)
+
+	return e, authenticator, session
+}
+
+func doRequest(e *echo.Echo, method string, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func findCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+func authenticateWithCookies(e *echo.Echo, authenticator Authenticator, cookies ...*http.Cookie) (string, error) {
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	return authenticator.Authenticate(e.NewContext(req, httptest.NewRecorder()))
+}
+
+// login ログインのエンドポイントを叩いてstateのcookieとstateを返す
+func login(t *testing.T, e *echo.Echo, redirect string) (*http.Cookie, string) {
+	rec := doRequest(e, http.MethodGet, "/api/oauth2/login?redirect="+url.QueryEscape(redirect))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+	require.NoError(t, err)
+	assert.Equal(t, "/oauth2/authorize", location.Path)
+	assert.Equal(t, fakeClientID, location.Query().Get("client_id"))
+	assert.Equal(t, "code", location.Query().Get("response_type"))
+
+	stateCookie := findCookie(rec, oauthStateCookieName)
+	require.NotNil(t, stateCookie)
+	assert.True(t, stateCookie.HttpOnly)
+
+	return stateCookie, location.Query().Get("state")
+}
+
+func TestOAuthLogin(t *testing.T) {
+	t.Parallel()
+
+	e, authenticator, session := newTestOAuthAuthenticator(t)
+
+	stateCookie, state := login(t, e, "/questionnaires/1")
+	require.NotEmpty(t, state)
+
+	rec := doRequest(e, http.MethodGet, fmt.Sprintf("/api/oauth2/callback?code=%s&state=%s", fakeAuthCode, url.QueryEscape(state)), stateCookie)
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	assert.Equal(t, "/questionnaires/1", rec.Header().Get(echo.HeaderLocation))
+
+	sessionCookie := findCookie(rec, sessionCookieName)
+	require.NotNil(t, sessionCookie)
+	assert.True(t, sessionCookie.HttpOnly)
+	assert.False(t, sessionCookie.Secure)
+	assert.Len(t, session.sessions, 1)
+
+	userID, err := authenticateWithCookies(e, authenticator, sessionCookie)
+	require.NoError(t, err)
+	assert.Equal(t, fakeUserID, userID)
+
+	// stateは1度しか使えない
+	rec = doRequest(e, http.MethodGet, fmt.Sprintf("/api/oauth2/callback?code=%s&state=%s", fakeAuthCode, url.QueryEscape(state)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(e, http.MethodPost, "/api/oauth2/logout", sessionCookie)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, session.sessions, 0)
+
+	_, err = authenticateWithCookies(e, authenticator, sessionCookie)
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+}
+
+func TestOAuthCallback(t *testing.T) {
+	t.Parallel()
+
+	type test struct {
+		description string
+		state       func(state string) string
+		code        string
+		withCookie  bool
+		expectCode  int
+	}
+	testCases := []test{
+		{
+			description: "state mismatch",
+			state: func(string) string {
+				return "invalid"
+			},
+			code:       fakeAuthCode,
+			withCookie: true,
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			description: "empty state",
+			state: func(string) string {
+				return ""
+			},
+			code:       fakeAuthCode,
+			withCookie: true,
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			description: "no state cookie",
+			state: func(state string) string {
+				return state
+			},
+			code:       fakeAuthCode,
+			withCookie: false,
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			description: "invalid code",
+			state: func(state string) string {
+				return state
+			},
+			code:       "invalid",
+			withCookie: true,
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		e, _, session := newTestOAuthAuthenticator(t)
+		stateCookie, state := login(t, e, "/")
+
+		cookies := []*http.Cookie{}
+		if testCase.withCookie {
+			cookies = append(cookies, stateCookie)
+		}
+		rec := doRequest(e, http.MethodGet, fmt.Sprintf("/api/oauth2/callback?code=%s&state=%s", testCase.code, url.QueryEscape(testCase.state(state))), cookies...)
+		assert.Equal(t, testCase.expectCode, rec.Code, testCase.description)
+		assert.Nil(t, findCookie(rec, sessionCookieName), testCase.description)
+		assert.Len(t, session.sessions, 0, testCase.description)
+	}
+}
+
+func TestOAuthAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	e, authenticator, session := newTestOAuthAuthenticator(t)
+
+	err := session.InsertSession(ctx, "valid", fakeUserID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	err = session.InsertSession(ctx, "expired", fakeUserID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	userID, err := authenticateWithCookies(e, authenticator, &http.Cookie{Name: sessionCookieName, Value: "valid"})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeUserID, userID)
+
+	_, err = authenticateWithCookies(e, authenticator, &http.Cookie{Name: sessionCookieName, Value: "expired"})
+	assert.True(t, errors.Is(err, ErrUnauthenticated), "expired")
+
+	_, err = authenticateWithCookies(e, authenticator, &http.Cookie{Name: sessionCookieName, Value: "unknown"})
+	assert.True(t, errors.Is(err, ErrUnauthenticated), "unknown")
+
+	_, err = authenticateWithCookies(e, authenticator)
+	assert.True(t, errors.Is(err, ErrUnauthenticated), "no cookie")
+
+	// X-Showcase-Userヘッダーは信頼しない
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+	req.Header.Set("X-Showcase-User", fakeUserID)
+	_, err = authenticator.Authenticate(e.NewContext(req, httptest.NewRecorder()))
+	assert.True(t, errors.Is(err, ErrUnauthenticated), "header")
+}
+
+func TestGetSafeRedirectPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"/questionnaires/1":   "/questionnaires/1",
+		"/results/1?sort=-id": "/results/1?sort=-id",
+		"":                    "/",
+		"https://example.com": "/",
+		"//example.com":       "/",
+		"/\\example.com":      "/",
+		"javascript:alert(1)": "/",
+	}
+
+	for redirect, expect := range testCases {
+		assert.Equal(t, expect, getSafeRedirectPath(redirect), redirect)
+	}
+}
+
+func TestHeaderAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	authenticator := NewHeaderAuthenticator()
+
+	testCases := []struct {
+		header string
+		isErr  bool
+	}{
+		{header: fakeUserID},
+		{header: "", isErr: true},
+		{header: "-", isErr: true},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+		req.Header.Set("X-Showcase-User", testCase.header)
+
+		userID, err := authenticator.Authenticate(e.NewContext(req, httptest.NewRecorder()))
+		if testCase.isErr {
+			assert.True(t, errors.Is(err, ErrUnauthenticated), testCase.header)
+			continue
+		}
+		assert.NoError(t, err, testCase.header)
+		assert.Equal(t, testCase.header, userID, testCase.header)
+	}
+}
+
+func TestUserAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	middleware := NewMiddleware(nil, nil, nil, nil, nil, nil, nil, nil, NewHeaderAuthenticator(), nil, nil, config.IdempotencyKeyConfig{})
+	e.GET("/api/users/me", func(c echo.Context) error {
+		userID, err := getUserID(c)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, userID)
+	}, middleware.UserAuthenticate)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+	req.Header.Set("X-Showcase-User", fakeUserID)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, fakeUserID, strings.TrimSpace(rec.Body.String()))
+
+	rec = doRequest(e, http.MethodGet, "/api/users/me")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewAuthenticator(config.AuthConfig{}, config.TraQConfig{}, newFakeSession())
+	assert.Error(t, err, "empty mode")
+
+	authenticator, err := NewAuthenticator(config.AuthConfig{Mode: config.AuthModeHeader}, config.TraQConfig{}, newFakeSession())
+	require.NoError(t, err)
+	assert.IsType(t, &HeaderAuthenticator{}, authenticator)
+
+	authenticator, err = NewAuthenticator(config.AuthConfig{Mode: config.AuthModeDev, DevUser: "mds_boy"}, config.TraQConfig{}, newFakeSession())
+	require.NoError(t, err)
+	userID, err := authenticator.Authenticate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "mds_boy", userID)
+
+	_, err = NewAuthenticator(config.AuthConfig{Mode: config.AuthModeOAuth}, config.TraQConfig{}, newFakeSession())
+	assert.Error(t, err, "missing client id")
+
+	authenticator, err = NewAuthenticator(config.AuthConfig{
+		Mode:             config.AuthModeOAuth,
+		OAuthClientID:    fakeClientID,
+		OAuthRedirectURL: "https://anke-to.trap.jp/api/oauth2/callback",
+	}, config.TraQConfig{}, newFakeSession())
+	require.NoError(t, err)
+	assert.True(t, authenticator.(*OAuthAuthenticator).secureCookie)
+
+	_, err = NewAuthenticator(config.AuthConfig{Mode: "invalid"}, config.TraQConfig{}, newFakeSession())
+	assert.Error(t, err)
+}
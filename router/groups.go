@@ -0,0 +1,125 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/traPtitech/anke-to/traq"
+)
+
+// Group Groupの構造体
+type Group struct {
+	traq.IGroup
+}
+
+// NewGroup Groupのコンストラクタ
+func NewGroup(group traq.IGroup) *Group {
+	return &Group{
+		IGroup: group,
+	}
+}
+
+// GetGroups GET /groups
+func (g *Group) GetGroups(c echo.Context) error {
+	groups, err := g.IGroup.GetGroups()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get groups: %w", err))
+	}
+
+	return c.JSON(http.StatusOK, groups)
+}
+
+// getUserGroupIDs ユーザーが所属するtraQのグループのIDの取得
+// traQに繋がらない場合もアンケートの一覧などは返せるようにグループは無いものとして扱う
+func getUserGroupIDs(c echo.Context, group traq.IGroup, userID string) []string {
+	groupIDs, err := group.GetUserGroupIDs(userID)
+	if err != nil {
+		c.Logger().Error(fmt.Errorf("failed to get groups of %s: %w", userID, err))
+		return nil
+	}
+
+	return groupIDs
+}
+
+// checkGroupIDs 対象者・管理者に含まれるグループのIDがtraQに存在するかの確認
+func checkGroupIDs(group traq.IGroup, ids []string) error {
+	groupIDs := []string{}
+	for _, id := range ids {
+		if traq.IsGroupID(id) {
+			groupIDs = append(groupIDs, id)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	groups, err := group.GetGroups()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get groups: %w", err))
+	}
+	groupSet := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		groupSet[group.ID] = struct{}{}
+	}
+
+	for _, groupID := range groupIDs {
+		if _, ok := groupSet[groupID]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("group(%s) does not exist", groupID))
+		}
+	}
+
+	return nil
+}
+
+// getGroupMembers グループのメンバーのtraQIDの取得
+func getGroupMembers(group traq.IGroup, groupIDs []string) ([]string, error) {
+	groups, err := group.GetGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	groupIDSet := make(map[string]struct{}, len(groupIDs))
+	for _, groupID := range groupIDs {
+		groupIDSet[groupID] = struct{}{}
+	}
+
+	members := []string{}
+	for _, group := range groups {
+		if _, ok := groupIDSet[group.ID]; ok {
+			members = append(members, group.Members...)
+		}
+	}
+
+	return members, nil
+}
+
+// replaceGroupIDsWithNames traQでメンションできるようにグループのIDをグループ名に置き換える
+// traQに繋がらない場合はグループを除く
+func replaceGroupIDsWithNames(c echo.Context, group traq.IGroup, ids []string) []string {
+	names := make([]string, 0, len(ids))
+	var groupNames map[string]string
+	for _, id := range ids {
+		if !traq.IsGroupID(id) {
+			names = append(names, id)
+			continue
+		}
+
+		if groupNames == nil {
+			groups, err := group.GetGroups()
+			if err != nil {
+				c.Logger().Error(fmt.Errorf("failed to get groups: %w", err))
+			}
+			groupNames = make(map[string]string, len(groups))
+			for _, group := range groups {
+				groupNames[group.ID] = group.Name
+			}
+		}
+		if name, ok := groupNames[id]; ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/anke-to/traq"
+)
+
+const (
+	fakeGroupID1 = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	fakeGroupID2 = "c8c8a0f2-6c8a-4d53-9f6b-1f1c5bd5f0a7"
+	unknownGroup = "00000000-0000-4000-8000-000000000000"
+)
+
+// fakeGroup テスト用のtraq.IGroupの実装
+type fakeGroup struct {
+	groups []traq.Groups
+	err    error
+}
+
+func newFakeGroup() *fakeGroup {
+	return &fakeGroup{
+		groups: []traq.Groups{
+			{
+				ID:      fakeGroupID1,
+				Name:    "SysAd",
+				Members: []string{"mazrean", "xxarupakaxx"},
+			},
+			{
+				ID:      fakeGroupID2,
+				Name:    "kaitoke",
+				Members: []string{"mazrean", "mds_boy"},
+			},
+		},
+	}
+}
+
+func (f *fakeGroup) GetGroups() ([]traq.Groups, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.groups, nil
+}
+
+func (f *fakeGroup) GetUserGroupIDs(userID string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	groupIDs := []string{}
+	for _, group := range f.groups {
+		for _, member := range group.Members {
+			if member == userID {
+				groupIDs = append(groupIDs, group.ID)
+			}
+		}
+	}
+
+	return groupIDs, nil
+}
+
+func TestCheckGroupIDs(t *testing.T) {
+	t.Parallel()
+
+	group := newFakeGroup()
+
+	testCases := []struct {
+		description string
+		ids         []string
+		code        int
+	}{
+		{
+			description: "グループを含まないのでOK",
+			ids:         []string{"mazrean", "traP"},
+		},
+		{
+			description: "存在するグループなのでOK",
+			ids:         []string{"mazrean", fakeGroupID1, fakeGroupID2},
+		},
+		{
+			description: "存在しないグループなので400",
+			ids:         []string{fakeGroupID1, unknownGroup},
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := checkGroupIDs(group, testCase.ids)
+		if testCase.code == 0 {
+			assert.NoError(t, err, testCase.description)
+			continue
+		}
+
+		var httpErr *echo.HTTPError
+		if assert.True(t, errors.As(err, &httpErr), testCase.description) {
+			assert.Equal(t, testCase.code, httpErr.Code, testCase.description)
+		}
+	}
+
+	failingGroup := &fakeGroup{err: errors.New("traQ is down")}
+	assert.NoError(t, checkGroupIDs(failingGroup, []string{"mazrean"}), "traQに問い合わせないのでOK")
+
+	var httpErr *echo.HTTPError
+	if assert.True(t, errors.As(checkGroupIDs(failingGroup, []string{fakeGroupID1}), &httpErr)) {
+		assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	}
+}
+
+func TestGetGroupMembers(t *testing.T) {
+	t.Parallel()
+
+	members, err := getGroupMembers(newFakeGroup(), []string{fakeGroupID1, unknownGroup})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"mazrean", "xxarupakaxx"}, members)
+
+	_, err = getGroupMembers(&fakeGroup{err: errors.New("traQ is down")}, []string{fakeGroupID1})
+	assert.Error(t, err)
+}
+
+func TestReplaceGroupIDsWithNames(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	names := replaceGroupIDsWithNames(c, newFakeGroup(), []string{"mazrean", fakeGroupID2, unknownGroup, "traP"})
+	assert.Equal(t, []string{"mazrean", "kaitoke", "traP"}, names)
+
+	names = replaceGroupIDsWithNames(c, &fakeGroup{err: errors.New("traQ is down")}, []string{"mazrean", fakeGroupID1})
+	assert.Equal(t, []string{"mazrean"}, names)
+}
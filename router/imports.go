@@ -0,0 +1,409 @@
+package router
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+)
+
+// defaultImportTraqIDColumn 回答者のtraQIDの列名の既定値
+const defaultImportTraqIDColumn = "traqID"
+
+// importOptionSeparator Checkboxの回答で複数の選択肢を区切る文字
+const importOptionSeparator = ";"
+
+// ImportMapping CSVの列と質問の対応の構造体
+type ImportMapping struct {
+	// TraqIDColumn 回答者のtraQIDの列名 (空の場合は"traqID")
+	TraqIDColumn string `json:"traqID"`
+	// Questions 列名と質問のIDの対応 (空の場合は列名と質問文が一致する質問に対応させる)
+	Questions map[string]int `json:"questions"`
+}
+
+// ImportResult 回答のインポート結果の構造体
+type ImportResult struct {
+	DryRun      bool             `json:"dry_run"`
+	Total       int              `json:"total"`
+	ResponseIDs []int            `json:"responseIDs"`
+	Errors      []ImportRowError `json:"errors"`
+}
+
+// ImportRowError 行ごとのインポートのエラーの構造体
+type ImportRowError struct {
+	// Row ヘッダーを1行目とした行番号
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type importColumn struct {
+	index    int
+	question model.Questions
+	options  map[string]struct{}
+}
+
+type importRow struct {
+	row    int
+	traqID string
+	bodies []model.ResponseBody
+}
+
+// ImportResponses POST /questionnaires/:questionnaireID/responses/import
+func (r *Response) ImportResponses(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	dryRun := false
+	if strDryRun := c.QueryParam("dry_run"); strDryRun != "" {
+		dryRun, err = strconv.ParseBool(strDryRun)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid dry_run: %w", err))
+		}
+	}
+
+	mapping := ImportMapping{}
+	if strMapping := c.FormValue("mapping"); strMapping != "" {
+		if err := json.Unmarshal([]byte(strMapping), &mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid mapping: %w", err))
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to get file: %w", err))
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to open file: %w", err))
+	}
+	defer file.Close()
+
+	result, err := r.ImportResponsesFromCSV(ctx, questionnaireID, userID, mapping, file, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun && len(result.Errors) != 0 && len(result.ResponseIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, result)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ImportResponsesFromCSV CSVの各行を代理入力の回答として追加する
+// 1行でも検証に失敗した場合は回答を追加しない
+func (r *Response) ImportResponsesFromCSV(ctx context.Context, questionnaireID int, enteredBy string, mapping ImportMapping, reader io.Reader, dryRun bool) (*ImportResult, error) {
+	questionnaire, _, _, _, err := r.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// 匿名のアンケートでは回答者が管理者に分かってしまうためインポートを許可しない
+	if questionnaire.IsAnonymous {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "importing responses is not allowed for anonymous questionnaires")
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to read csv header: %w", err))
+	}
+
+	traqIDIndex, columns, err := r.getImportColumns(ctx, questionnaireID, header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	userSet, err := getUserSet(r.IUser)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:      dryRun,
+		ResponseIDs: []int{},
+		Errors:      []ImportRowError{},
+	}
+	rows := []importRow{}
+	traqIDRows := map[string]int{}
+	for rowNum := 2; ; rowNum++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to read csv row(%d): %w", rowNum, err))
+		}
+		result.Total++
+
+		row, err := r.parseImportRow(ctx, rowNum, record, traqIDIndex, columns, userSet)
+		if err != nil {
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) && httpErr.Code != http.StatusBadRequest {
+				return nil, err
+			}
+			result.Errors = append(result.Errors, newImportRowError(rowNum, err))
+			continue
+		}
+
+		// 回答数に制限のあるアンケートではCSV内の同じユーザーの回答の重複も許可しない
+		if questionnaire.ResponseMode != model.ResponseModeMultiple {
+			if firstRow, ok := traqIDRows[row.traqID]; ok {
+				result.Errors = append(result.Errors, ImportRowError{
+					Row:     rowNum,
+					Message: fmt.Sprintf("the response of %s is already in row %d", row.traqID, firstRow),
+				})
+				continue
+			}
+			traqIDRows[row.traqID] = rowNum
+
+			existingResponseID, err := r.GetSubmittedRespondentID(ctx, row.traqID, questionnaireID)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+			if existingResponseID.Valid {
+				result.Errors = append(result.Errors, newImportExistingRowError(row, int(existingResponseID.Int64)))
+				continue
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if dryRun || len(result.Errors) != 0 {
+		return result, nil
+	}
+
+	// 全ての行を同じトランザクションで追加し，途中で失敗した場合は1行も追加しない
+	err = r.Do(ctx, nil, func(ctx context.Context) error {
+		for _, row := range rows {
+			var existingResponseID int
+			responseID, err := r.audit.RecordCreation(ctx, enteredBy, service.ActionResponseImport, service.TargetResponse, func(ctx context.Context) (int, error) {
+				responseID, err := r.InsertProxyRespondent(ctx, row.traqID, enteredBy, questionnaireID)
+				if err != nil {
+					existingResponseID = responseID
+					return 0, err
+				}
+
+				err = r.InsertResponses(ctx, responseID, createResponseMetas(row.bodies))
+				if err != nil {
+					return 0, fmt.Errorf("failed to insert responses: %w", err)
+				}
+
+				return responseID, nil
+			})
+			if errors.Is(err, model.ErrResponseAlreadyExists) {
+				// 検証の後に回答が追加された
+				result.Errors = append(result.Errors, newImportExistingRowError(row, existingResponseID))
+				return err
+			}
+			if err != nil {
+				return err
+			}
+
+			result.ResponseIDs = append(result.ResponseIDs, responseID)
+		}
+
+		return nil
+	})
+	if errors.Is(err, model.ErrResponseAlreadyExists) {
+		result.ResponseIDs = []int{}
+		return result, nil
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return result, nil
+}
+
+// getImportColumns CSVのヘッダーから回答者の列と質問の列を求める
+func (r *Response) getImportColumns(ctx context.Context, questionnaireID int, header []string, mapping ImportMapping) (int, []importColumn, error) {
+	headerIndexes := make(map[string]int, len(header))
+	for i, name := range header {
+		headerIndexes[strings.TrimSpace(name)] = i
+	}
+
+	traqIDColumn := mapping.TraqIDColumn
+	if traqIDColumn == "" {
+		traqIDColumn = defaultImportTraqIDColumn
+	}
+	traqIDIndex, ok := headerIndexes[traqIDColumn]
+	if !ok {
+		return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("column(%s) is not in the csv", traqIDColumn))
+	}
+
+	questions, err := r.GetQuestions(ctx, questionnaireID)
+	if err != nil {
+		return 0, nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	columns := []importColumn{}
+	if len(mapping.Questions) == 0 {
+		for _, question := range questions {
+			index, ok := headerIndexes[strings.TrimSpace(question.Body)]
+			if !ok || index == traqIDIndex {
+				continue
+			}
+			columns = append(columns, importColumn{
+				index:    index,
+				question: question,
+			})
+		}
+	} else {
+		questionMap := make(map[int]model.Questions, len(questions))
+		for _, question := range questions {
+			questionMap[question.ID] = question
+		}
+
+		mappedQuestionIDs := make(map[int]struct{}, len(mapping.Questions))
+		for name, questionID := range mapping.Questions {
+			index, ok := headerIndexes[name]
+			if !ok {
+				return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("column(%s) is not in the csv", name))
+			}
+			question, ok := questionMap[questionID]
+			if !ok {
+				return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is not in the questionnaire", questionID))
+			}
+			if _, ok := mappedQuestionIDs[questionID]; ok {
+				return 0, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is mapped to multiple columns", questionID))
+			}
+			mappedQuestionIDs[questionID] = struct{}{}
+			columns = append(columns, importColumn{
+				index:    index,
+				question: question,
+			})
+		}
+	}
+
+	if len(columns) == 0 {
+		return 0, nil, echo.NewHTTPError(http.StatusBadRequest, "no column is mapped to questions")
+	}
+
+	questionIDs := make([]int, 0, len(columns))
+	for _, column := range columns {
+		questionIDs = append(questionIDs, column.question.ID)
+	}
+
+	options, err := r.GetOptions(ctx, questionIDs)
+	if err != nil {
+		return 0, nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	optionMap := map[int]map[string]struct{}{}
+	for _, option := range options {
+		if _, ok := optionMap[option.QuestionID]; !ok {
+			optionMap[option.QuestionID] = map[string]struct{}{}
+		}
+		optionMap[option.QuestionID][option.Body] = struct{}{}
+	}
+	for i := range columns {
+		columns[i].options = optionMap[columns[i].question.ID]
+	}
+
+	return traqIDIndex, columns, nil
+}
+
+// parseImportRow CSVの1行を回答に変換してPostProxyResponseと同じ検証を行う
+// userSetには凍結されていないtraQのユーザーのtraQIDの集合を渡す
+func (r *Response) parseImportRow(ctx context.Context, rowNum int, record []string, traqIDIndex int, columns []importColumn, userSet map[string]struct{}) (importRow, error) {
+	row := importRow{
+		row: rowNum,
+	}
+
+	if traqIDIndex < len(record) {
+		row.traqID = strings.TrimSpace(record[traqIDIndex])
+	}
+	if err := checkProxyRespondentID(row.traqID); err != nil {
+		return importRow{}, err
+	}
+	if _, ok := userSet[row.traqID]; !ok {
+		return importRow{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("user(%s) does not exist or is suspended", row.traqID))
+	}
+
+	row.bodies = make([]model.ResponseBody, 0, len(columns))
+	for _, column := range columns {
+		value := ""
+		if column.index < len(record) {
+			value = strings.TrimSpace(record[column.index])
+		}
+
+		body := model.ResponseBody{
+			QuestionID:     column.question.ID,
+			QuestionType:   column.question.Type,
+			OptionResponse: []string{},
+		}
+		switch column.question.Type {
+		case "MultipleChoice", "Checkbox", "Dropdown":
+			if value != "" {
+				for _, option := range strings.Split(value, importOptionSeparator) {
+					option = strings.TrimSpace(option)
+					if _, ok := column.options[option]; !ok {
+						return importRow{}, fmt.Errorf("%s is not an option of question(%d)", option, column.question.ID)
+					}
+					body.OptionResponse = append(body.OptionResponse, option)
+				}
+			}
+		default:
+			body.Body = null.NewString(value, value != "")
+		}
+
+		if err := checkResponseType(column.question.Type, body); err != nil {
+			return importRow{}, err
+		}
+
+		row.bodies = append(row.bodies, body)
+	}
+
+	if err := r.validateResponseBodies(ctx, row.bodies); err != nil {
+		return importRow{}, err
+	}
+
+	return row, nil
+}
+
+func newImportExistingRowError(row importRow, existingResponseID int) ImportRowError {
+	return ImportRowError{
+		Row:     row.row,
+		Message: fmt.Sprintf("the response of %s already exists(responseID: %d)", row.traqID, existingResponseID),
+	}
+}
+
+func newImportRowError(rowNum int, err error) ImportRowError {
+	message := err.Error()
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message = fmt.Sprint(httpErr.Message)
+	}
+
+	return ImportRowError{
+		Row:     rowNum,
+		Message: message,
+	}
+}
@@ -0,0 +1,287 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+	"github.com/traPtitech/anke-to/traq"
+)
+
+// GetQuestionnaireInvitations GET /questionnaires/:questionnaireID/invitations
+func (q *Questionnaire) GetQuestionnaireInvitations(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	invitations, err := q.GetInvitations(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// PostInvitation POST /questionnaires/:questionnaireID/invitations
+func (q *Questionnaire) PostInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	req := struct {
+		TraqID string `json:"traqID"`
+		Role   string `json:"role"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to bind request: %w", err))
+	}
+
+	if _, ok := rolePermissions[req.Role]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid role: %s", req.Role))
+	}
+	// グループは承認ができないので招待せず直接管理者に追加する
+	if req.TraqID == "" || req.TraqID == "traP" || traq.IsGroupID(req.TraqID) {
+		return echo.NewHTTPError(http.StatusBadRequest, "only users can be invited")
+	}
+	if err := checkUserIDs(q.IUser, []string{req.TraqID}); err != nil {
+		return err
+	}
+
+	role, err := q.GetQuestionnaireRole(ctx, req.TraqID, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	if role != "" {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s is already an administrator", req.TraqID))
+	}
+
+	var invitationID int
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireInviteAdministrator, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		invitationID, err = q.InsertInvitation(ctx, questionnaireID, req.TraqID, req.Role, userID)
+		return err
+	})
+	if errors.Is(err, model.ErrInvitationExists) {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("%s is already invited", req.TraqID))
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	questionnaire, _, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if err := q.PostMessage(
+		"### アンケート『" + "[" + questionnaire.Title + "](https://anke-to.trap.jp/questionnaires/" +
+			strconv.Itoa(questionnaireID) + ")" + "』の" + roleNames[req.Role] + "に招待されました\n" +
+			"@" + req.TraqID + "\n" +
+			"招待者: @" + userID + "\n" +
+			"anke-toで招待を承認または辞退してください"); err != nil {
+		c.Logger().Error(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"id":              invitationID,
+		"questionnaireID": questionnaireID,
+		"traqID":          req.TraqID,
+		"role":            req.Role,
+		"invited_by":      userID,
+	})
+}
+
+// TransferQuestionnaireOwnership POST /questionnaires/:questionnaireID/transfer
+func (q *Questionnaire) TransferQuestionnaireOwnership(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	req := struct {
+		TraqID string `json:"traqID"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to bind request: %w", err))
+	}
+
+	if req.TraqID == "" || req.TraqID == "traP" || traq.IsGroupID(req.TraqID) {
+		return echo.NewHTTPError(http.StatusBadRequest, "ownership can only be transferred to a user")
+	}
+	if req.TraqID == userID {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot transfer ownership to yourself")
+	}
+	if err := checkUserIDs(q.IUser, []string{req.TraqID}); err != nil {
+		return err
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireTransferOwnership, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return q.TransferOwnership(ctx, questionnaireID, userID, req.TraqID)
+	})
+	if errors.Is(err, model.ErrNoRecordUpdated) {
+		return echo.NewHTTPError(http.StatusForbidden, "only users who are directly owners can transfer ownership")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	questionnaire, _, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if err := q.PostMessage(
+		"### アンケート『" + "[" + questionnaire.Title + "](https://anke-to.trap.jp/questionnaires/" +
+			strconv.Itoa(questionnaireID) + ")" + "』のオーナーが譲渡されました\n" +
+			"@" + userID + " → @" + req.TraqID); err != nil {
+		c.Logger().Error(err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetMyInvitations GET /users/me/invitations
+func (u *User) GetMyInvitations(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	invitations, err := u.GetUserInvitations(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptMyInvitation POST /users/me/invitations/:invitationID/accept
+func (u *User) AcceptMyInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	invitationID, err := getInvitationID(c)
+	if err != nil {
+		return err
+	}
+
+	questionnaireID, err := u.getInvitationQuestionnaireID(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	var invitation *model.Invitations
+	err = u.audit.Record(ctx, userID, service.ActionQuestionnaireAcceptInvitation, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		invitation, err = u.AcceptInvitation(ctx, userID, invitationID)
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "the invitation does not exist")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	questionnaire, _, _, _, err := u.GetQuestionnaireInfo(ctx, invitation.QuestionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if err := u.PostMessage(
+		"### アンケート『" + "[" + questionnaire.Title + "](https://anke-to.trap.jp/questionnaires/" +
+			strconv.Itoa(invitation.QuestionnaireID) + ")" + "』への招待が承認されました\n" +
+			"@" + invitation.InvitedBy + "\n" +
+			"@" + userID + " が" + roleNames[invitation.Role] + "になりました"); err != nil {
+		c.Logger().Error(err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// DeclineMyInvitation DELETE /users/me/invitations/:invitationID
+func (u *User) DeclineMyInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	invitationID, err := getInvitationID(c)
+	if err != nil {
+		return err
+	}
+
+	questionnaireID, err := u.getInvitationQuestionnaireID(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	err = u.audit.Record(ctx, userID, service.ActionQuestionnaireDeclineInvitation, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return u.DeclineInvitation(ctx, userID, invitationID)
+	})
+	if errors.Is(err, model.ErrNoRecordDeleted) {
+		return echo.NewHTTPError(http.StatusNotFound, "the invitation does not exist")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func getInvitationID(c echo.Context) (int, error) {
+	strInvitationID := c.Param("invitationID")
+	invitationID, err := strconv.Atoi(strInvitationID)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid invitationID:%s(error: %w)", strInvitationID, err))
+	}
+
+	return invitationID, nil
+}
+
+// getInvitationQuestionnaireID ユーザーへの招待のアンケートのID
+func (u *User) getInvitationQuestionnaireID(ctx context.Context, userID string, invitationID int) (int, error) {
+	invitations, err := u.GetUserInvitations(ctx, userID)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	for _, invitation := range invitations {
+		if invitation.ID == invitationID {
+			return invitation.QuestionnaireID, nil
+		}
+	}
+
+	return 0, echo.NewHTTPError(http.StatusNotFound, "the invitation does not exist")
+}
@@ -0,0 +1,294 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+	"github.com/traPtitech/anke-to/traq"
+)
+
+// fakeInvitation テスト用のメモリ上のmodel.IInvitationの実装
+type fakeInvitation struct {
+	invitations []model.Invitations
+}
+
+func (f *fakeInvitation) InsertInvitation(ctx context.Context, questionnaireID int, userID string, role string, invitedBy string) (int, error) {
+	for _, invitation := range f.invitations {
+		if invitation.QuestionnaireID == questionnaireID && invitation.UserTraqid == userID {
+			return 0, fmt.Errorf("failed to insert an invitation: %w", model.ErrInvitationExists)
+		}
+	}
+
+	invitationID := len(f.invitations) + 1
+	f.invitations = append(f.invitations, model.Invitations{
+		ID:              invitationID,
+		QuestionnaireID: questionnaireID,
+		UserTraqid:      userID,
+		Role:            role,
+		InvitedBy:       invitedBy,
+	})
+
+	return invitationID, nil
+}
+
+func (f *fakeInvitation) GetInvitations(ctx context.Context, questionnaireID int) ([]model.Invitations, error) {
+	invitations := []model.Invitations{}
+	for _, invitation := range f.invitations {
+		if invitation.QuestionnaireID == questionnaireID {
+			invitations = append(invitations, invitation)
+		}
+	}
+
+	return invitations, nil
+}
+
+func (f *fakeInvitation) GetUserInvitations(ctx context.Context, userID string) ([]model.InvitationInfo, error) {
+	invitations := []model.InvitationInfo{}
+	for _, invitation := range f.invitations {
+		if invitation.UserTraqid == userID {
+			invitations = append(invitations, model.InvitationInfo{Invitations: invitation})
+		}
+	}
+
+	return invitations, nil
+}
+
+func (f *fakeInvitation) AcceptInvitation(ctx context.Context, userID string, invitationID int) (*model.Invitations, error) {
+	for i, invitation := range f.invitations {
+		if invitation.ID == invitationID && invitation.UserTraqid == userID {
+			f.invitations = append(f.invitations[:i], f.invitations[i+1:]...)
+			return &invitation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get an invitation: %w", gorm.ErrRecordNotFound)
+}
+
+func (f *fakeInvitation) DeclineInvitation(ctx context.Context, userID string, invitationID int) error {
+	for i, invitation := range f.invitations {
+		if invitation.ID == invitationID && invitation.UserTraqid == userID {
+			f.invitations = append(f.invitations[:i], f.invitations[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to delete an invitation: %w", model.ErrNoRecordDeleted)
+}
+
+// fakeQuestionnaireInfo GetQuestionnaireInfoのみを実装したテスト用のmodel.IQuestionnaire
+type fakeQuestionnaireInfo struct {
+	model.IQuestionnaire
+}
+
+func (*fakeQuestionnaireInfo) GetQuestionnaireInfo(ctx context.Context, questionnaireID int) (*model.Questionnaires, []string, []string, []string, error) {
+	return &model.Questionnaires{
+		ID:    questionnaireID,
+		Title: "第1回集会らん☆ぷろ募集アンケート",
+	}, []string{}, []string{}, []string{}, nil
+}
+
+// fakeWebhook 送ったメッセージを記録するテスト用のtraq.IWebhookの実装
+type fakeWebhook struct {
+	messages []string
+}
+
+func (f *fakeWebhook) PostMessage(message string) error {
+	f.messages = append(f.messages, message)
+
+	return nil
+}
+
+func newInvitationContext(e *echo.Echo, userID string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(userIDKey, userID)
+	c.Set(questionnaireIDKey, 1)
+
+	return c, rec
+}
+
+func getStatusCode(err error, rec *httptest.ResponseRecorder) int {
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		return httpErr.Code
+	}
+
+	return rec.Code
+}
+
+func TestPostInvitation(t *testing.T) {
+	t.Parallel()
+
+	user := newFakeUser()
+	user.users = append(user.users, traq.Users{TraqID: "xxarupakaxx"})
+	webhook := &fakeWebhook{}
+	administrator := newFakeAdministrator()
+	invitation := &fakeInvitation{}
+	auditLog := &fakeAuditLog{}
+	audit := newFakeAudit(auditLog, &fakeQuestionnaireInfo{}, administrator, invitation, nil)
+	q := NewQuestionnaire(&fakeQuestionnaireInfo{}, nil, administrator, nil, nil, nil, nil, nil, nil, invitation, nil, webhook, user, newFakeGroup(), nil, audit)
+
+	e := echo.New()
+
+	testCases := []struct {
+		description string
+		body        string
+		expectCode  int
+	}{
+		{
+			description: "invalid role",
+			body:        `{"traqID": "xxarupakaxx", "role": "admin"}`,
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			description: "group",
+			body:        `{"traqID": "` + fakeGroupID1 + `", "role": "viewer"}`,
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			description: "unknown user",
+			body:        `{"traqID": "unknown_user", "role": "viewer"}`,
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			description: "already an administrator",
+			body:        `{"traqID": "mds_boy", "role": "viewer"}`,
+			expectCode:  http.StatusConflict,
+		},
+		{
+			description: "valid",
+			body:        `{"traqID": "xxarupakaxx", "role": "viewer"}`,
+			expectCode:  http.StatusCreated,
+		},
+		{
+			description: "already invited",
+			body:        `{"traqID": "xxarupakaxx", "role": "editor"}`,
+			expectCode:  http.StatusConflict,
+		},
+	}
+
+	for _, testCase := range testCases {
+		c, rec := newInvitationContext(e, "mazrean", testCase.body)
+		err := q.PostInvitation(c)
+		assert.Equal(t, testCase.expectCode, getStatusCode(err, rec), testCase.description)
+	}
+
+	if assert.Len(t, webhook.messages, 1) {
+		assert.Contains(t, webhook.messages[0], "@xxarupakaxx")
+		assert.Contains(t, webhook.messages[0], "閲覧者")
+	}
+
+	if assert.Len(t, auditLog.auditLogs, 1) {
+		assert.Equal(t, "mazrean", auditLog.auditLogs[0].Actor)
+		assert.Equal(t, service.ActionQuestionnaireInviteAdministrator, auditLog.auditLogs[0].Action)
+		assert.NotContains(t, auditLog.auditLogs[0].Before.String, "xxarupakaxx")
+		assert.Contains(t, auditLog.auditLogs[0].After.String, "xxarupakaxx")
+	}
+}
+
+func TestTransferQuestionnaireOwnership(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	administrator := newFakeAdministrator()
+	webhook := &fakeWebhook{}
+	invitation := &fakeInvitation{}
+	auditLog := &fakeAuditLog{}
+	audit := newFakeAudit(auditLog, &fakeQuestionnaireInfo{}, administrator, invitation, nil)
+	q := NewQuestionnaire(&fakeQuestionnaireInfo{}, nil, administrator, nil, nil, nil, nil, nil, nil, invitation, nil, webhook, newFakeUser(), newFakeGroup(), nil, audit)
+
+	e := echo.New()
+
+	c, rec := newInvitationContext(e, "mazrean", `{"traqID": "mazrean"}`)
+	err := q.TransferQuestionnaireOwnership(c)
+	assert.Equal(t, http.StatusBadRequest, getStatusCode(err, rec), "transfer to yourself")
+
+	c, rec = newInvitationContext(e, "mazrean", `{"traqID": "mds_boy"}`)
+	err = q.TransferQuestionnaireOwnership(c)
+	assert.Equal(t, http.StatusOK, getStatusCode(err, rec))
+
+	role, err := administrator.GetQuestionnaireRole(ctx, "mds_boy", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleOwner, role)
+	role, err = administrator.GetQuestionnaireRole(ctx, "mazrean", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleEditor, role, "the previous owner becomes an editor")
+	assert.Len(t, webhook.messages, 1)
+
+	c, rec = newInvitationContext(e, "mazrean", `{"traqID": "mds_boy"}`)
+	err = q.TransferQuestionnaireOwnership(c)
+	assert.Equal(t, http.StatusForbidden, getStatusCode(err, rec), "editors cannot transfer ownership")
+
+	if assert.Len(t, auditLog.auditLogs, 1, "failed operations are not recorded") {
+		assert.Equal(t, service.ActionQuestionnaireTransferOwnership, auditLog.auditLogs[0].Action)
+		assert.NotEqual(t, auditLog.auditLogs[0].Before, auditLog.auditLogs[0].After)
+	}
+}
+
+func TestAcceptAndDeclineMyInvitation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	invitation := &fakeInvitation{}
+	_, err := invitation.InsertInvitation(ctx, 1, "xxarupakaxx", model.RoleEditor, "mazrean")
+	assert.NoError(t, err)
+	_, err = invitation.InsertInvitation(ctx, 2, "xxarupakaxx", model.RoleViewer, "mazrean")
+	assert.NoError(t, err)
+
+	webhook := &fakeWebhook{}
+	administrator := newFakeAdministrator()
+	auditLog := &fakeAuditLog{}
+	audit := newFakeAudit(auditLog, &fakeQuestionnaireInfo{}, administrator, invitation, nil)
+	u := NewUser(nil, &fakeQuestionnaireInfo{}, nil, administrator, nil, invitation, nil, nil, webhook, audit)
+
+	e := echo.New()
+	newContext := func(userID string, invitationID string) (echo.Context, *httptest.ResponseRecorder) {
+		c, rec := newInvitationContext(e, userID, "")
+		c.SetParamNames("invitationID")
+		c.SetParamValues(invitationID)
+		return c, rec
+	}
+
+	c, rec := newContext("mds_boy", "1")
+	err = u.AcceptMyInvitation(c)
+	assert.Equal(t, http.StatusNotFound, getStatusCode(err, rec), "invitation for another user")
+
+	c, rec = newContext("xxarupakaxx", "1")
+	err = u.AcceptMyInvitation(c)
+	assert.Equal(t, http.StatusOK, getStatusCode(err, rec))
+	if assert.Len(t, webhook.messages, 1) {
+		assert.Contains(t, webhook.messages[0], "@mazrean", "the inviter is notified")
+	}
+
+	c, rec = newContext("xxarupakaxx", "2")
+	err = u.DeclineMyInvitation(c)
+	assert.Equal(t, http.StatusOK, getStatusCode(err, rec))
+
+	c, rec = newContext("xxarupakaxx", "2")
+	err = u.DeclineMyInvitation(c)
+	assert.Equal(t, http.StatusNotFound, getStatusCode(err, rec), "already declined")
+
+	c, rec = newContext("xxarupakaxx", "invalid")
+	err = u.DeclineMyInvitation(c)
+	assert.Equal(t, http.StatusBadRequest, getStatusCode(err, rec))
+
+	if assert.Len(t, auditLog.auditLogs, 2) {
+		assert.Equal(t, service.ActionQuestionnaireAcceptInvitation, auditLog.auditLogs[0].Action)
+		assert.Equal(t, 1, auditLog.auditLogs[0].TargetID)
+		assert.Equal(t, service.ActionQuestionnaireDeclineInvitation, auditLog.auditLogs[1].Action)
+		assert.Equal(t, 2, auditLog.auditLogs[1].TargetID)
+	}
+}
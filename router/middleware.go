@@ -1,28 +1,57 @@
 package router
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
+	"github.com/traPtitech/anke-to/config"
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/traq"
 )
 
 // Middleware Middlewareの構造体
 type Middleware struct {
 	model.IAdministrator
+	model.IQuestionnaire
 	model.IRespondent
 	model.IQuestion
+	model.IIdempotencyKey
+	model.IAPIToken
+	model.IShareLink
+	traq.IGroup
+	Authenticator
+	shareLinkSigner *ShareLinkSigner
+	rateLimiters    *RateLimiters
+	// idempotencyKeyWindow Idempotency-Keyを保持する期間
+	idempotencyKeyWindow time.Duration
 }
 
 // NewMiddleware Middlewareのコンストラクタ
-func NewMiddleware(administrator model.IAdministrator, respondent model.IRespondent, question model.IQuestion) *Middleware {
+func NewMiddleware(administrator model.IAdministrator, questionnaire model.IQuestionnaire, respondent model.IRespondent, question model.IQuestion, idempotencyKey model.IIdempotencyKey, apiToken model.IAPIToken, shareLink model.IShareLink, group traq.IGroup, authenticator Authenticator, shareLinkSigner *ShareLinkSigner, rateLimiters *RateLimiters, idempotencyKeyConfig config.IdempotencyKeyConfig) *Middleware {
 	return &Middleware{
-		IAdministrator: administrator,
-		IRespondent:    respondent,
-		IQuestion:      question,
+		IAdministrator:       administrator,
+		IQuestionnaire:       questionnaire,
+		IRespondent:          respondent,
+		IQuestion:            question,
+		IIdempotencyKey:      idempotencyKey,
+		IAPIToken:            apiToken,
+		IShareLink:           shareLink,
+		IGroup:               group,
+		Authenticator:        authenticator,
+		shareLinkSigner:      shareLinkSigner,
+		rateLimiters:         rateLimiters,
+		idempotencyKeyWindow: time.Duration(idempotencyKeyConfig.WindowHours) * time.Hour,
 	}
 }
 
@@ -31,6 +60,9 @@ const (
 	questionnaireIDKey = "questionnaireID"
 	responseIDKey      = "responseID"
 	questionIDKey      = "questionID"
+	roleKey            = "role"
+	apiTokenScopesKey  = "apiTokenScopes"
+	shareLinkIDKey     = "shareLinkID"
 )
 
 /* 消せないアンケートの発生を防ぐための管理者
@@ -38,17 +70,20 @@ const (
 var adminUserIDs = []string{"temma", "sappi_red", "ryoha", "mazrean", "YumizSui", "pure_white_404"}
 
 // UserAuthenticate traPのメンバーかの認証
-func (*Middleware) UserAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
+// Authorizationヘッダーがある場合はAPIトークンで認証する
+func (m *Middleware) UserAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		userID := c.Request().Header.Get("X-Showcase-User")
-		if userID == "" {
-			userID = "mds_boy"
+		if authorization := c.Request().Header.Get(echo.HeaderAuthorization); authorization != "" {
+			return m.apiTokenAuthenticate(c, authorization, next)
 		}
 
-		// トークンを持たないユーザはアクセスできない
-		if userID == "-" {
+		userID, err := m.Authenticate(c)
+		if errors.Is(err, ErrUnauthenticated) {
 			return echo.NewHTTPError(http.StatusUnauthorized, "You are not logged in")
 		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to authenticate: %w", err))
+		}
 
 		c.Set(userIDKey, userID)
 
@@ -56,44 +91,185 @@ func (*Middleware) UserAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-// QuestionnaireAdministratorAuthenticate アンケートの管理者かどうかの認証
-func (m *Middleware) QuestionnaireAdministratorAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
+const bearerPrefix = "Bearer "
+
+// apiTokenAuthenticate Authorization: BearerヘッダーのAPIトークンによる認証
+func (m *Middleware) apiTokenAuthenticate(c echo.Context, authorization string, next echo.HandlerFunc) error {
+	ctx := c.Request().Context()
+
+	if len(authorization) <= len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid Authorization header")
+	}
+
+	apiToken, err := m.GetAPITokenByToken(ctx, authorization[len(bearerPrefix):])
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired api token")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if err := m.UpdateAPITokenLastUsedAt(ctx, apiToken.ID); err != nil {
+		c.Logger().Error(err)
+	}
+
+	c.Set(userIDKey, apiToken.UserTraqid)
+	c.Set(apiTokenScopesKey, apiToken.GetScopes())
+
+	return next(c)
+}
+
+// RequireScope APIトークンでのリクエストの場合にいずれかのスコープを持つかの確認
+// APIトークンを使わないリクエストは全ての操作ができる
+func (*Middleware) RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenScopes, ok := getAPITokenScopes(c)
+			if !ok {
+				return next(c)
+			}
+
+			for _, tokenScope := range tokenScopes {
+				for _, scope := range scopes {
+					if tokenScope == scope {
+						return next(c)
+					}
+				}
+			}
+
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("the api token requires one of the scopes: %s", strings.Join(scopes, ", ")))
+		}
+	}
+}
+
+// RejectAPIToken APIトークンでのリクエストの拒否
+// APIトークンの管理など，ログインしたユーザーのみができる操作に使う
+func (*Middleware) RejectAPIToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := getAPITokenScopes(c); ok {
+			return echo.NewHTTPError(http.StatusForbidden, "this endpoint cannot be used with api tokens")
+		}
+
+		return next(c)
+	}
+}
+
+// SystemAdministratorAuthenticate anke-to全体の管理者かどうかの認証
+func (*Middleware) SystemAdministratorAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		userID, err := getUserID(c)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 		}
 
-		strQuestionnaireID := c.Param("questionnaireID")
-		questionnaireID, err := strconv.Atoi(strQuestionnaireID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid questionnaireID:%s(error: %w)", strQuestionnaireID, err))
-		}
-
 		for _, adminID := range adminUserIDs {
 			if userID == adminID {
-				c.Set(questionnaireIDKey, questionnaireID)
-
 				return next(c)
 			}
 		}
-		isAdmin, err := m.CheckQuestionnaireAdmin(userID, questionnaireID)
+
+		return echo.NewHTTPError(http.StatusForbidden, "only system administrators can access this endpoint")
+	}
+}
+
+// ShareLinkAuthenticate 共有リンクのトークンの検証
+// traQのアカウントがない回答者が回答するためのエンドポイントのみに使う
+func (m *Middleware) ShareLinkAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		shareLinkID, err := m.shareLinkSigner.Verify(c.Param("token"))
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
+			return echo.NewHTTPError(http.StatusNotFound, "the share link does not exist")
 		}
-		if !isAdmin {
-			return c.String(http.StatusForbidden, "You are not a administrator of this questionnaire.")
+
+		shareLink, err := m.GetShareLink(ctx, shareLinkID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "the share link does not exist")
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if !shareLink.IsAvailable(time.Now()) {
+			return echo.NewHTTPError(http.StatusGone, "the share link has expired or been revoked")
 		}
 
-		c.Set(questionnaireIDKey, questionnaireID)
+		c.Set(questionnaireIDKey, shareLink.QuestionnaireID)
+		c.Set(shareLinkIDKey, shareLink.ID)
 
 		return next(c)
 	}
 }
 
+// QuestionnairePermission アンケートの管理者の役割で操作が許可されているかの認証
+// ゴミ箱に移動されたアンケートは404
+func (m *Middleware) QuestionnairePermission(permission string) echo.MiddlewareFunc {
+	return m.questionnairePermission(permission, false)
+}
+
+// DeletedQuestionnairePermission ゴミ箱に移動されたアンケートも対象にするQuestionnairePermission
+// アンケートの復元にのみ使う
+func (m *Middleware) DeletedQuestionnairePermission(permission string) echo.MiddlewareFunc {
+	return m.questionnairePermission(permission, true)
+}
+
+func (m *Middleware) questionnairePermission(permission string, allowDeleted bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			userID, err := getUserID(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+			}
+
+			strQuestionnaireID := c.Param("questionnaireID")
+			questionnaireID, err := strconv.Atoi(strQuestionnaireID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid questionnaireID:%s(error: %w)", strQuestionnaireID, err))
+			}
+
+			isDeleted, err := m.CheckQuestionnaireDeleted(ctx, questionnaireID)
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && isDeleted && !allowDeleted) {
+				return echo.NewHTTPError(http.StatusNotFound, "the questionnaire does not exist")
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if the questionnaire is deleted: %w", err))
+			}
+
+			for _, adminID := range adminUserIDs {
+				if userID == adminID {
+					c.Set(questionnaireIDKey, questionnaireID)
+					c.Set(roleKey, model.RoleOwner)
+
+					return next(c)
+				}
+			}
+			role, err := getQuestionnaireRole(ctx, m.IAdministrator, m.IGroup, userID, questionnaireID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
+			}
+			if role == "" {
+				return c.String(http.StatusForbidden, "You are not a administrator of this questionnaire.")
+			}
+			if !hasPermission(role, permission) {
+				return c.String(http.StatusForbidden, fmt.Sprintf("The %s of this questionnaire is not allowed to do this.", role))
+			}
+
+			c.Set(questionnaireIDKey, questionnaireID)
+			c.Set(roleKey, role)
+
+			return next(c)
+		}
+	}
+}
+
 // RespondentAuthenticate 回答者かどうかの認証
 func (m *Middleware) RespondentAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
 		userID, err := getUserID(c)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
@@ -105,7 +281,7 @@ func (m *Middleware) RespondentAuthenticate(next echo.HandlerFunc) echo.HandlerF
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid responseID:%s(error: %w)", strResponseID, err))
 		}
 
-		isRespondent, err := m.CheckRespondentByResponseID(userID, responseID)
+		isRespondent, err := m.CheckRespondentByResponseID(ctx, userID, responseID)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are a respondent: %w", err))
 		}
@@ -119,9 +295,11 @@ func (m *Middleware) RespondentAuthenticate(next echo.HandlerFunc) echo.HandlerF
 	}
 }
 
-// QuestionAdministratorAuthenticate アンケートの管理者かどうかの認証
+// QuestionAdministratorAuthenticate 質問を編集できるアンケートの管理者かどうかの認証
 func (m *Middleware) QuestionAdministratorAuthenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
 		userID, err := getUserID(c)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
@@ -133,6 +311,22 @@ func (m *Middleware) QuestionAdministratorAuthenticate(next echo.HandlerFunc) ec
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid questionID:%s(error: %w)", strQuestionID, err))
 		}
 
+		question, err := m.GetQuestion(ctx, questionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "the question does not exist")
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get question: %w", err))
+		}
+
+		isDeleted, err := m.CheckQuestionnaireDeleted(ctx, question.QuestionnaireID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && isDeleted) {
+			return echo.NewHTTPError(http.StatusNotFound, "the questionnaire does not exist")
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if the questionnaire is deleted: %w", err))
+		}
+
 		for _, adminID := range adminUserIDs {
 			if userID == adminID {
 				c.Set(questionIDKey, questionID)
@@ -140,10 +334,16 @@ func (m *Middleware) QuestionAdministratorAuthenticate(next echo.HandlerFunc) ec
 				return next(c)
 			}
 		}
-		isAdmin, err := m.CheckQuestionAdmin(userID, questionID)
+		isAdmin, err := m.CheckQuestionAdmin(ctx, userID, questionID)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
 		}
+		if !isAdmin {
+			isAdmin, err = checkQuestionnairePermission(ctx, m.IAdministrator, m.IGroup, userID, question.QuestionnaireID, PermissionEdit)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
+			}
+		}
 		if !isAdmin {
 			return c.String(http.StatusForbidden, "You are not a administrator of this questionnaire.")
 		}
@@ -154,6 +354,105 @@ func (m *Middleware) QuestionAdministratorAuthenticate(next echo.HandlerFunc) ec
 	}
 }
 
+// IdempotencyKey Idempotency-Keyヘッダーが同じリクエストの再送では最初のレスポンスを返す
+func (m *Middleware) IdempotencyKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		key := c.Request().Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+		}
+
+		userID, err := getUserID(c)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+		}
+
+		body, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err))
+		}
+		c.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// 同じキーで別のリクエストが送られてきた場合を区別する
+		hash := sha256.New()
+		hash.Write([]byte(c.Request().Method + " " + c.Request().URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		idempotencyKey, err := m.GetIdempotencyKey(ctx, userID, key)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		if err == nil {
+			switch {
+			case idempotencyKey.ExpiresAt.Before(time.Now()):
+				if err := m.DeleteIdempotencyKey(ctx, userID, key); err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, err)
+				}
+			case idempotencyKey.RequestHash != requestHash:
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("%s is already used for another request", idempotencyKeyHeader))
+			case !idempotencyKey.StatusCode.Valid:
+				return echo.NewHTTPError(http.StatusConflict, "the request with the same idempotency key is in progress")
+			default:
+				c.Response().Header().Set(idempotentReplayedHeader, "true")
+				return c.Blob(int(idempotencyKey.StatusCode.Int64), echo.MIMEApplicationJSONCharsetUTF8, []byte(idempotencyKey.ResponseBody.String))
+			}
+		}
+
+		err = m.InsertIdempotencyKey(ctx, userID, key, requestHash, time.Now().Add(m.idempotencyKeyWindow))
+		if errors.Is(err, model.ErrIdempotencyKeyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "the request with the same idempotency key is in progress")
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		responseBody := new(bytes.Buffer)
+		writer := c.Response().Writer
+		c.Response().Writer = &bodyDumpResponseWriter{
+			Writer:         io.MultiWriter(writer, responseBody),
+			ResponseWriter: writer,
+		}
+		err = next(c)
+		c.Response().Writer = writer
+
+		// 失敗したリクエストは再送できるようにキーを消す
+		status := c.Response().Status
+		if err != nil || status < 200 || status >= 300 {
+			if err := m.DeleteIdempotencyKey(ctx, userID, key); err != nil {
+				c.Logger().Error(err)
+			}
+			return err
+		}
+
+		if err := m.UpdateIdempotencyKeyResponse(ctx, userID, key, status, responseBody.String()); err != nil {
+			c.Logger().Error(err)
+		}
+
+		return nil
+	}
+}
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 64
+)
+
+type bodyDumpResponseWriter struct {
+	io.Writer
+	http.ResponseWriter
+}
+
+func (w *bodyDumpResponseWriter) Write(b []byte) (int, error) {
+	return w.Writer.Write(b)
+}
+
 func getUserID(c echo.Context) (string, error) {
 	rowUserID := c.Get(userIDKey)
 	userID, ok := rowUserID.(string)
@@ -164,6 +463,13 @@ func getUserID(c echo.Context) (string, error) {
 	return userID, nil
 }
 
+// getAPITokenScopes APIトークンでのリクエストの場合にそのスコープを返す
+func getAPITokenScopes(c echo.Context) ([]string, bool) {
+	scopes, ok := c.Get(apiTokenScopesKey).([]string)
+
+	return scopes, ok
+}
+
 func getQuestionnaireID(c echo.Context) (int, error) {
 	rowQuestionnaireID := c.Get(questionnaireIDKey)
 	questionnaireID, ok := rowQuestionnaireID.(int)
@@ -174,6 +480,28 @@ func getQuestionnaireID(c echo.Context) (int, error) {
 	return questionnaireID, nil
 }
 
+// getRole QuestionnairePermissionで確認したアンケートでの役割の取得
+func getRole(c echo.Context) (string, error) {
+	rowRole := c.Get(roleKey)
+	role, ok := rowRole.(string)
+	if !ok {
+		return "", errors.New("invalid context role")
+	}
+
+	return role, nil
+}
+
+// getShareLinkID ShareLinkAuthenticateで確認した共有リンクのIDの取得
+func getShareLinkID(c echo.Context) (int, error) {
+	rowShareLinkID := c.Get(shareLinkIDKey)
+	shareLinkID, ok := rowShareLinkID.(int)
+	if !ok {
+		return 0, errors.New("invalid context shareLinkID")
+	}
+
+	return shareLinkID, nil
+}
+
 func getResponseID(c echo.Context) (int, error) {
 	rowResponseID := c.Get(responseIDKey)
 	questionnaireID, ok := rowResponseID.(int)
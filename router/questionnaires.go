@@ -1,9 +1,11 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
 	"github.com/traPtitech/anke-to/traq"
 )
 
@@ -26,25 +29,43 @@ type Questionnaire struct {
 	model.IOption
 	model.IScaleLabel
 	model.IValidation
+	model.IRevision
+	model.IRespondent
+	model.IInvitation
+	model.IShareLink
 	traq.IWebhook
+	traq.IUser
+	traq.IGroup
+	shareLinkSigner *ShareLinkSigner
+	audit           *service.Audit
 }
 
 // NewQuestionnaire Questionnaireのコンストラクタ
-func NewQuestionnaire(questionnaire model.IQuestionnaire, target model.ITarget, administrator model.IAdministrator, question model.IQuestion, option model.IOption, scaleLabel model.IScaleLabel, validation model.IValidation, webhook traq.IWebhook) *Questionnaire {
+func NewQuestionnaire(questionnaire model.IQuestionnaire, target model.ITarget, administrator model.IAdministrator, question model.IQuestion, option model.IOption, scaleLabel model.IScaleLabel, validation model.IValidation, revision model.IRevision, respondent model.IRespondent, invitation model.IInvitation, shareLink model.IShareLink, webhook traq.IWebhook, user traq.IUser, group traq.IGroup, shareLinkSigner *ShareLinkSigner, audit *service.Audit) *Questionnaire {
 	return &Questionnaire{
-		IQuestionnaire: questionnaire,
-		ITarget:        target,
-		IAdministrator: administrator,
-		IQuestion:      question,
-		IOption:        option,
-		IScaleLabel:    scaleLabel,
-		IValidation:    validation,
-		IWebhook:       webhook,
+		IQuestionnaire:  questionnaire,
+		ITarget:         target,
+		IAdministrator:  administrator,
+		IQuestion:       question,
+		IOption:         option,
+		IScaleLabel:     scaleLabel,
+		IValidation:     validation,
+		IRevision:       revision,
+		IRespondent:     respondent,
+		IInvitation:     invitation,
+		IShareLink:      shareLink,
+		IWebhook:        webhook,
+		IUser:           user,
+		IGroup:          group,
+		shareLinkSigner: shareLinkSigner,
+		audit:           audit,
 	}
 }
 
 // GetQuestionnaires GET /questionnaires
 func (q *Questionnaire) GetQuestionnaires(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
@@ -63,7 +84,7 @@ func (q *Questionnaire) GetQuestionnaires(c echo.Context) error {
 	if pageNum <= 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, errors.New("page cannot be less than 0"))
 	}
-	questionnaires, pageMax, err := q.IQuestionnaire.GetQuestionnaires(userID, sort, search, pageNum, c.QueryParam("nontargeted") == "true")
+	questionnaires, pageMax, err := q.IQuestionnaire.GetQuestionnaires(ctx, userID, getUserGroupIDs(c, q.IGroup, userID), sort, search, pageNum, c.QueryParam("nontargeted") == "true")
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusInternalServerError, err)
@@ -81,13 +102,24 @@ func (q *Questionnaire) GetQuestionnaires(c echo.Context) error {
 
 // PostQuestionnaire POST /questionnaires
 func (q *Questionnaire) PostQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	req := struct {
 		Title          string    `json:"title"`
 		Description    string    `json:"description"`
 		ResTimeLimit   null.Time `json:"res_time_limit"`
 		ResSharedTo    string    `json:"res_shared_to"`
+		ResponseMode   string    `json:"response_mode"`
+		IsAnonymous    bool      `json:"is_anonymous"`
 		Targets        []string  `json:"targets"`
 		Administrators []string  `json:"administrators"`
+		Editors        []string  `json:"editors"`
+		Viewers        []string  `json:"viewers"`
 	}{}
 
 	// JSONを構造体につける
@@ -96,17 +128,47 @@ func (q *Questionnaire) PostQuestionnaire(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	lastID, err := q.InsertQuestionnaire(req.Title, req.Description, req.ResTimeLimit, req.ResSharedTo)
-	if err != nil {
+	if req.ResponseMode == "" {
+		req.ResponseMode = model.ResponseModeMultiple
+	}
+	if !isValidResponseMode(req.ResponseMode) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid response_mode: %s", req.ResponseMode))
+	}
+
+	if err := checkRoleDuplication(req.Administrators, req.Editors, req.Viewers); err != nil {
 		return err
 	}
 
-	if err := q.InsertTargets(lastID, req.Targets); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	ids := concatIDs(req.Targets, req.Administrators, req.Editors, req.Viewers)
+	if err := checkUserIDs(q.IUser, ids); err != nil {
+		return err
+	}
+	if err := checkGroupIDs(q.IGroup, ids); err != nil {
+		return err
 	}
 
-	if err := q.InsertAdministrators(lastID, req.Administrators); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	lastID, err := q.audit.RecordCreation(ctx, userID, service.ActionQuestionnaireCreate, service.TargetQuestionnaire, func(ctx context.Context) (int, error) {
+		lastID, err := q.InsertQuestionnaire(ctx, req.Title, req.Description, req.ResTimeLimit, req.ResSharedTo, req.ResponseMode, req.IsAnonymous)
+		if err != nil {
+			return 0, err
+		}
+
+		if err := q.InsertTargets(ctx, lastID, req.Targets); err != nil {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.insertAdministrators(ctx, lastID, req.Administrators, req.Editors, req.Viewers); err != nil {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if _, err := q.InsertRevision(ctx, lastID, userID); err != nil {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return lastID, nil
+	})
+	if err != nil {
+		return toHTTPError(err)
 	}
 
 	timeLimit := "なし"
@@ -114,18 +176,13 @@ func (q *Questionnaire) PostQuestionnaire(c echo.Context) error {
 		timeLimit = req.ResTimeLimit.Time.Local().Format("2006/01/02 15:04")
 	}
 
-	targetsMentionText := "なし"
-	if len(req.Targets) != 0 {
-		targetsMentionText = "@" + strings.Join(req.Targets, " @")
-	}
-
 	if err := q.PostMessage(
 		"### アンケート『" + "[" + req.Title + "](https://anke-to.trap.jp/questionnaires/" +
 			strconv.Itoa(lastID) + ")" + "』が作成されました\n" +
-			"#### 管理者\n" + strings.Join(req.Administrators, ",") + "\n" +
+			"#### 管理者\n" + strings.Join(replaceGroupIDsWithNames(c, q.IGroup, req.Administrators), ",") + "\n" +
 			"#### 説明\n" + req.Description + "\n" +
 			"#### 回答期限\n" + timeLimit + "\n" +
-			"#### 対象者\n" + targetsMentionText + "\n" +
+			"#### 対象者\n" + createTargetsMentionText(replaceGroupIDsWithNames(c, q.IGroup, req.Targets)) + "\n" +
 			"#### 回答リンク\n" +
 			"https://anke-to.trap.jp/responses/new/" + strconv.Itoa(lastID)); err != nil {
 		c.Logger().Error(err)
@@ -140,20 +197,26 @@ func (q *Questionnaire) PostQuestionnaire(c echo.Context) error {
 		"created_at":      time.Now().Format(time.RFC3339),
 		"modified_at":     time.Now().Format(time.RFC3339),
 		"res_shared_to":   req.ResSharedTo,
+		"response_mode":   req.ResponseMode,
+		"is_anonymous":    req.IsAnonymous,
 		"targets":         req.Targets,
 		"administrators":  req.Administrators,
+		"editors":         nonNilIDs(req.Editors),
+		"viewers":         nonNilIDs(req.Viewers),
 	})
 }
 
 // GetQuestionnaire GET /questionnaires/:questionnaireID
 func (q *Questionnaire) GetQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	strQuestionnaireID := c.Param("questionnaireID")
 	questionnaireID, err := strconv.Atoi(strQuestionnaireID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid questionnaireID:%s(error: %w)", strQuestionnaireID, err))
 	}
 
-	questionnaire, targets, administrators, respondents, err := q.GetQuestionnaireInfo(questionnaireID)
+	questionnaire, targets, administrators, respondents, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -161,22 +224,49 @@ func (q *Questionnaire) GetQuestionnaire(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	roleAdministrators, err := q.GetAdministrators(ctx, []int{questionnaireID})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	roleMap := splitAdministratorsByRole(roleAdministrators)[questionnaireID]
+
+	// 匿名のアンケートでは回答者の一覧の代わりに人数のみを返す
+	respondentCount, err := q.GetRespondentCount(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"questionnaireID": questionnaire.ID,
-		"title":           questionnaire.Title,
-		"description":     questionnaire.Description,
-		"res_time_limit":  questionnaire.ResTimeLimit,
-		"created_at":      questionnaire.CreatedAt.Format(time.RFC3339),
-		"modified_at":     questionnaire.ModifiedAt.Format(time.RFC3339),
-		"res_shared_to":   questionnaire.ResSharedTo,
-		"targets":         targets,
-		"administrators":  administrators,
-		"respondents":     respondents,
+		"questionnaireID":  questionnaire.ID,
+		"title":            questionnaire.Title,
+		"description":      questionnaire.Description,
+		"res_time_limit":   questionnaire.ResTimeLimit,
+		"created_at":       questionnaire.CreatedAt.Format(time.RFC3339),
+		"modified_at":      questionnaire.ModifiedAt.Format(time.RFC3339),
+		"res_shared_to":    questionnaire.ResSharedTo,
+		"response_mode":    questionnaire.ResponseMode,
+		"is_anonymous":     questionnaire.IsAnonymous,
+		"closed_at":        questionnaire.ClosedAt,
+		"closed_by":        questionnaire.ClosedBy,
+		"orphaned_at":      questionnaire.OrphanedAt,
+		"targets":          targets,
+		"administrators":   administrators,
+		"editors":          nonNilIDs(roleMap[model.RoleEditor]),
+		"viewers":          nonNilIDs(roleMap[model.RoleViewer]),
+		"respondents":      respondents,
+		"respondent_count": respondentCount,
 	})
 }
 
 // EditQuestionnaire PATCH /questonnaires/:questionnaireID
 func (q *Questionnaire) EditQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	questionnaireID, err := getQuestionnaireID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
@@ -187,8 +277,12 @@ func (q *Questionnaire) EditQuestionnaire(c echo.Context) error {
 		Description    string    `json:"description"`
 		ResTimeLimit   null.Time `json:"res_time_limit"`
 		ResSharedTo    string    `json:"res_shared_to"`
+		ResponseMode   string    `json:"response_mode"`
+		IsAnonymous    bool      `json:"is_anonymous"`
 		Targets        []string  `json:"targets"`
 		Administrators []string  `json:"administrators"`
+		Editors        []string  `json:"editors"`
+		Viewers        []string  `json:"viewers"`
 	}{}
 
 	if err := c.Bind(&req); err != nil {
@@ -200,47 +294,228 @@ func (q *Questionnaire) EditQuestionnaire(c echo.Context) error {
 		req.ResSharedTo = "administrators"
 	}
 
-	if err := q.UpdateQuestionnaire(
-		req.Title, req.Description, req.ResTimeLimit, req.ResSharedTo, questionnaireID); err != nil {
+	// 回答モードを送らない古いクライアントのために省略された場合は変更しない
+	if req.ResponseMode == "" {
+		questionnaire, _, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		req.ResponseMode = questionnaire.ResponseMode
+	}
+	if !isValidResponseMode(req.ResponseMode) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid response_mode: %s", req.ResponseMode))
+	}
+
+	administrators, err := q.GetAdministrators(ctx, []int{questionnaireID})
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
+	roleMap := splitAdministratorsByRole(administrators)[questionnaireID]
+
+	// 編集者と閲覧者を送らない古いクライアントのために省略された場合は変更しない
+	if req.Editors == nil {
+		req.Editors = roleMap[model.RoleEditor]
+	}
+	if req.Viewers == nil {
+		req.Viewers = roleMap[model.RoleViewer]
+	}
+
+	if err := checkRoleDuplication(req.Administrators, req.Editors, req.Viewers); err != nil {
+		return err
+	}
+
+	role, err := getRole(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get role: %w", err))
+	}
+	if !hasPermission(role, PermissionManage) &&
+		(!isSameIDs(req.Administrators, roleMap[model.RoleOwner]) ||
+			!isSameIDs(req.Editors, roleMap[model.RoleEditor]) ||
+			!isSameIDs(req.Viewers, roleMap[model.RoleViewer])) {
+		return echo.NewHTTPError(http.StatusForbidden, "only owners can change administrators")
+	}
 
-	if err := q.DeleteTargets(questionnaireID); err != nil {
+	ids := concatIDs(req.Targets, req.Administrators, req.Editors, req.Viewers)
+	if err := checkUserIDs(q.IUser, ids); err != nil {
+		return err
+	}
+	if err := checkGroupIDs(q.IGroup, ids); err != nil {
+		return err
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireUpdate, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		if err := q.UpdateQuestionnaire(ctx,
+			req.Title, req.Description, req.ResTimeLimit, req.ResSharedTo, req.ResponseMode, req.IsAnonymous, questionnaireID); errors.Is(err, model.ErrAnonymityLocked) || errors.Is(err, model.ErrResponseModeConflict) {
+			return echo.NewHTTPError(http.StatusConflict, err)
+		} else if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.DeleteTargets(ctx, questionnaireID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.InsertTargets(ctx, questionnaireID, req.Targets); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.DeleteAdministrators(ctx, questionnaireID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.insertAdministrators(ctx, questionnaireID, req.Administrators, req.Editors, req.Viewers); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if _, err := q.InsertRevision(ctx, questionnaireID, userID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// DeleteQuestionnaire DELETE /questonnaires/:questionnaireID
+func (q *Questionnaire) DeleteQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	// 復元できるように対象者・管理者は完全に削除されるまで残しておく
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireDelete, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return q.IQuestionnaire.DeleteQuestionnaire(ctx, questionnaireID)
+	})
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.InsertTargets(questionnaireID, req.Targets); err != nil {
+	return c.NoContent(http.StatusOK)
+}
+
+// RestoreQuestionnaire POST /questionnaires/:questionnaireID/restore
+func (q *Questionnaire) RestoreQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireRestore, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return q.IQuestionnaire.RestoreQuestionnaire(ctx, questionnaireID)
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrNoRecordUpdated) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.DeleteAdministrators(questionnaireID); err != nil {
+	return c.NoContent(http.StatusOK)
+}
+
+// CloseQuestionnaire POST /questionnaires/:questionnaireID/close
+func (q *Questionnaire) CloseQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireClose, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return q.IQuestionnaire.CloseQuestionnaire(ctx, questionnaireID, userID)
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrNoRecordUpdated) {
+			return echo.NewHTTPError(http.StatusConflict, "the questionnaire is already closed")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.InsertAdministrators(questionnaireID, req.Administrators); err != nil {
+	questionnaire, targets, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if err := q.PostMessage(
+		"### アンケート『" + "[" + questionnaire.Title + "](https://anke-to.trap.jp/questionnaires/" +
+			strconv.Itoa(questionnaireID) + ")" + "』の回答受付が終了しました\n" +
+			"#### 対象者\n" + createTargetsMentionText(replaceGroupIDsWithNames(c, q.IGroup, targets))); err != nil {
+		c.Logger().Error(err)
+	}
+
 	return c.NoContent(http.StatusOK)
 }
 
-// DeleteQuestionnaire DELETE /questonnaires/:questionnaireID
-func (q *Questionnaire) DeleteQuestionnaire(c echo.Context) error {
+// ReopenQuestionnaire POST /questionnaires/:questionnaireID/reopen
+func (q *Questionnaire) ReopenQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	questionnaireID, err := getQuestionnaireID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
 	}
 
-	if err := q.IQuestionnaire.DeleteQuestionnaire(questionnaireID); err != nil {
+	err = q.audit.Record(ctx, userID, service.ActionQuestionnaireReopen, service.TargetQuestionnaire, questionnaireID, func(ctx context.Context) error {
+		return q.IQuestionnaire.ReopenQuestionnaire(ctx, questionnaireID)
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrNoRecordUpdated) {
+			return echo.NewHTTPError(http.StatusConflict, "the questionnaire is not closed")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.DeleteTargets(questionnaireID); err != nil {
+	questionnaire, targets, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.DeleteAdministrators(questionnaireID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	timeLimit := "なし"
+	if questionnaire.ResTimeLimit.Valid {
+		timeLimit = questionnaire.ResTimeLimit.Time.Local().Format("2006/01/02 15:04")
+	}
+
+	if err := q.PostMessage(
+		"### アンケート『" + "[" + questionnaire.Title + "](https://anke-to.trap.jp/questionnaires/" +
+			strconv.Itoa(questionnaireID) + ")" + "』の回答受付が再開されました\n" +
+			"#### 回答期限\n" + timeLimit + "\n" +
+			"#### 対象者\n" + createTargetsMentionText(replaceGroupIDsWithNames(c, q.IGroup, targets)) + "\n" +
+			"#### 回答リンク\n" +
+			"https://anke-to.trap.jp/responses/new/" + strconv.Itoa(questionnaireID)); err != nil {
+		c.Logger().Error(err)
 	}
 
 	return c.NoContent(http.StatusOK)
@@ -248,41 +523,57 @@ func (q *Questionnaire) DeleteQuestionnaire(c echo.Context) error {
 
 // GetQuestions GET /questionnaires/:questionnaireID/questions
 func (q *Questionnaire) GetQuestions(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	strQuestionnaireID := c.Param("questionnaireID")
 	questionnaireID, err := strconv.Atoi(strQuestionnaireID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid questionnaireID:%s(error: %w)", strQuestionnaireID, err))
 	}
 
-	allquestions, err := q.IQuestion.GetQuestions(questionnaireID)
+	questionInfos, err := q.getQuestionInfos(ctx, questionnaireID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, questionInfos)
+}
+
+// questionInfo 回答画面で使う質問の情報
+type questionInfo struct {
+	QuestionID      int                     `json:"questionID"`
+	PageNum         int                     `json:"page_num"`
+	QuestionNum     int                     `json:"question_num"`
+	QuestionType    string                  `json:"question_type"`
+	Body            string                  `json:"body"`
+	IsRequired      bool                    `json:"is_required"`
+	HasResponses    bool                    `json:"has_responses"`
+	CreatedAt       string                  `json:"created_at"`
+	Options         []string                `json:"options"`
+	FormerOptions   []model.OptionHistories `json:"former_options"`
+	ScaleLabelRight string                  `json:"scale_label_right"`
+	ScaleLabelLeft  string                  `json:"scale_label_left"`
+	ScaleMin        int                     `json:"scale_min"`
+	ScaleMax        int                     `json:"scale_max"`
+	RegexPattern    string                  `json:"regex_pattern"`
+	MinBound        string                  `json:"min_bound"`
+	MaxBound        string                  `json:"max_bound"`
+}
+
+// getQuestionInfos アンケートの質問を選択肢などと合わせて取得
+func (q *Questionnaire) getQuestionInfos(ctx context.Context, questionnaireID int) ([]questionInfo, error) {
+	allquestions, err := q.IQuestion.GetQuestions(ctx, questionnaireID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, err)
+			return nil, echo.NewHTTPError(http.StatusNotFound, err)
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
 	if len(allquestions) == 0 {
-		return echo.NewHTTPError(http.StatusNotFound)
-	}
-
-	type questionInfo struct {
-		QuestionID      int      `json:"questionID"`
-		PageNum         int      `json:"page_num"`
-		QuestionNum     int      `json:"question_num"`
-		QuestionType    string   `json:"question_type"`
-		Body            string   `json:"body"`
-		IsRequired      bool     `json:"is_required"`
-		CreatedAt       string   `json:"created_at"`
-		Options         []string `json:"options"`
-		ScaleLabelRight string   `json:"scale_label_right"`
-		ScaleLabelLeft  string   `json:"scale_label_left"`
-		ScaleMin        int      `json:"scale_min"`
-		ScaleMax        int      `json:"scale_max"`
-		RegexPattern    string   `json:"regex_pattern"`
-		MinBound        string   `json:"min_bound"`
-		MaxBound        string   `json:"max_bound"`
+		return nil, echo.NewHTTPError(http.StatusNotFound)
 	}
+
 	var ret []questionInfo
 
 	optionIDs := []int{}
@@ -299,35 +590,54 @@ func (q *Questionnaire) GetQuestions(c echo.Context) error {
 		}
 	}
 
-	options, err := q.GetOptions(optionIDs)
+	options, err := q.GetOptions(ctx, optionIDs)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 	optionMap := make(map[int][]string, len(options))
 	for _, option := range options {
 		optionMap[option.QuestionID] = append(optionMap[option.QuestionID], option.Body)
 	}
 
-	scaleLabels, err := q.GetScaleLabels(scaleLabelIDs)
+	optionHistories, err := q.GetOptionHistories(ctx, optionIDs)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	optionHistoryMap := make(map[int][]model.OptionHistories, len(optionHistories))
+	for _, optionHistory := range optionHistories {
+		optionHistoryMap[optionHistory.QuestionID] = append(optionHistoryMap[optionHistory.QuestionID], optionHistory)
+	}
+
+	scaleLabels, err := q.GetScaleLabels(ctx, scaleLabelIDs)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 	scaleLabelMap := make(map[int]*model.ScaleLabels, len(scaleLabels))
 	for _, label := range scaleLabels {
 		scaleLabelMap[label.QuestionID] = &label
 	}
 
-	validations, err := q.GetValidations(validationIDs)
+	validations, err := q.GetValidations(ctx, validationIDs)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 	validationMap := make(map[int]*model.Validations, len(validations))
 	for _, validation := range validations {
 		validationMap[validation.QuestionID] = &validation
 	}
 
+	answeredQuestionIDs, err := q.GetAnsweredQuestionIDs(ctx, questionnaireID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	answeredQuestionMap := make(map[int]bool, len(answeredQuestionIDs))
+	for _, answeredQuestionID := range answeredQuestionIDs {
+		answeredQuestionMap[answeredQuestionID] = true
+	}
+
 	for _, v := range allquestions {
 		options := []string{}
+		formerOptions := []model.OptionHistories{}
 		scalelabel := &model.ScaleLabels{}
 		validation := &model.Validations{}
 		switch v.Type {
@@ -337,6 +647,10 @@ func (q *Questionnaire) GetQuestions(c echo.Context) error {
 			if !ok {
 				options = []string{}
 			}
+			formerOptions, ok = optionHistoryMap[v.ID]
+			if !ok {
+				formerOptions = []model.OptionHistories{}
+			}
 		case "LinearScale":
 			var ok bool
 			scalelabel, ok = scaleLabelMap[v.ID]
@@ -359,8 +673,10 @@ func (q *Questionnaire) GetQuestions(c echo.Context) error {
 				QuestionType:    v.Type,
 				Body:            v.Body,
 				IsRequired:      v.IsRequired,
+				HasResponses:    answeredQuestionMap[v.ID],
 				CreatedAt:       v.CreatedAt.Format(time.RFC3339),
 				Options:         options,
+				FormerOptions:   formerOptions,
 				ScaleLabelRight: scalelabel.ScaleLabelRight,
 				ScaleLabelLeft:  scalelabel.ScaleLabelLeft,
 				ScaleMin:        scalelabel.ScaleMin,
@@ -371,5 +687,162 @@ func (q *Questionnaire) GetQuestions(c echo.Context) error {
 			})
 	}
 
-	return c.JSON(http.StatusOK, ret)
+	return ret, nil
+}
+
+// 回答の状況
+const (
+	progressNotStarted = "not_started"
+	progressDraft      = "draft"
+	progressSubmitted  = "submitted"
+	progressEdited     = "edited"
+)
+
+// TargetProgress 対象者ごとの回答の状況の構造体
+type TargetProgress struct {
+	TraqID      string    `json:"traqID"`
+	Status      string    `json:"status"`
+	ModifiedAt  null.Time `json:"modified_at"`
+	SubmittedAt null.Time `json:"submitted_at"`
+}
+
+// insertAdministrators アンケートの管理者を役割ごとに追加
+func (q *Questionnaire) insertAdministrators(ctx context.Context, questionnaireID int, owners []string, editors []string, viewers []string) error {
+	if err := q.InsertAdministrators(ctx, questionnaireID, owners, model.RoleOwner); err != nil {
+		return err
+	}
+	if err := q.InsertAdministrators(ctx, questionnaireID, editors, model.RoleEditor); err != nil {
+		return err
+	}
+
+	return q.InsertAdministrators(ctx, questionnaireID, viewers, model.RoleViewer)
+}
+
+// GetQuestionnaireProgress GET /questionnaires/:questionnaireID/progress
+func (q *Questionnaire) GetQuestionnaireProgress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	questionnaire, targets, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// 匿名のアンケートでは誰が回答したかを返さない
+	if questionnaire.IsAnonymous {
+		return echo.NewHTTPError(http.StatusBadRequest, "the progress of anonymous questionnaires is not available")
+	}
+
+	// 対象者のtraPは全員を，グループのIDはそのメンバーを表す
+	targetSet := make(map[string]struct{}, len(targets))
+	expandedTargets := make([]string, 0, len(targets))
+	addTargets := func(users []string) {
+		for _, user := range users {
+			if _, ok := targetSet[user]; !ok {
+				targetSet[user] = struct{}{}
+				expandedTargets = append(expandedTargets, user)
+			}
+		}
+	}
+	isAllTargeted := false
+	groupIDs := []string{}
+	for _, target := range targets {
+		switch {
+		case target == "traP":
+			isAllTargeted = true
+		case traq.IsGroupID(target):
+			groupIDs = append(groupIDs, target)
+		default:
+			addTargets([]string{target})
+		}
+	}
+	if isAllTargeted {
+		users, err := q.GetUsers()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get users: %w", err))
+		}
+		addTargets(users)
+	}
+	if len(groupIDs) != 0 {
+		members, err := getGroupMembers(q.IGroup, groupIDs)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		addTargets(members)
+	}
+	sort.Strings(expandedTargets)
+
+	progresses, err := q.GetRespondentProgress(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	progressMap := make(map[string]model.RespondentProgress, len(progresses))
+	for _, progress := range progresses {
+		progressMap[progress.UserTraqid] = progress
+	}
+
+	counts := map[string]int{
+		progressNotStarted: 0,
+		progressDraft:      0,
+		progressSubmitted:  0,
+		progressEdited:     0,
+	}
+	targetProgresses := make([]TargetProgress, 0, len(expandedTargets))
+	for _, target := range expandedTargets {
+		targetProgress := TargetProgress{
+			TraqID: target,
+			Status: progressNotStarted,
+		}
+		if progress, ok := progressMap[target]; ok {
+			targetProgress.ModifiedAt = progress.ModifiedAt
+			targetProgress.SubmittedAt = progress.SubmittedAt
+			switch {
+			case progress.EditedCount != 0:
+				targetProgress.Status = progressEdited
+			case progress.SubmittedCount != 0:
+				targetProgress.Status = progressSubmitted
+			case progress.DraftCount != 0:
+				targetProgress.Status = progressDraft
+			}
+		}
+
+		counts[targetProgress.Status]++
+		targetProgresses = append(targetProgresses, targetProgress)
+	}
+
+	completionRate := 0.0
+	if len(expandedTargets) != 0 {
+		completionRate = float64(counts[progressSubmitted]+counts[progressEdited]) / float64(len(expandedTargets))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"questionnaireID": questionnaireID,
+		"target_count":    len(expandedTargets),
+		"counts":          counts,
+		"completion_rate": completionRate,
+		"targets":         targetProgresses,
+	})
+}
+
+func isValidResponseMode(responseMode string) bool {
+	switch responseMode {
+	case model.ResponseModeMultiple, model.ResponseModeOnce, model.ResponseModeOnceWithDraft:
+		return true
+	}
+	return false
+}
+
+func createTargetsMentionText(targets []string) string {
+	if len(targets) == 0 {
+		return "なし"
+	}
+
+	return "@" + strings.Join(targets, " @")
 }
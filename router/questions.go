@@ -1,36 +1,58 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 
+	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
 
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+	"github.com/traPtitech/anke-to/traq"
 )
 
 // Question Questionの構造体
 type Question struct {
 	model.IValidation
+	model.IQuestionnaire
 	model.IQuestion
+	model.IAdministrator
 	model.IOption
 	model.IScaleLabel
+	model.IRevision
+	traq.IGroup
+	audit *service.Audit
 }
 
 // NewQuestion Questionのコンストラクタ
-func NewQuestion(validation model.IValidation, question model.IQuestion, option model.IOption, scaleLabel model.IScaleLabel) *Question {
+func NewQuestion(validation model.IValidation, questionnaire model.IQuestionnaire, question model.IQuestion, administrator model.IAdministrator, option model.IOption, scaleLabel model.IScaleLabel, revision model.IRevision, group traq.IGroup, audit *service.Audit) *Question {
 	return &Question{
-		IValidation: validation,
-		IQuestion:   question,
-		IOption:     option,
-		IScaleLabel: scaleLabel,
+		IValidation:    validation,
+		IQuestionnaire: questionnaire,
+		IQuestion:      question,
+		IAdministrator: administrator,
+		IOption:        option,
+		IScaleLabel:    scaleLabel,
+		IRevision:      revision,
+		IGroup:         group,
+		audit:          audit,
 	}
 }
 
 // PostQuestion POST /questions
 func (q *Question) PostQuestion(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	req := struct {
 		QuestionnaireID int      `json:"questionnaireID"`
 		QuestionType    string   `json:"question_type"`
@@ -53,6 +75,11 @@ func (q *Question) PostQuestion(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
+	err = q.checkQuestionnaireEditable(ctx, userID, req.QuestionnaireID)
+	if err != nil {
+		return err
+	}
+
 	switch req.QuestionType {
 	case "Text":
 		//正規表現のチェック
@@ -67,37 +94,48 @@ func (q *Question) PostQuestion(c echo.Context) error {
 		}
 	}
 
-	lastID, err := q.InsertQuestion(req.QuestionnaireID, req.PageNum, req.QuestionNum, req.QuestionType, req.Body, req.IsRequired)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
-	}
+	lastID, err := q.audit.RecordCreation(ctx, userID, service.ActionQuestionCreate, service.TargetQuestion, func(ctx context.Context) (int, error) {
+		lastID, err := q.InsertQuestion(ctx, req.QuestionnaireID, req.PageNum, req.QuestionNum, req.QuestionType, req.Body, req.IsRequired)
+		if err != nil {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
 
-	switch req.QuestionType {
-	case "MultipleChoice", "Checkbox", "Dropdown":
-		for i, v := range req.Options {
-			if err := q.InsertOption(lastID, i+1, v); err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err)
+		switch req.QuestionType {
+		case "MultipleChoice", "Checkbox", "Dropdown":
+			for i, v := range req.Options {
+				if err := q.InsertOption(ctx, lastID, i+1, v); err != nil {
+					return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+				}
+			}
+		case "LinearScale":
+			if err := q.InsertScaleLabel(ctx, lastID,
+				model.ScaleLabels{
+					ScaleLabelLeft:  req.ScaleLabelLeft,
+					ScaleLabelRight: req.ScaleLabelRight,
+					ScaleMax:        req.ScaleMax,
+					ScaleMin:        req.ScaleMin,
+				}); err != nil {
+				return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+		case "Text", "Number":
+			if err := q.InsertValidation(ctx, lastID,
+				model.Validations{
+					RegexPattern: req.RegexPattern,
+					MinBound:     req.MinBound,
+					MaxBound:     req.MaxBound,
+				}); err != nil {
+				return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
 			}
 		}
-	case "LinearScale":
-		if err := q.InsertScaleLabel(lastID,
-			model.ScaleLabels{
-				ScaleLabelLeft:  req.ScaleLabelLeft,
-				ScaleLabelRight: req.ScaleLabelRight,
-				ScaleMax:        req.ScaleMax,
-				ScaleMin:        req.ScaleMin,
-			}); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err)
-		}
-	case "Text", "Number":
-		if err := q.InsertValidation(lastID,
-			model.Validations{
-				RegexPattern: req.RegexPattern,
-				MinBound:     req.MinBound,
-				MaxBound:     req.MaxBound,
-			}); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err)
+
+		if _, err := q.InsertRevision(ctx, req.QuestionnaireID, userID); err != nil {
+			return 0, echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
+
+		return lastID, nil
+	})
+	if err != nil {
+		return toHTTPError(err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -121,6 +159,13 @@ func (q *Question) PostQuestion(c echo.Context) error {
 
 // EditQuestion PATCH /questions/:id
 func (q *Question) EditQuestion(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	questionID, err := getQuestionID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionID: %w", err))
@@ -162,35 +207,78 @@ func (q *Question) EditQuestion(c echo.Context) error {
 		}
 	}
 
-	if err := q.UpdateQuestion(req.QuestionnaireID, req.PageNum, req.QuestionNum, req.QuestionType, req.Body,
-		req.IsRequired, questionID); err != nil {
+	question, err := q.GetQuestion(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
+	// 質問を別のアンケートに移動することはできない
+	if req.QuestionnaireID != question.QuestionnaireID {
+		return echo.NewHTTPError(http.StatusBadRequest, "questionnaireID does not match the question")
+	}
 
-	switch req.QuestionType {
-	case "MultipleChoice", "Checkbox", "Dropdown":
-		if err := q.UpdateOptions(req.Options, questionID); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+	scaleLabel := model.ScaleLabels{
+		ScaleLabelLeft:  req.ScaleLabelLeft,
+		ScaleLabelRight: req.ScaleLabelRight,
+		ScaleMax:        req.ScaleMax,
+		ScaleMin:        req.ScaleMin,
+	}
+	validation := model.Validations{
+		RegexPattern: req.RegexPattern,
+		MinBound:     req.MinBound,
+		MaxBound:     req.MaxBound,
+	}
+
+	// 回答済みの質問の種類を変えたり範囲を狭めたりすると既存の回答が解釈できなくなったり条件を満たさなくなったりするので許可しない
+	isAnswered, err := q.CheckQuestionAnswered(ctx, questionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	if isAnswered {
+		if question.Type != req.QuestionType {
+			return echo.NewHTTPError(http.StatusConflict, "cannot change the type of a question that already has responses")
+		}
+
+		isNarrowed, err := q.isQuestionRangeNarrowed(ctx, questionID, req.QuestionType, scaleLabel, validation)
+		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
-	case "LinearScale":
-		if err := q.UpdateScaleLabel(questionID,
-			model.ScaleLabels{
-				ScaleLabelLeft:  req.ScaleLabelLeft,
-				ScaleLabelRight: req.ScaleLabelRight,
-				ScaleMax:        req.ScaleMax,
-				ScaleMin:        req.ScaleMin,
-			}); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+		if isNarrowed {
+			return echo.NewHTTPError(http.StatusConflict, "cannot narrow the scale or validation of a question that already has responses")
+		}
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionQuestionUpdate, service.TargetQuestion, questionID, func(ctx context.Context) error {
+		if err := q.UpdateQuestion(ctx, question.QuestionnaireID, req.PageNum, req.QuestionNum, req.QuestionType, req.Body,
+			req.IsRequired, questionID); err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
-	case "Text", "Number":
-		if err := q.UpdateValidation(questionID,
-			model.Validations{
-				RegexPattern: req.RegexPattern,
-				MinBound:     req.MinBound,
-				MaxBound:     req.MaxBound,
-			}); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+
+		switch req.QuestionType {
+		case "MultipleChoice", "Checkbox", "Dropdown":
+			if err := q.UpdateOptions(ctx, req.Options, questionID); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+		case "LinearScale":
+			if err := q.UpdateScaleLabel(ctx, questionID, scaleLabel); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+		case "Text", "Number":
+			if err := q.UpdateValidation(ctx, questionID, validation); err != nil && !errors.Is(err, model.ErrNoRecordUpdated) {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+		}
+
+		if _, err := q.InsertRevision(ctx, question.QuestionnaireID, userID); err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
+
+		return nil
+	})
+	if err != nil {
+		return toHTTPError(err)
 	}
 
 	return c.NoContent(http.StatusOK)
@@ -198,26 +286,144 @@ func (q *Question) EditQuestion(c echo.Context) error {
 
 // DeleteQuestion DELETE /questions/:id
 func (q *Question) DeleteQuestion(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
 	questionID, err := getQuestionID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionID: %w", err))
 	}
 
-	if err := q.IQuestion.DeleteQuestion(questionID); err != nil {
+	question, err := q.GetQuestion(ctx, questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if err := q.DeleteOptions(questionID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	err = q.audit.Record(ctx, userID, service.ActionQuestionDelete, service.TargetQuestion, questionID, func(ctx context.Context) error {
+		if err := q.IQuestion.DeleteQuestion(ctx, questionID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.DeleteOptions(ctx, questionID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.DeleteScaleLabel(ctx, questionID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err := q.DeleteValidation(ctx, questionID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if _, err := q.InsertRevision(ctx, question.QuestionnaireID, userID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return toHTTPError(err)
 	}
 
-	if err := q.DeleteScaleLabel(questionID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	return c.NoContent(http.StatusOK)
+}
+
+// isQuestionRangeNarrowed 目盛りの範囲や回答の検証を狭める変更かどうか
+func (q *Question) isQuestionRangeNarrowed(ctx context.Context, questionID int, questionType string, scaleLabel model.ScaleLabels, validation model.Validations) (bool, error) {
+	switch questionType {
+	case "LinearScale":
+		scaleLabels, err := q.GetScaleLabels(ctx, []int{questionID})
+		if err != nil {
+			return false, err
+		}
+		if len(scaleLabels) == 0 {
+			return false, nil
+		}
+
+		return scaleLabel.ScaleMin > scaleLabels[0].ScaleMin || scaleLabel.ScaleMax < scaleLabels[0].ScaleMax, nil
+	case "Text", "Number":
+		validations, err := q.GetValidations(ctx, []int{questionID})
+		if err != nil {
+			return false, err
+		}
+		// 検証がなければ制限のない状態から変更する
+		before := model.Validations{}
+		if len(validations) != 0 {
+			before = validations[0]
+		}
+
+		return isValidationNarrowed(questionType, before, validation), nil
 	}
 
-	if err := q.DeleteValidation(questionID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	return false, nil
+}
+
+// isValidationNarrowed 回答の検証を狭める変更かどうか (空文字列は制限なし)
+func isValidationNarrowed(questionType string, before model.Validations, after model.Validations) bool {
+	switch questionType {
+	case "Text":
+		// 正規表現の包含関係は判定できないので，制限をなくす以外の変更は全て狭める変更とみなす
+		return after.RegexPattern != "" && after.RegexPattern != before.RegexPattern
+	case "Number":
+		return isBoundNarrowed(before.MinBound, after.MinBound, func(before, after float64) bool { return after > before }) ||
+			isBoundNarrowed(before.MaxBound, after.MaxBound, func(before, after float64) bool { return after < before })
 	}
 
-	return c.NoContent(http.StatusOK)
+	return false
+}
+
+// isBoundNarrowed 下限または上限を狭める変更かどうか
+func isBoundNarrowed(before string, after string, narrower func(before, after float64) bool) bool {
+	if after == "" {
+		return false
+	}
+	if before == "" {
+		return true
+	}
+
+	beforeBound, err := strconv.ParseFloat(before, 64)
+	if err != nil {
+		return after != before
+	}
+	afterBound, err := strconv.ParseFloat(after, 64)
+	if err != nil {
+		return true
+	}
+
+	return narrower(beforeBound, afterBound)
+}
+
+// checkQuestionnaireEditable 質問を追加するアンケートが編集できるか
+// ゴミ箱に移動されたアンケートは404
+func (q *Question) checkQuestionnaireEditable(ctx context.Context, userID string, questionnaireID int) error {
+	isDeleted, err := q.CheckQuestionnaireDeleted(ctx, questionnaireID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && isDeleted) {
+		return echo.NewHTTPError(http.StatusNotFound, "the questionnaire does not exist")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if the questionnaire is deleted: %w", err))
+	}
+
+	for _, adminID := range adminUserIDs {
+		if userID == adminID {
+			return nil
+		}
+	}
+	isAdmin, err := checkQuestionnairePermission(ctx, q.IAdministrator, q.IGroup, userID, questionnaireID, PermissionEdit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
+	}
+	if !isAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "You are not a administrator of this questionnaire.")
+	}
+
+	return nil
 }
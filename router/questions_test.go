@@ -0,0 +1,183 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traPtitech/anke-to/model"
+)
+
+// fakeQuestionInsert InsertQuestionの呼び出しを記録するテスト用のmodel.IQuestion
+type fakeQuestionInsert struct {
+	model.IQuestion
+	questionnaireIDs []int
+}
+
+func (f *fakeQuestionInsert) InsertQuestion(ctx context.Context, questionnaireID int, pageNum int, questionNum int, questionType string, body string, isRequired bool) (int, error) {
+	f.questionnaireIDs = append(f.questionnaireIDs, questionnaireID)
+
+	return 0, errors.New("failed to insert a question")
+}
+
+func TestPostQuestionPermission(t *testing.T) {
+	t.Parallel()
+
+	administrator := newFakeAdministrator()
+	_ = administrator.InsertAdministrators(context.Background(), 3, []string{"mazrean"}, model.RoleOwner)
+	questionnaire := &fakeQuestionnaireDeleted{deleted: map[int]bool{1: false, 3: true}}
+
+	testCases := []struct {
+		description     string
+		userID          string
+		questionnaireID string
+		code            int
+		inserted        bool
+	}{
+		{
+			description:     "編集者は質問を追加できる",
+			userID:          "mds_boy",
+			questionnaireID: "1",
+			code:            http.StatusInternalServerError,
+			inserted:        true,
+		},
+		{
+			description:     "閲覧者は質問を追加できない",
+			userID:          "xxarupakaxx",
+			questionnaireID: "1",
+			code:            http.StatusForbidden,
+		},
+		{
+			description:     "管理者でないと質問を追加できない",
+			userID:          "ryoha_test",
+			questionnaireID: "1",
+			code:            http.StatusForbidden,
+		},
+		{
+			description:     "ゴミ箱のアンケートには質問を追加できない",
+			userID:          "mazrean",
+			questionnaireID: "3",
+			code:            http.StatusNotFound,
+		},
+		{
+			description:     "存在しないアンケートには質問を追加できない",
+			userID:          "mazrean",
+			questionnaireID: "4",
+			code:            http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		question := &fakeQuestionInsert{}
+		audit := newFakeAudit(&fakeAuditLog{}, questionnaire, administrator, nil, nil)
+		q := NewQuestion(nil, questionnaire, question, administrator, nil, nil, nil, newFakeGroup(), audit)
+
+		e := echo.New()
+		body := `{"questionnaireID":` + testCase.questionnaireID + `,"question_type":"Text","body":"質問"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/questions", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(userIDKey, testCase.userID)
+
+		err := q.PostQuestion(c)
+		var httpErr *echo.HTTPError
+		if assert.True(t, errors.As(err, &httpErr), testCase.description) {
+			assert.Equal(t, testCase.code, httpErr.Code, testCase.description)
+		}
+		assert.Equal(t, testCase.inserted, len(question.questionnaireIDs) != 0, testCase.description)
+	}
+}
+
+func TestIsValidationNarrowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description  string
+		questionType string
+		before       model.Validations
+		after        model.Validations
+		expect       bool
+	}{
+		{
+			description:  "same regex",
+			questionType: "Text",
+			before:       model.Validations{RegexPattern: "^[0-9]+$"},
+			after:        model.Validations{RegexPattern: "^[0-9]+$"},
+			expect:       false,
+		},
+		{
+			description:  "remove regex",
+			questionType: "Text",
+			before:       model.Validations{RegexPattern: "^[0-9]+$"},
+			after:        model.Validations{},
+			expect:       false,
+		},
+		{
+			description:  "change regex",
+			questionType: "Text",
+			before:       model.Validations{RegexPattern: "^[0-9]+$"},
+			after:        model.Validations{RegexPattern: "^[0-9]{3}$"},
+			expect:       true,
+		},
+		{
+			description:  "add regex",
+			questionType: "Text",
+			before:       model.Validations{},
+			after:        model.Validations{RegexPattern: "^[0-9]+$"},
+			expect:       true,
+		},
+		{
+			description:  "widen bounds",
+			questionType: "Number",
+			before:       model.Validations{MinBound: "0", MaxBound: "10"},
+			after:        model.Validations{MinBound: "-5", MaxBound: "20"},
+			expect:       false,
+		},
+		{
+			description:  "remove bounds",
+			questionType: "Number",
+			before:       model.Validations{MinBound: "0", MaxBound: "10"},
+			after:        model.Validations{},
+			expect:       false,
+		},
+		{
+			description:  "raise min bound",
+			questionType: "Number",
+			before:       model.Validations{MinBound: "0", MaxBound: "10"},
+			after:        model.Validations{MinBound: "1", MaxBound: "10"},
+			expect:       true,
+		},
+		{
+			description:  "lower max bound",
+			questionType: "Number",
+			before:       model.Validations{MinBound: "0", MaxBound: "10"},
+			after:        model.Validations{MinBound: "0", MaxBound: "9.5"},
+			expect:       true,
+		},
+		{
+			description:  "add max bound",
+			questionType: "Number",
+			before:       model.Validations{MinBound: "0"},
+			after:        model.Validations{MinBound: "0", MaxBound: "10"},
+			expect:       true,
+		},
+		{
+			description:  "regex of number questions is not used",
+			questionType: "Number",
+			before:       model.Validations{},
+			after:        model.Validations{RegexPattern: "^[0-9]+$"},
+			expect:       false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expect, isValidationNarrowed(testCase.questionType, testCase.before, testCase.after), testCase.description)
+	}
+}
@@ -0,0 +1,188 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/traPtitech/anke-to/config"
+)
+
+// RateLimiter キーごとのリクエストの回数制限のinterface
+// 複数のサーバーで共有する場合は共有のストアを使う実装に差し替える
+type RateLimiter interface {
+	// Allow キーのリクエストを1回許可するか判定し，許可しない場合は次に許可されるまでの時間を返す
+	Allow(key string) (bool, time.Duration, error)
+}
+
+const rateLimitSweepInterval = 10 * time.Minute
+
+// RateLimiters 読み込みと書き込みで別々の回数制限
+// nilの場合はその種類のリクエストを制限しない
+type RateLimiters struct {
+	Read  RateLimiter
+	Write RateLimiter
+}
+
+// NewRateLimiters 設定に従ったメモリ上の回数制限のコンストラクタ
+// 1分あたりの回数が0の種類のリクエストは制限しない
+func NewRateLimiters(rateLimitConfig config.RateLimitConfig) *RateLimiters {
+	rateLimiters := &RateLimiters{}
+	if rateLimitConfig.Read.PerMinute != 0 {
+		rateLimiters.Read = NewMemoryRateLimiter(rateLimitConfig.Read)
+	}
+	if rateLimitConfig.Write.PerMinute != 0 {
+		rateLimiters.Write = NewMemoryRateLimiter(rateLimitConfig.Write)
+	}
+
+	return rateLimiters
+}
+
+// MemoryRateLimiter メモリ上のトークンバケットによる回数制限
+// サーバーを再起動するとリセットされ，複数のサーバー間では共有されない
+type MemoryRateLimiter struct {
+	rule      config.RateLimitRule
+	now       func() time.Time
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryRateLimiter MemoryRateLimiterのコンストラクタ
+func NewMemoryRateLimiter(rule config.RateLimitRule) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		rule:      rule,
+		now:       time.Now,
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow トークンが残っていれば1つ消費して許可する
+func (l *MemoryRateLimiter) Allow(key string) (bool, time.Duration, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:    float64(l.rule.Burst),
+			updatedAt: now,
+		}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.updatedAt = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+
+	retryAfter := time.Duration((1 - bucket.tokens) / l.tokensPerSecond() * float64(time.Second))
+
+	return false, retryAfter, nil
+}
+
+func (l *MemoryRateLimiter) tokensPerSecond() float64 {
+	return float64(l.rule.PerMinute) / 60
+}
+
+func (l *MemoryRateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+
+	return math.Min(float64(l.rule.Burst), bucket.tokens+elapsed*l.tokensPerSecond())
+}
+
+// sweep 満タンまで補充されたバケットは新しく作るのと同じなので削除する
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if l.refill(bucket, now) >= float64(l.rule.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit ユーザーとエンドポイントごとのリクエストの回数制限
+// 読み込み (GET, HEAD) と書き込みで別々の制限を使う
+func (m *Middleware) RateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := getUserID(c)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+		}
+
+		return m.limit(c, next, userID)
+	}
+}
+
+// GuestRateLimit 共有リンクとクライアントのIPアドレスごとのリクエストの回数制限
+// トークンを知っていればログインせずに送れるので，同じ共有リンクでもIPアドレスごとに数える
+func (m *Middleware) GuestRateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		shareLinkID, err := getShareLinkID(c)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get shareLinkID: %w", err))
+		}
+
+		// traQIDには:が使えないのでユーザーのキーと重複しない
+		return m.limit(c, next, "share:"+strconv.Itoa(shareLinkID)+" "+remoteIP(c))
+	}
+}
+
+// remoteIP 接続元のIPアドレス
+// X-Forwarded-ForやX-Real-IPはクライアントが自由に付けられるので使わない
+func remoteIP(c echo.Context) string {
+	remoteAddr := c.Request().RemoteAddr
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
+
+// limit リクエストを送った主体のキーとエンドポイントごとに回数を数える
+func (m *Middleware) limit(c echo.Context, next echo.HandlerFunc, key string) error {
+	kind := "write"
+	rateLimiter := m.rateLimiters.Write
+	if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+		kind = "read"
+		rateLimiter = m.rateLimiters.Read
+	}
+	if rateLimiter == nil {
+		return next(c)
+	}
+
+	allowed, retryAfter, err := rateLimiter.Allow(key + " " + kind + " " + c.Path())
+	if err != nil {
+		// 回数制限のストアの障害で全てのリクエストが失敗しないように許可する
+		c.Logger().Error(fmt.Errorf("failed to check rate limit: %w", err))
+		return next(c)
+	}
+	if !allowed {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	return next(c)
+}
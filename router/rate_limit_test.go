@@ -0,0 +1,191 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/anke-to/config"
+)
+
+// fakeClock テスト用に進める時刻
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func newFakeClockRateLimiter(rule config.RateLimitRule) (*MemoryRateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	rateLimiter := NewMemoryRateLimiter(rule)
+	rateLimiter.now = clock.Now
+
+	return rateLimiter, clock
+}
+
+// failingRateLimiter 共有のストアの障害を再現するRateLimiter
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Allow(string) (bool, time.Duration, error) {
+	return false, 0, errors.New("store is down")
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	rateLimiter, clock := newFakeClockRateLimiter(config.RateLimitRule{PerMinute: 30, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := rateLimiter.Allow("mazrean")
+		require.NoError(t, err)
+		assert.True(t, allowed, "burst")
+	}
+
+	allowed, retryAfter, err := rateLimiter.Allow("mazrean")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, retryAfter, "a token is refilled every 2 seconds")
+
+	allowed, _, err = rateLimiter.Allow("mds_boy")
+	require.NoError(t, err)
+	assert.True(t, allowed, "other keys have their own buckets")
+
+	clock.now = clock.now.Add(time.Second)
+	allowed, retryAfter, err = rateLimiter.Allow("mazrean")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	clock.now = clock.now.Add(time.Second)
+	allowed, _, err = rateLimiter.Allow("mazrean")
+	require.NoError(t, err)
+	assert.True(t, allowed, "refilled")
+
+	clock.now = clock.now.Add(time.Hour)
+	allowed, _, err = rateLimiter.Allow("mazrean")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Len(t, rateLimiter.buckets, 1, "full buckets are swept")
+	assert.InDelta(t, 1, rateLimiter.buckets["mazrean"].tokens, 0.001, "refilled up to the burst")
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	read, _ := newFakeClockRateLimiter(config.RateLimitRule{PerMinute: 60, Burst: 1})
+	write, _ := newFakeClockRateLimiter(config.RateLimitRule{PerMinute: 6, Burst: 1})
+	m := &Middleware{
+		rateLimiters: &RateLimiters{
+			Read:  read,
+			Write: write,
+		},
+	}
+
+	e := echo.New()
+	request := func(m *Middleware, userID string, method string, path string) (int, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, path, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		c.Set(userIDKey, userID)
+
+		err := m.RateLimit(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+
+		return getStatusCode(err, rec), rec
+	}
+
+	code, _ := request(m, "mazrean", http.MethodPost, "/api/responses")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, rec := request(m, "mazrean", http.MethodPost, "/api/responses")
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	code, _ = request(m, "mazrean", http.MethodGet, "/api/responses")
+	assert.Equal(t, http.StatusOK, code, "reads have a separate budget")
+
+	code, rec = request(m, "mazrean", http.MethodGet, "/api/responses")
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	code, _ = request(m, "mazrean", http.MethodPost, "/api/questions")
+	assert.Equal(t, http.StatusOK, code, "endpoints have separate budgets")
+
+	code, _ = request(m, "mds_boy", http.MethodPost, "/api/responses")
+	assert.Equal(t, http.StatusOK, code, "users have separate budgets")
+
+	unlimited := &Middleware{rateLimiters: &RateLimiters{}}
+	for i := 0; i < 3; i++ {
+		code, _ = request(unlimited, "mazrean", http.MethodPost, "/api/responses")
+		assert.Equal(t, http.StatusOK, code, "disabled")
+	}
+
+	failing := &Middleware{rateLimiters: &RateLimiters{Write: failingRateLimiter{}}}
+	code, _ = request(failing, "mazrean", http.MethodPost, "/api/responses")
+	assert.Equal(t, http.StatusOK, code, "requests are allowed when the rate limiter fails")
+}
+
+func TestGuestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	write, _ := newFakeClockRateLimiter(config.RateLimitRule{PerMinute: 6, Burst: 1})
+	m := &Middleware{
+		rateLimiters: &RateLimiters{
+			Write: write,
+		},
+	}
+
+	e := echo.New()
+	request := func(shareLinkID int, remoteAddr string, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/share/token/responses", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+			req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/share/:token/responses")
+		c.Set(shareLinkIDKey, shareLinkID)
+
+		err := m.GuestRateLimit(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+
+		return getStatusCode(err, rec)
+	}
+
+	assert.Equal(t, http.StatusOK, request(1, "192.0.2.1:1234", ""))
+	assert.Equal(t, http.StatusTooManyRequests, request(1, "192.0.2.1:5678", ""), "same share link and IP address")
+	assert.Equal(t, http.StatusTooManyRequests, request(1, "192.0.2.1:1234", "198.51.100.1"), "forwarded headers are ignored")
+	assert.Equal(t, http.StatusOK, request(1, "192.0.2.2:1234", ""), "IP addresses have separate budgets")
+	assert.Equal(t, http.StatusOK, request(2, "192.0.2.1:1234", ""), "share links have separate budgets")
+}
+
+func TestNewRateLimiters(t *testing.T) {
+	t.Parallel()
+
+	rateLimiters := NewRateLimiters(config.Default().RateLimit)
+	if assert.IsType(t, &MemoryRateLimiter{}, rateLimiters.Write) {
+		assert.Equal(t, config.Default().RateLimit.Write, rateLimiters.Write.(*MemoryRateLimiter).rule)
+	}
+
+	rateLimiters = NewRateLimiters(config.RateLimitConfig{
+		Read:  config.RateLimitRule{PerMinute: 0, Burst: 60},
+		Write: config.RateLimitRule{PerMinute: 60, Burst: 3},
+	})
+	assert.Nil(t, rateLimiters.Read, "disabled")
+	if assert.IsType(t, &MemoryRateLimiter{}, rateLimiters.Write) {
+		assert.Equal(t, 3, rateLimiters.Write.(*MemoryRateLimiter).rule.Burst)
+	}
+}
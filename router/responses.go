@@ -1,10 +1,12 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -12,25 +14,39 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+	"github.com/traPtitech/anke-to/traq"
 )
 
 // Response Responseの構造体
 type Response struct {
+	model.ITransaction
 	model.IQuestionnaire
 	model.IValidation
 	model.IScaleLabel
 	model.IRespondent
 	model.IResponse
+	model.IQuestion
+	model.IOption
+	model.IShareLink
+	traq.IUser
+	audit *service.Audit
 }
 
 // NewResponse Responseのコンストラクタ
-func NewResponse(questionnaire model.IQuestionnaire, validation model.IValidation, scaleLabel model.IScaleLabel, respondent model.IRespondent, response model.IResponse) *Response {
+func NewResponse(transaction model.ITransaction, questionnaire model.IQuestionnaire, validation model.IValidation, scaleLabel model.IScaleLabel, respondent model.IRespondent, response model.IResponse, question model.IQuestion, option model.IOption, shareLink model.IShareLink, user traq.IUser, audit *service.Audit) *Response {
 	return &Response{
+		ITransaction:   transaction,
 		IQuestionnaire: questionnaire,
 		IValidation:    validation,
 		IScaleLabel:    scaleLabel,
 		IRespondent:    respondent,
 		IResponse:      response,
+		IQuestion:      question,
+		IOption:        option,
+		IShareLink:     shareLink,
+		IUser:          user,
+		audit:          audit,
 	}
 }
 
@@ -43,6 +59,8 @@ type Responses struct {
 
 // PostResponse POST /responses
 func (r *Response) PostResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
@@ -55,130 +73,256 @@ func (r *Response) PostResponse(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	limit, err := r.GetQuestionnaireLimit(req.ID)
+	if err := r.checkResponseAcceptable(ctx, req.ID); err != nil {
+		return err
+	}
+
+	if err := r.checkResponseBodiesInQuestionnaire(ctx, req.ID, req.Body); err != nil {
+		return err
+	}
+
+	if err := r.validateResponseBodies(ctx, req.Body); err != nil {
+		return err
+	}
+
+	// 回答者と回答を同じトランザクションで追加し，回答だけが欠けた回答者を残さない
+	var responseID int
+	err = r.Do(ctx, nil, func(ctx context.Context) error {
+		var err error
+		responseID, err = r.InsertRespondent(ctx, userID, req.ID, req.SubmittedAt)
+		if err != nil {
+			return err
+		}
+
+		err = r.InsertResponses(ctx, responseID, createResponseMetas(req.Body))
+		if err != nil {
+			return fmt.Errorf("failed to insert responses: %w", err)
+		}
+
+		return nil
+	})
+	if errors.Is(err, model.ErrResponseAlreadyExists) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"message":    "the response already exists",
+			"responseID": responseID,
+		})
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	// 回答期限を過ぎた回答は許可しない
-	if limit.Valid && limit.Time.Before(time.Now()) {
-		return echo.NewHTTPError(http.StatusMethodNotAllowed)
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"responseID":      responseID,
+		"questionnaireID": req.ID,
+		"submitted_at":    req.SubmittedAt,
+		"body":            req.Body,
+	})
+}
+
+// GetResponse GET /responses/:responseID
+func (r *Response) GetResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	strResponseID := c.Param("responseID")
+	responseID, err := strconv.Atoi(strResponseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to parse responseID(%s) to integer: %w", strResponseID, err))
 	}
 
-	// validationsのパターンマッチ
-	questionIDs := make([]int, 0, len(req.Body))
-	QuestionTypes := make(map[int]model.ResponseBody, len(req.Body))
+	respondentDetail, err := r.GetRespondentDetail(ctx, responseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
 
-	for _, body := range req.Body {
-		questionIDs = append(questionIDs, body.QuestionID)
-		QuestionTypes[body.QuestionID] = body
+	return c.JSON(http.StatusOK, respondentDetail)
+}
+
+// EditResponse PATCH /responses/:responseID
+func (r *Response) EditResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	responseID, err := getResponseID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
 	}
 
-	validations, err := r.GetValidations(questionIDs)
+	req := Responses{}
+	if err := c.Bind(&req); err != nil {
+		c.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
 
-	// パターンマッチしてエラーなら返す
-	for _, validation := range validations {
-		body := QuestionTypes[validation.QuestionID]
-		switch body.QuestionType {
-		case "Number":
+	respondentDetail, err := r.GetRespondentDetail(ctx, responseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if req.ID != respondentDetail.QuestionnaireID {
+		return echo.NewHTTPError(http.StatusBadRequest, "questionnaireID does not match the response")
+	}
+
+	if err := r.checkResponseAcceptable(ctx, respondentDetail.QuestionnaireID); err != nil {
+		return err
+	}
+
+	if err := r.checkResponseBodiesInQuestionnaire(ctx, respondentDetail.QuestionnaireID, req.Body); err != nil {
+		return err
+	}
+
+	if err := r.validateResponseBodies(ctx, req.Body); err != nil {
+		return err
+	}
+
+	// 送信と回答の置き換えを同じトランザクションで行い，途中で失敗しても回答が消えないようにする
+	err = r.Do(ctx, nil, func(ctx context.Context) error {
+		// 下書きを初めて送信する場合は編集として数えない
+		if respondentDetail.SubmittedAt.Valid {
+			err := r.UpdateEditedAt(ctx, responseID)
 			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err)
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to update edited_at: %w", err))
 			}
-			if err := r.CheckNumberValidation(validation, body.Body.ValueOrZero()); err != nil {
-				if errors.Is(err, model.ErrInvalidNumber) {
-					return echo.NewHTTPError(http.StatusInternalServerError, err)
-				}
-				return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if req.SubmittedAt.Valid {
+			err := r.UpdateSubmittedAt(ctx, responseID)
+			if errors.Is(err, model.ErrResponseAlreadyExists) {
+				return echo.NewHTTPError(http.StatusConflict, "the submitted response already exists")
 			}
-		case "Text":
 			if err != nil {
-				return echo.NewHTTPError(http.StatusInternalServerError, err)
-			}
-			if err := r.CheckTextValidation(validation, body.Body.ValueOrZero()); err != nil {
-				if errors.Is(err, model.ErrTextMatching) {
-					return echo.NewHTTPError(http.StatusBadRequest, err)
-				}
-				return echo.NewHTTPError(http.StatusInternalServerError, err)
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to update sbmitted_at: %w", err))
 			}
 		}
-	}
 
-	scaleLabelIDs := []int{}
-	for _, body := range req.Body {
-		switch body.QuestionType {
-		case "LinearScale":
-			scaleLabelIDs = append(scaleLabelIDs, body.QuestionID)
+		//全消し&追加(レコード数爆発しそう)
+		if err := r.IResponse.DeleteResponse(ctx, responseID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
+
+		err := r.InsertResponses(ctx, responseID, createResponseMetas(req.Body))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to insert responses: %w", err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// DeleteResponse DELETE /responses/:responseID
+func (r *Response) DeleteResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 	}
 
-	scaleLabels, err := r.GetScaleLabels(scaleLabelIDs)
+	responseID, err := getResponseID(c)
 	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
+	}
+
+	err = r.audit.Record(ctx, userID, service.ActionResponseDelete, service.TargetResponse, responseID, func(ctx context.Context) error {
+		return r.DeleteRespondent(ctx, userID, responseID)
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrNoRecordDeleted) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
-	scaleLabelMap := make(map[int]*model.ScaleLabels, len(scaleLabels))
-	for _, label := range scaleLabels {
-		scaleLabelMap[label.QuestionID] = &label
+
+	return c.NoContent(http.StatusOK)
+}
+
+// EditResponseDraft PATCH /responses/:responseID/draft
+func (r *Response) EditResponseDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	responseID, err := getResponseID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
 	}
 
-	// LinearScaleのパターンマッチ
-	for _, body := range req.Body {
-		switch body.QuestionType {
-		case "LinearScale":
-			label, ok := scaleLabelMap[body.QuestionID]
-			if !ok {
-				label = &model.ScaleLabels{}
-			}
-			if err := r.CheckScaleLabel(*label, body.Body.ValueOrZero()); err != nil {
-				return echo.NewHTTPError(http.StatusBadRequest, err)
-			}
+	req := struct {
+		Body []model.ResponseBody `json:"body"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		c.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	respondentDetail, err := r.GetRespondentDetail(ctx, responseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
 		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	responseID, err := r.InsertRespondent(userID, req.ID, req.SubmittedAt)
+	// 送信済みの回答はPATCH /responses/:responseIDで編集する
+	if respondentDetail.SubmittedAt.Valid {
+		return echo.NewHTTPError(http.StatusConflict, "the response has already been submitted")
+	}
+
+	if err := r.checkResponseAcceptable(ctx, respondentDetail.QuestionnaireID); err != nil {
+		return err
+	}
+
+	questions, err := r.GetQuestions(ctx, respondentDetail.QuestionnaireID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
+	questionTypes := make(map[int]string, len(questions))
+	for _, question := range questions {
+		questionTypes[question.ID] = question.Type
+	}
 
-	responseMetas := make([]*model.ResponseMeta, 0, len(req.Body))
+	// 下書きでは回答の形式のみを確認し，validationsは送信時に確認する
+	questionIDs := make([]int, 0, len(req.Body))
 	for _, body := range req.Body {
-		switch body.QuestionType {
-		case "MultipleChoice", "Checkbox", "Dropdown":
-			for _, option := range body.OptionResponse {
-				responseMetas = append(responseMetas, &model.ResponseMeta{
-					QuestionID: body.QuestionID,
-					Data:       option,
-				})
-			}
-		default:
-			responseMetas = append(responseMetas, &model.ResponseMeta{
-				QuestionID: body.QuestionID,
-				Data:       body.Body.ValueOrZero(),
-			})
+		questionType, ok := questionTypes[body.QuestionID]
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is not in the questionnaire", body.QuestionID))
 		}
+		if err := checkResponseType(questionType, body); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		questionIDs = append(questionIDs, body.QuestionID)
 	}
 
-	err = r.InsertResponses(responseID, responseMetas)
+	changedQuestionIDs, err := r.MergeResponses(ctx, responseID, questionIDs, createResponseMetas(req.Body))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to insert responses: %w", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to merge responses: %w", err))
 	}
 
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"responseID":      responseID,
-		"questionnaireID": req.ID,
-		"submitted_at":    req.SubmittedAt,
-		"body":            req.Body,
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"responseID":        responseID,
+		"changed_questions": changedQuestionIDs,
 	})
 }
 
-// GetResponse GET /responses/:responseID
-func (r *Response) GetResponse(c echo.Context) error {
-	strResponseID := c.Param("responseID")
-	responseID, err := strconv.Atoi(strResponseID)
+// SubmitResponse POST /responses/:responseID/submit
+func (r *Response) SubmitResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	responseID, err := getResponseID(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to parse responseID(%s) to integer: %w", strResponseID, err))
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
 	}
 
-	respondentDetail, err := r.GetRespondentDetail(responseID)
+	respondentDetail, err := r.GetRespondentDetail(ctx, responseID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -186,23 +330,131 @@ func (r *Response) GetResponse(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	return c.JSON(http.StatusOK, respondentDetail)
+	if respondentDetail.SubmittedAt.Valid {
+		return echo.NewHTTPError(http.StatusConflict, "the response has already been submitted")
+	}
+
+	if err := r.checkResponseAcceptable(ctx, respondentDetail.QuestionnaireID); err != nil {
+		return err
+	}
+
+	if err := r.validateResponseBodies(ctx, respondentDetail.Responses); err != nil {
+		return err
+	}
+
+	err = r.UpdateSubmittedAt(ctx, responseID)
+	if errors.Is(err, model.ErrResponseAlreadyExists) {
+		return echo.NewHTTPError(http.StatusConflict, "the submitted response already exists")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to update sbmitted_at: %w", err))
+	}
+
+	return c.NoContent(http.StatusOK)
 }
 
-// EditResponse PATCH /responses/:responseID
-func (r *Response) EditResponse(c echo.Context) error {
-	responseID, err := getResponseID(c)
+// PostProxyResponse POST /questionnaires/:questionnaireID/responses/proxy
+func (r *Response) PostProxyResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 	}
 
-	req := Responses{}
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	req := struct {
+		TraqID string               `json:"traqID"`
+		Body   []model.ResponseBody `json:"body"`
+	}{}
 	if err := c.Bind(&req); err != nil {
 		c.Logger().Error(err)
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	limit, err := r.GetQuestionnaireLimit(req.ID)
+	if err := checkProxyRespondentID(req.TraqID); err != nil {
+		return err
+	}
+	if err := checkUserIDs(r.IUser, []string{req.TraqID}); err != nil {
+		return err
+	}
+
+	questionnaire, _, _, _, err := r.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// 匿名のアンケートでは回答者が管理者に分かってしまうため代理入力を許可しない
+	if questionnaire.IsAnonymous {
+		return echo.NewHTTPError(http.StatusBadRequest, "proxy responses are not allowed for anonymous questionnaires")
+	}
+
+	if err := r.checkResponseAcceptable(ctx, questionnaireID); err != nil {
+		return err
+	}
+
+	if err := r.checkResponseBodiesInQuestionnaire(ctx, questionnaireID, req.Body); err != nil {
+		return err
+	}
+
+	if err := r.validateResponseBodies(ctx, req.Body); err != nil {
+		return err
+	}
+
+	var existingResponseID int
+	responseID, err := r.audit.RecordCreation(ctx, userID, service.ActionResponseProxyCreate, service.TargetResponse, func(ctx context.Context) (int, error) {
+		responseID, err := r.InsertProxyRespondent(ctx, req.TraqID, userID, questionnaireID)
+		if err != nil {
+			existingResponseID = responseID
+			return 0, err
+		}
+
+		err = r.InsertResponses(ctx, responseID, createResponseMetas(req.Body))
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert responses: %w", err)
+		}
+
+		return responseID, nil
+	})
+	if errors.Is(err, model.ErrResponseAlreadyExists) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"message":    "the response already exists",
+			"responseID": existingResponseID,
+		})
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"responseID":      responseID,
+		"questionnaireID": questionnaireID,
+		"traqID":          req.TraqID,
+		"entered_by":      userID,
+		"body":            req.Body,
+	})
+}
+
+// checkProxyRespondentID 代理入力の回答者のtraQIDの確認
+// 全員を表すtraP・グループ・共有リンクのゲストの代わりには回答できない
+func checkProxyRespondentID(traqID string) error {
+	if len(traqID) == 0 || len(traqID) > 30 || traqID == "traP" || traq.IsGroupID(traqID) || strings.HasPrefix(traqID, guestUserIDPrefix) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid traqID")
+	}
+
+	return nil
+}
+
+// checkResponseAcceptable アンケートが回答を受け付けているかの確認
+func (r *Response) checkResponseAcceptable(ctx context.Context, questionnaireID int) error {
+	limit, err := r.GetQuestionnaireLimit(ctx, questionnaireID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
@@ -212,16 +464,54 @@ func (r *Response) EditResponse(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusMethodNotAllowed)
 	}
 
+	// 回答受付が終了したアンケートへの回答は許可しない
+	isClosed, err := r.CheckQuestionnaireClosed(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	if isClosed {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "the questionnaire is closed")
+	}
+
+	return nil
+}
+
+// checkResponseBodiesInQuestionnaire 回答がアンケートの質問に対するもので，質問の種類に合っているかの確認
+func (r *Response) checkResponseBodiesInQuestionnaire(ctx context.Context, questionnaireID int, bodies []model.ResponseBody) error {
+	questions, err := r.GetQuestions(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	questionTypes := make(map[int]string, len(questions))
+	for _, question := range questions {
+		questionTypes[question.ID] = question.Type
+	}
+
+	for _, body := range bodies {
+		questionType, ok := questionTypes[body.QuestionID]
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is not in the questionnaire", body.QuestionID))
+		}
+		if err := checkResponseType(questionType, body); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+	}
+
+	return nil
+}
+
+// validateResponseBodies validationsとscale_labelsによる回答の検証
+func (r *Response) validateResponseBodies(ctx context.Context, bodies []model.ResponseBody) error {
 	// validationsのパターンマッチ
-	questionIDs := make([]int, 0, len(req.Body))
-	QuestionTypes := make(map[int]model.ResponseBody, len(req.Body))
+	questionIDs := make([]int, 0, len(bodies))
+	QuestionTypes := make(map[int]model.ResponseBody, len(bodies))
 
-	for _, body := range req.Body {
+	for _, body := range bodies {
 		questionIDs = append(questionIDs, body.QuestionID)
 		QuestionTypes[body.QuestionID] = body
 	}
 
-	validations, err := r.GetValidations(questionIDs)
+	validations, err := r.GetValidations(ctx, questionIDs)
 
 	// パターンマッチしてエラーなら返す
 	for _, validation := range validations {
@@ -251,14 +541,14 @@ func (r *Response) EditResponse(c echo.Context) error {
 	}
 
 	scaleLabelIDs := []int{}
-	for _, body := range req.Body {
+	for _, body := range bodies {
 		switch body.QuestionType {
 		case "LinearScale":
 			scaleLabelIDs = append(scaleLabelIDs, body.QuestionID)
 		}
 	}
 
-	scaleLabels, err := r.GetScaleLabels(scaleLabelIDs)
+	scaleLabels, err := r.GetScaleLabels(ctx, scaleLabelIDs)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
@@ -268,7 +558,7 @@ func (r *Response) EditResponse(c echo.Context) error {
 	}
 
 	// LinearScaleのパターンマッチ
-	for _, body := range req.Body {
+	for _, body := range bodies {
 		switch body.QuestionType {
 		case "LinearScale":
 			label, ok := scaleLabelMap[body.QuestionID]
@@ -281,20 +571,42 @@ func (r *Response) EditResponse(c echo.Context) error {
 		}
 	}
 
-	if req.SubmittedAt.Valid {
-		err := r.UpdateSubmittedAt(responseID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to update sbmitted_at: %w", err))
-		}
+	return nil
+}
+
+// checkResponseType 回答が質問の種類に合った形式かの確認
+func checkResponseType(questionType string, body model.ResponseBody) error {
+	if body.QuestionType != questionType {
+		return fmt.Errorf("the type of question(%d) is %s, not %s", body.QuestionID, questionType, body.QuestionType)
 	}
 
-	//全消し&追加(レコード数爆発しそう)
-	if err := r.IResponse.DeleteResponse(responseID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	switch questionType {
+	case "Number":
+		if body.Body.ValueOrZero() == "" {
+			return nil
+		}
+		if _, err := strconv.ParseFloat(body.Body.ValueOrZero(), 64); err != nil {
+			return fmt.Errorf("the response to question(%d) is not a number: %w", body.QuestionID, err)
+		}
+	case "LinearScale":
+		if body.Body.ValueOrZero() == "" {
+			return nil
+		}
+		if _, err := strconv.Atoi(body.Body.ValueOrZero()); err != nil {
+			return fmt.Errorf("the response to question(%d) is not an integer: %w", body.QuestionID, err)
+		}
+	case "MultipleChoice", "Dropdown":
+		if len(body.OptionResponse) > 1 {
+			return fmt.Errorf("question(%d) accepts only one option", body.QuestionID)
+		}
 	}
 
-	responseMetas := make([]*model.ResponseMeta, 0, len(req.Body))
-	for _, body := range req.Body {
+	return nil
+}
+
+func createResponseMetas(bodies []model.ResponseBody) []*model.ResponseMeta {
+	responseMetas := make([]*model.ResponseMeta, 0, len(bodies))
+	for _, body := range bodies {
 		switch body.QuestionType {
 		case "MultipleChoice", "Checkbox", "Dropdown":
 			for _, option := range body.OptionResponse {
@@ -311,32 +623,5 @@ func (r *Response) EditResponse(c echo.Context) error {
 		}
 	}
 
-	err = r.InsertResponses(responseID, responseMetas)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to insert responses: %w", err))
-	}
-
-	return c.NoContent(http.StatusOK)
-}
-
-// DeleteResponse DELETE /responses/:responseID
-func (r *Response) DeleteResponse(c echo.Context) error {
-	userID, err := getUserID(c)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
-	}
-
-	responseID, err := getResponseID(c)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get responseID: %w", err))
-	}
-
-	if err := r.DeleteRespondent(userID, responseID); err != nil {
-		if errors.Is(err, model.ErrNoRecordDeleted) {
-			return echo.NewHTTPError(http.StatusNotFound, err)
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
-	}
-
-	return c.NoContent(http.StatusOK)
+	return responseMetas
 }
@@ -1,14 +1,17 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/traq"
 )
 
 // Result Resultの構造体
@@ -16,19 +19,31 @@ type Result struct {
 	model.IRespondent
 	model.IQuestionnaire
 	model.IAdministrator
+	model.IResponse
+	model.IQuestion
+	model.IOption
+	model.IScaleLabel
+	traq.IGroup
 }
 
 // NewResult Resultのコンストラクタ
-func NewResult(respondent model.IRespondent, questionnaire model.IQuestionnaire, administrator model.IAdministrator) *Result {
+func NewResult(respondent model.IRespondent, questionnaire model.IQuestionnaire, administrator model.IAdministrator, response model.IResponse, question model.IQuestion, option model.IOption, scaleLabel model.IScaleLabel, group traq.IGroup) *Result {
 	return &Result{
 		IRespondent:    respondent,
 		IQuestionnaire: questionnaire,
 		IAdministrator: administrator,
+		IResponse:      response,
+		IQuestion:      question,
+		IOption:        option,
+		IScaleLabel:    scaleLabel,
+		IGroup:         group,
 	}
 }
 
 // GetResults GET /results/:questionnaireID
 func (r *Result) GetResults(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	sort := c.QueryParam("sort")
 	questionnaireID, err := strconv.Atoi(c.Param("questionnaireID"))
 	if err != nil {
@@ -41,17 +56,297 @@ func (r *Result) GetResults(c echo.Context) error {
 		return err
 	}
 
-	respondentDetails, err := r.GetRespondentDetails(questionnaireID, sort)
+	// limitを省略した場合は全ての回答を返す
+	limit := 0
+	if strLimit := c.QueryParam("limit"); strLimit != "" {
+		limit, err = strconv.Atoi(strLimit)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid limit: %s", strLimit))
+		}
+	}
+	after := c.QueryParam("after")
+	filter := c.QueryParam("filter")
+
+	respondentDetails, nextCursor, err := r.GetRespondentDetailsPage(ctx, questionnaireID, sort, filter, limit, after)
+	if errors.Is(err, model.ErrInvalidSortParam) || errors.Is(err, model.ErrInvalidCursor) || errors.Is(err, model.ErrInvalidFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if nextCursor != "" {
+		query := url.Values{}
+		if sort != "" {
+			query.Set("sort", sort)
+		}
+		if filter != "" {
+			query.Set("filter", filter)
+		}
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("after", nextCursor)
+		c.Response().Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", c.Request().URL.Path, query.Encode()))
+	}
+
 	return c.JSON(http.StatusOK, respondentDetails)
 }
 
+// GetResponseHistory GET /responses/:responseID/history
+func (r *Result) GetResponseHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	strResponseID := c.Param("responseID")
+	responseID, err := strconv.Atoi(strResponseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid responseID:%s(error: %w)", strResponseID, err))
+	}
+
+	respondentDetail, err := r.GetRespondentDetail(ctx, responseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// 回答者本人以外はアンケートの回答を確認する権限が必要
+	isRespondent, err := r.CheckRespondentByResponseID(ctx, userID, responseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are a respondent: %w", err))
+	}
+	if !isRespondent {
+		if err := r.checkResponseConfirmable(c, respondentDetail.QuestionnaireID); err != nil {
+			return err
+		}
+	}
+
+	histories, err := r.IResponse.GetResponseHistory(ctx, responseID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, histories)
+}
+
+// CrossTabulation クロス集計の結果の構造体
+type CrossTabulation struct {
+	Row              CrossTabulationAxis `json:"row"`
+	Col              CrossTabulationAxis `json:"col"`
+	Counts           [][]int             `json:"counts"`
+	RowTotals        []int               `json:"row_totals"`
+	ColTotals        []int               `json:"col_totals"`
+	Total            int                 `json:"total"`
+	RowPercentages   [][]float64         `json:"row_percentages"`
+	ColPercentages   [][]float64         `json:"col_percentages"`
+	ChiSquare        float64             `json:"chi_square"`
+	DegreesOfFreedom int                 `json:"degrees_of_freedom"`
+}
+
+// CrossTabulationAxis クロス集計の行または列の質問の構造体
+type CrossTabulationAxis struct {
+	QuestionID int      `json:"questionID"`
+	Body       string   `json:"body"`
+	Labels     []string `json:"labels"`
+}
+
+// GetCrossTabulation GET /results/:questionnaireID/crosstab
+func (r *Result) GetCrossTabulation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := strconv.Atoi(c.Param("questionnaireID"))
+	if err != nil {
+		c.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	// アンケートの回答を確認する権限が無ければエラーを返す
+	if err := r.checkResponseConfirmable(c, questionnaireID); err != nil {
+		return err
+	}
+
+	rowQuestionID, err := strconv.Atoi(c.QueryParam("row"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid row: %w", err))
+	}
+	colQuestionID, err := strconv.Atoi(c.QueryParam("col"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid col: %w", err))
+	}
+
+	questions, err := r.GetQuestions(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	questionMap := make(map[int]model.Questions, len(questions))
+	for _, question := range questions {
+		questionMap[question.ID] = question
+	}
+
+	axes := make([]CrossTabulationAxis, 0, 2)
+	for _, questionID := range []int{rowQuestionID, colQuestionID} {
+		question, ok := questionMap[questionID]
+		if !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is not in the questionnaire", questionID))
+		}
+
+		labels, err := r.getCrossTabulationLabels(ctx, question)
+		if err != nil {
+			return err
+		}
+
+		axes = append(axes, CrossTabulationAxis{
+			QuestionID: question.ID,
+			Body:       question.Body,
+			Labels:     labels,
+		})
+	}
+
+	cells, err := r.IResponse.GetCrossTabulation(ctx, questionnaireID, rowQuestionID, colQuestionID, c.QueryParam("filter"))
+	if errors.Is(err, model.ErrInvalidFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newCrossTabulation(axes[0], axes[1], cells))
+}
+
+// getCrossTabulationLabels 選択肢の順または目盛りの順の回答の一覧
+func (r *Result) getCrossTabulationLabels(ctx context.Context, question model.Questions) ([]string, error) {
+	switch question.Type {
+	case "Checkbox":
+		// 1つの回答が複数のセルに数えられ，カイ二乗検定の前提を満たさない
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is Checkbox, which allows multiple answers", question.ID))
+	case "MultipleChoice", "Dropdown":
+		options, err := r.GetOptions(ctx, []int{question.ID})
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		labels := make([]string, 0, len(options))
+		for _, option := range options {
+			labels = append(labels, option.Body)
+		}
+
+		return labels, nil
+	case "LinearScale":
+		scaleLabels, err := r.GetScaleLabels(ctx, []int{question.ID})
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		if len(scaleLabels) == 0 {
+			return []string{}, nil
+		}
+
+		labels := []string{}
+		for i := scaleLabels[0].ScaleMin; i <= scaleLabels[0].ScaleMax; i++ {
+			labels = append(labels, strconv.Itoa(i))
+		}
+
+		return labels, nil
+	default:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("question(%d) is %s, not a choice or scale question", question.ID, question.Type))
+	}
+}
+
+// newCrossTabulation 回答数から割合とカイ二乗統計量を求める
+func newCrossTabulation(row CrossTabulationAxis, col CrossTabulationAxis, cells []model.CrossTabulationCell) *CrossTabulation {
+	// 選択肢の変更などで一覧に無い回答は末尾に加える
+	rowIndexes := make(map[string]int, len(row.Labels))
+	for i, label := range row.Labels {
+		rowIndexes[label] = i
+	}
+	colIndexes := make(map[string]int, len(col.Labels))
+	for i, label := range col.Labels {
+		colIndexes[label] = i
+	}
+	for _, cell := range cells {
+		if _, ok := rowIndexes[cell.RowValue]; !ok {
+			rowIndexes[cell.RowValue] = len(row.Labels)
+			row.Labels = append(row.Labels, cell.RowValue)
+		}
+		if _, ok := colIndexes[cell.ColValue]; !ok {
+			colIndexes[cell.ColValue] = len(col.Labels)
+			col.Labels = append(col.Labels, cell.ColValue)
+		}
+	}
+
+	crossTabulation := &CrossTabulation{
+		Row:            row,
+		Col:            col,
+		Counts:         make([][]int, len(row.Labels)),
+		RowTotals:      make([]int, len(row.Labels)),
+		ColTotals:      make([]int, len(col.Labels)),
+		RowPercentages: make([][]float64, len(row.Labels)),
+		ColPercentages: make([][]float64, len(row.Labels)),
+	}
+	for i := range row.Labels {
+		crossTabulation.Counts[i] = make([]int, len(col.Labels))
+		crossTabulation.RowPercentages[i] = make([]float64, len(col.Labels))
+		crossTabulation.ColPercentages[i] = make([]float64, len(col.Labels))
+	}
+
+	for _, cell := range cells {
+		i, j := rowIndexes[cell.RowValue], colIndexes[cell.ColValue]
+		crossTabulation.Counts[i][j] += cell.Count
+		crossTabulation.RowTotals[i] += cell.Count
+		crossTabulation.ColTotals[j] += cell.Count
+		crossTabulation.Total += cell.Count
+	}
+
+	for i := range row.Labels {
+		for j := range col.Labels {
+			count := float64(crossTabulation.Counts[i][j])
+			if crossTabulation.RowTotals[i] != 0 {
+				crossTabulation.RowPercentages[i][j] = count / float64(crossTabulation.RowTotals[i]) * 100
+			}
+			if crossTabulation.ColTotals[j] != 0 {
+				crossTabulation.ColPercentages[i][j] = count / float64(crossTabulation.ColTotals[j]) * 100
+			}
+		}
+	}
+
+	// 回答の無い行と列は期待度数が0になるので除いて計算する
+	if crossTabulation.Total == 0 {
+		return crossTabulation
+	}
+	nonEmptyRows, nonEmptyCols := 0, 0
+	for _, rowTotal := range crossTabulation.RowTotals {
+		if rowTotal != 0 {
+			nonEmptyRows++
+		}
+	}
+	for _, colTotal := range crossTabulation.ColTotals {
+		if colTotal != 0 {
+			nonEmptyCols++
+		}
+	}
+	for i, rowTotal := range crossTabulation.RowTotals {
+		for j, colTotal := range crossTabulation.ColTotals {
+			expected := float64(rowTotal) * float64(colTotal) / float64(crossTabulation.Total)
+			if expected == 0 {
+				continue
+			}
+			diff := float64(crossTabulation.Counts[i][j]) - expected
+			crossTabulation.ChiSquare += diff * diff / expected
+		}
+	}
+	crossTabulation.DegreesOfFreedom = (nonEmptyRows - 1) * (nonEmptyCols - 1)
+
+	return crossTabulation
+}
+
 // アンケートの回答を確認できるか
 func (r *Result) checkResponseConfirmable(c echo.Context, questionnaireID int) error {
-	resSharedTo, err := r.GetResShared(questionnaireID)
+	ctx := c.Request().Context()
+
+	resSharedTo, err := r.GetResShared(ctx, questionnaireID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -66,7 +361,7 @@ func (r *Result) checkResponseConfirmable(c echo.Context, questionnaireID int) e
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 		}
 
-		isAdmin, err := r.CheckQuestionnaireAdmin(userID, questionnaireID)
+		isAdmin, err := checkQuestionnairePermission(ctx, r.IAdministrator, r.IGroup, userID, questionnaireID, PermissionViewResults)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
 		}
@@ -79,12 +374,12 @@ func (r *Result) checkResponseConfirmable(c echo.Context, questionnaireID int) e
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 		}
 
-		isAdmin, err := r.CheckQuestionnaireAdmin(userID, questionnaireID)
+		isAdmin, err := checkQuestionnairePermission(ctx, r.IAdministrator, r.IGroup, userID, questionnaireID, PermissionViewResults)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to check if you are administrator: %w", err))
 		}
 		if !isAdmin {
-			isRespondent, err := r.CheckRespondent(userID, questionnaireID)
+			isRespondent, err := r.CheckRespondent(ctx, userID, questionnaireID)
 			if err != nil {
 				return err
 			}
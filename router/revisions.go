@@ -0,0 +1,190 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"github.com/traPtitech/anke-to/model"
+)
+
+// Revision Revisionの構造体
+type Revision struct {
+	model.IRevision
+}
+
+// NewRevision Revisionのコンストラクタ
+func NewRevision(revision model.IRevision) *Revision {
+	return &Revision{
+		IRevision: revision,
+	}
+}
+
+// RevisionDiff リビジョン間の差分
+type RevisionDiff struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// GetRevisions GET /questionnaires/:questionnaireID/revisions
+func (r *Revision) GetRevisions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	revisions, err := r.IRevision.GetRevisions(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision GET /questionnaires/:questionnaireID/revisions/:revision
+func (r *Revision) GetRevision(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	strRevision := c.Param("revision")
+	revisionNum, err := strconv.Atoi(strRevision)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid revision:%s(error: %w)", strRevision, err))
+	}
+
+	revision, snapshot, err := r.IRevision.GetRevision(ctx, questionnaireID, revisionNum)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revisionID":      revision.ID,
+		"questionnaireID": revision.QuestionnaireID,
+		"revision":        revision.Revision,
+		"user_traq_id":    revision.UserTraqid,
+		"created_at":      revision.CreatedAt.Format(time.RFC3339),
+		"questionnaire":   snapshot,
+	})
+}
+
+// GetRevisionDiff GET /questionnaires/:questionnaireID/revisions/diff?from=&to=
+func (r *Revision) GetRevisionDiff(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	strFrom := c.QueryParam("from")
+	from, err := strconv.Atoi(strFrom)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid from:%s(error: %w)", strFrom, err))
+	}
+
+	strTo := c.QueryParam("to")
+	to, err := strconv.Atoi(strTo)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid to:%s(error: %w)", strTo, err))
+	}
+
+	_, fromSnapshot, err := r.IRevision.GetRevision(ctx, questionnaireID, from)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	_, toSnapshot, err := r.IRevision.GetRevision(ctx, questionnaireID, to)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"diffs": diffSnapshots(fromSnapshot, toSnapshot),
+	})
+}
+
+// diffSnapshots 2つのリビジョンのアンケートの差分を列挙する
+func diffSnapshots(from *model.QuestionnaireSnapshot, to *model.QuestionnaireSnapshot) []RevisionDiff {
+	diffs := []RevisionDiff{}
+	appendDiff := func(field string, before interface{}, after interface{}) {
+		if !reflect.DeepEqual(before, after) {
+			diffs = append(diffs, RevisionDiff{
+				Field:  field,
+				Before: before,
+				After:  after,
+			})
+		}
+	}
+
+	appendDiff("title", from.Title, to.Title)
+	appendDiff("description", from.Description, to.Description)
+	appendDiff("res_time_limit", from.ResTimeLimit, to.ResTimeLimit)
+	appendDiff("res_shared_to", from.ResSharedTo, to.ResSharedTo)
+	appendDiff("response_mode", from.ResponseMode, to.ResponseMode)
+	appendDiff("is_anonymous", from.IsAnonymous, to.IsAnonymous)
+	appendDiff("targets", from.Targets, to.Targets)
+	appendDiff("administrators", from.Administrators, to.Administrators)
+
+	toQuestionMap := make(map[int]model.QuestionSnapshot, len(to.Questions))
+	for _, question := range to.Questions {
+		toQuestionMap[question.QuestionID] = question
+	}
+
+	fromQuestionIDs := make(map[int]bool, len(from.Questions))
+	for _, before := range from.Questions {
+		fromQuestionIDs[before.QuestionID] = true
+		field := "questions[" + strconv.Itoa(before.QuestionID) + "]"
+
+		after, ok := toQuestionMap[before.QuestionID]
+		if !ok {
+			appendDiff(field, before, nil)
+			continue
+		}
+
+		appendDiff(field+".page_num", before.PageNum, after.PageNum)
+		appendDiff(field+".question_num", before.QuestionNum, after.QuestionNum)
+		appendDiff(field+".question_type", before.QuestionType, after.QuestionType)
+		appendDiff(field+".body", before.Body, after.Body)
+		appendDiff(field+".is_required", before.IsRequired, after.IsRequired)
+		appendDiff(field+".options", before.Options, after.Options)
+		appendDiff(field+".scale_label_right", before.ScaleLabelRight, after.ScaleLabelRight)
+		appendDiff(field+".scale_label_left", before.ScaleLabelLeft, after.ScaleLabelLeft)
+		appendDiff(field+".scale_min", before.ScaleMin, after.ScaleMin)
+		appendDiff(field+".scale_max", before.ScaleMax, after.ScaleMax)
+		appendDiff(field+".regex_pattern", before.RegexPattern, after.RegexPattern)
+		appendDiff(field+".min_bound", before.MinBound, after.MinBound)
+		appendDiff(field+".max_bound", before.MaxBound, after.MaxBound)
+	}
+
+	for _, after := range to.Questions {
+		if !fromQuestionIDs[after.QuestionID] {
+			appendDiff("questions["+strconv.Itoa(after.QuestionID)+"]", nil, after)
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,194 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/traq"
+)
+
+// アンケートの管理者の役割によって許可される操作
+const (
+	// PermissionViewResults 結果と回答状況の閲覧
+	PermissionViewResults = "view_results"
+	// PermissionEdit アンケートと質問の編集，締め切り，代理回答
+	PermissionEdit = "edit"
+	// PermissionManage アンケートの削除・復元と管理者の変更
+	PermissionManage = "manage"
+)
+
+// rolePermissions 役割ごとに許可される操作
+var rolePermissions = map[string][]string{
+	model.RoleOwner:  {PermissionViewResults, PermissionEdit, PermissionManage},
+	model.RoleEditor: {PermissionViewResults, PermissionEdit},
+	model.RoleViewer: {PermissionViewResults},
+}
+
+// roleRanks 役割の強さ 直接とグループを通しての複数の役割を持つ場合は強い方を使う
+var roleRanks = map[string]int{
+	model.RoleViewer: 1,
+	model.RoleEditor: 2,
+	model.RoleOwner:  3,
+}
+
+// roleNames 通知で使う役割の名前
+var roleNames = map[string]string{
+	model.RoleOwner:  "オーナー",
+	model.RoleEditor: "編集者",
+	model.RoleViewer: "閲覧者",
+}
+
+// hasPermission 役割で操作が許可されているか
+func hasPermission(role string, permission string) bool {
+	for _, rolePermission := range rolePermissions[role] {
+		if rolePermission == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getQuestionnaireRole 直接またはグループを通して持つアンケートでの役割の取得 (管理者でない場合は空文字列)
+func getQuestionnaireRole(ctx context.Context, administrator model.IAdministrator, group traq.IGroup, userID string, questionnaireID int) (string, error) {
+	role, err := administrator.GetQuestionnaireRole(ctx, userID, questionnaireID)
+	if err != nil {
+		return "", err
+	}
+	if role == model.RoleOwner {
+		return role, nil
+	}
+
+	groupRole, err := getGroupRole(ctx, administrator, group, userID, questionnaireID)
+	if err != nil {
+		return "", err
+	}
+	if roleRanks[groupRole] > roleRanks[role] {
+		role = groupRole
+	}
+
+	return role, nil
+}
+
+// getGroupRole 所属するグループが持つアンケートでの役割の取得
+func getGroupRole(ctx context.Context, administrator model.IAdministrator, group traq.IGroup, userID string, questionnaireID int) (string, error) {
+	administrators, err := administrator.GetAdministrators(ctx, []int{questionnaireID})
+	if err != nil {
+		return "", err
+	}
+
+	groupRoles := map[string]string{}
+	for _, administrator := range administrators {
+		if traq.IsGroupID(administrator.UserTraqid) {
+			groupRoles[administrator.UserTraqid] = administrator.Role
+		}
+	}
+	if len(groupRoles) == 0 {
+		return "", nil
+	}
+
+	groupIDs, err := group.GetUserGroupIDs(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get groups of %s: %w", userID, err)
+	}
+
+	role := ""
+	for _, groupID := range groupIDs {
+		if groupRole, ok := groupRoles[groupID]; ok && roleRanks[groupRole] > roleRanks[role] {
+			role = groupRole
+		}
+	}
+
+	return role, nil
+}
+
+// checkQuestionnairePermission アンケートに対する操作が許可されているか
+func checkQuestionnairePermission(ctx context.Context, administrator model.IAdministrator, group traq.IGroup, userID string, questionnaireID int, permission string) (bool, error) {
+	role, err := getQuestionnaireRole(ctx, administrator, group, userID, questionnaireID)
+	if err != nil {
+		return false, err
+	}
+
+	return hasPermission(role, permission), nil
+}
+
+// splitAdministratorsByRole 管理者を役割ごとに分ける
+func splitAdministratorsByRole(administrators []model.Administrators) map[int]map[string][]string {
+	administratorMap := map[int]map[string][]string{}
+	for _, administrator := range administrators {
+		roleMap, ok := administratorMap[administrator.QuestionnaireID]
+		if !ok {
+			roleMap = map[string][]string{
+				model.RoleOwner:  {},
+				model.RoleEditor: {},
+				model.RoleViewer: {},
+			}
+			administratorMap[administrator.QuestionnaireID] = roleMap
+		}
+		roleMap[administrator.Role] = append(roleMap[administrator.Role], administrator.UserTraqid)
+	}
+
+	return administratorMap
+}
+
+// checkRoleDuplication 1人(1グループ)が複数の役割を持っていないかの確認
+func checkRoleDuplication(owners []string, editors []string, viewers []string) error {
+	roleMap := map[string]string{}
+	for _, roleMembers := range []struct {
+		role    string
+		members []string
+	}{
+		{model.RoleOwner, owners},
+		{model.RoleEditor, editors},
+		{model.RoleViewer, viewers},
+	} {
+		for _, member := range roleMembers.members {
+			if role, ok := roleMap[member]; ok && role != roleMembers.role {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s cannot be both %s and %s", member, role, roleMembers.role))
+			}
+			roleMap[member] = roleMembers.role
+		}
+	}
+
+	return nil
+}
+
+// isSameIDs 順番を無視して同じIDの集合か
+func isSameIDs(ids1 []string, ids2 []string) bool {
+	idSet := make(map[string]struct{}, len(ids1))
+	for _, id := range ids1 {
+		idSet[id] = struct{}{}
+	}
+
+	otherIDSet := make(map[string]struct{}, len(ids2))
+	for _, id := range ids2 {
+		if _, ok := idSet[id]; !ok {
+			return false
+		}
+		otherIDSet[id] = struct{}{}
+	}
+
+	return len(idSet) == len(otherIDSet)
+}
+
+func concatIDs(idsList ...[]string) []string {
+	concatenatedIDs := []string{}
+	for _, ids := range idsList {
+		concatenatedIDs = append(concatenatedIDs, ids...)
+	}
+
+	return concatenatedIDs
+}
+
+// nonNilIDs JSONでnullではなく空の配列を返すため
+func nonNilIDs(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+
+	return ids
+}
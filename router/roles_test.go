@@ -0,0 +1,348 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/anke-to/config"
+	"github.com/traPtitech/anke-to/model"
+)
+
+// fakeAdministrator テスト用のメモリ上のmodel.IAdministratorの実装
+type fakeAdministrator struct {
+	administrators []model.Administrators
+}
+
+func (f *fakeAdministrator) InsertAdministrators(ctx context.Context, questionnaireID int, administrators []string, role string) error {
+	for _, administrator := range administrators {
+		f.administrators = append(f.administrators, model.Administrators{
+			QuestionnaireID: questionnaireID,
+			UserTraqid:      administrator,
+			Role:            role,
+		})
+	}
+
+	return nil
+}
+
+func (f *fakeAdministrator) DeleteAdministrators(ctx context.Context, questionnaireID int) error {
+	administrators := []model.Administrators{}
+	for _, administrator := range f.administrators {
+		if administrator.QuestionnaireID != questionnaireID {
+			administrators = append(administrators, administrator)
+		}
+	}
+	f.administrators = administrators
+
+	return nil
+}
+
+func (f *fakeAdministrator) GetAdministrators(ctx context.Context, questionnaireIDs []int) ([]model.Administrators, error) {
+	administrators := []model.Administrators{}
+	for _, administrator := range f.administrators {
+		for _, questionnaireID := range questionnaireIDs {
+			if administrator.QuestionnaireID == questionnaireID {
+				administrators = append(administrators, administrator)
+			}
+		}
+	}
+
+	return administrators, nil
+}
+
+func (f *fakeAdministrator) GetQuestionnaireRole(ctx context.Context, userID string, questionnaireID int) (string, error) {
+	for _, administrator := range f.administrators {
+		if administrator.QuestionnaireID == questionnaireID && administrator.UserTraqid == userID {
+			return administrator.Role, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (f *fakeAdministrator) GetAllAdministrators(ctx context.Context) ([]model.Administrators, error) {
+	return f.administrators, nil
+}
+
+func (f *fakeAdministrator) TransferOwnership(ctx context.Context, questionnaireID int, fromUserID string, toUserID string) error {
+	fromIndex := -1
+	for i, administrator := range f.administrators {
+		if administrator.QuestionnaireID == questionnaireID && administrator.UserTraqid == fromUserID && administrator.Role == model.RoleOwner {
+			fromIndex = i
+		}
+	}
+	if fromIndex < 0 {
+		return fmt.Errorf("failed to update administrator: %w", model.ErrNoRecordUpdated)
+	}
+	f.administrators[fromIndex].Role = model.RoleEditor
+
+	for i, administrator := range f.administrators {
+		if administrator.QuestionnaireID == questionnaireID && administrator.UserTraqid == toUserID {
+			f.administrators[i].Role = model.RoleOwner
+			return nil
+		}
+	}
+
+	return f.InsertAdministrators(ctx, questionnaireID, []string{toUserID}, model.RoleOwner)
+}
+
+func newFakeAdministrator() *fakeAdministrator {
+	ctx := context.Background()
+
+	administrator := &fakeAdministrator{}
+	_ = administrator.InsertAdministrators(ctx, 1, []string{"mazrean"}, model.RoleOwner)
+	_ = administrator.InsertAdministrators(ctx, 1, []string{"mds_boy"}, model.RoleEditor)
+	_ = administrator.InsertAdministrators(ctx, 1, []string{fakeGroupID1}, model.RoleViewer)
+	_ = administrator.InsertAdministrators(ctx, 2, []string{fakeGroupID2}, model.RoleEditor)
+
+	return administrator
+}
+
+func TestGetQuestionnaireRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	administrator := newFakeAdministrator()
+	group := newFakeGroup()
+
+	testCases := []struct {
+		description     string
+		userID          string
+		questionnaireID int
+		role            string
+	}{
+		{
+			description:     "直接オーナー",
+			userID:          "mazrean",
+			questionnaireID: 1,
+			role:            model.RoleOwner,
+		},
+		{
+			description:     "直接の編集者はグループの閲覧者より強い",
+			userID:          "mds_boy",
+			questionnaireID: 1,
+			role:            model.RoleEditor,
+		},
+		{
+			description:     "グループを通して閲覧者",
+			userID:          "xxarupakaxx",
+			questionnaireID: 1,
+			role:            model.RoleViewer,
+		},
+		{
+			description:     "グループを通して編集者",
+			userID:          "mds_boy",
+			questionnaireID: 2,
+			role:            model.RoleEditor,
+		},
+		{
+			description:     "管理者ではない",
+			userID:          "xxarupakaxx",
+			questionnaireID: 2,
+			role:            "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		role, err := getQuestionnaireRole(ctx, administrator, group, testCase.userID, testCase.questionnaireID)
+		assert.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.role, role, testCase.description)
+	}
+
+	_, err := getQuestionnaireRole(ctx, administrator, &fakeGroup{err: errors.New("traQ is down")}, "xxarupakaxx", 1)
+	assert.Error(t, err, "グループの管理者がいる場合はtraQに問い合わせる")
+
+	role, err := getQuestionnaireRole(ctx, administrator, &fakeGroup{err: errors.New("traQ is down")}, "mazrean", 1)
+	assert.NoError(t, err, "オーナーの場合はtraQに問い合わせない")
+	assert.Equal(t, model.RoleOwner, role)
+}
+
+func TestHasPermission(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, hasPermission(model.RoleOwner, PermissionManage))
+	assert.True(t, hasPermission(model.RoleEditor, PermissionEdit))
+	assert.False(t, hasPermission(model.RoleEditor, PermissionManage))
+	assert.True(t, hasPermission(model.RoleViewer, PermissionViewResults))
+	assert.False(t, hasPermission(model.RoleViewer, PermissionEdit))
+	assert.False(t, hasPermission("", PermissionViewResults))
+}
+
+// fakeQuestionnaireDeleted CheckQuestionnaireDeletedのみを実装したテスト用のmodel.IQuestionnaire
+type fakeQuestionnaireDeleted struct {
+	model.IQuestionnaire
+	// deleted アンケートのIDとゴミ箱に移動されているか
+	deleted map[int]bool
+}
+
+func (f *fakeQuestionnaireDeleted) CheckQuestionnaireDeleted(ctx context.Context, questionnaireID int) (bool, error) {
+	isDeleted, ok := f.deleted[questionnaireID]
+	if !ok {
+		return false, fmt.Errorf("failed to get deleted_at: %w", gorm.ErrRecordNotFound)
+	}
+
+	return isDeleted, nil
+}
+
+func TestQuestionnairePermission(t *testing.T) {
+	t.Parallel()
+
+	m := NewMiddleware(newFakeAdministrator(), &fakeQuestionnaireDeleted{deleted: map[int]bool{1: false}}, nil, nil, nil, nil, nil, newFakeGroup(), NewHeaderAuthenticator(), nil, nil, config.IdempotencyKeyConfig{})
+
+	e := echo.New()
+	e.GET("/api/questionnaires/:questionnaireID/edit", func(c echo.Context) error {
+		role, err := getRole(c)
+		if err != nil {
+			return err
+		}
+
+		return c.String(http.StatusOK, role)
+	}, m.UserAuthenticate, m.QuestionnairePermission(PermissionEdit))
+
+	testCases := []struct {
+		description string
+		userID      string
+		code        int
+		role        string
+	}{
+		{
+			description: "オーナーは編集できる",
+			userID:      "mazrean",
+			code:        http.StatusOK,
+			role:        model.RoleOwner,
+		},
+		{
+			description: "編集者は編集できる",
+			userID:      "mds_boy",
+			code:        http.StatusOK,
+			role:        model.RoleEditor,
+		},
+		{
+			description: "閲覧者は編集できない",
+			userID:      "xxarupakaxx",
+			code:        http.StatusForbidden,
+		},
+		{
+			description: "管理者でない",
+			userID:      "ryoha_test",
+			code:        http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/api/questionnaires/1/edit", nil)
+		req.Header.Set("X-Showcase-User", testCase.userID)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, testCase.code, rec.Code, testCase.description)
+		if testCase.code == http.StatusOK {
+			assert.Equal(t, testCase.role, rec.Body.String(), testCase.description)
+		}
+	}
+}
+
+func TestQuestionnairePermissionDeleted(t *testing.T) {
+	t.Parallel()
+
+	administrator := newFakeAdministrator()
+	_ = administrator.InsertAdministrators(context.Background(), 3, []string{"mazrean"}, model.RoleOwner)
+	m := NewMiddleware(administrator, &fakeQuestionnaireDeleted{deleted: map[int]bool{1: false, 3: true}}, nil, nil, nil, nil, nil, newFakeGroup(), NewHeaderAuthenticator(), nil, nil, config.IdempotencyKeyConfig{})
+
+	e := echo.New()
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.PATCH("/api/questionnaires/:questionnaireID", handler, m.UserAuthenticate, m.QuestionnairePermission(PermissionManage))
+	e.POST("/api/questionnaires/:questionnaireID/restore", handler, m.UserAuthenticate, m.DeletedQuestionnairePermission(PermissionManage))
+
+	testCases := []struct {
+		description string
+		method      string
+		path        string
+		userID      string
+		code        int
+	}{
+		{
+			description: "ゴミ箱のアンケートは編集できない",
+			method:      http.MethodPatch,
+			path:        "/api/questionnaires/3",
+			userID:      "mazrean",
+			code:        http.StatusNotFound,
+		},
+		{
+			description: "ゴミ箱のアンケートはハードコードされた管理者も編集できない",
+			method:      http.MethodPatch,
+			path:        "/api/questionnaires/3",
+			userID:      "temma",
+			code:        http.StatusNotFound,
+		},
+		{
+			description: "存在しないアンケート",
+			method:      http.MethodPatch,
+			path:        "/api/questionnaires/4",
+			userID:      "mazrean",
+			code:        http.StatusNotFound,
+		},
+		{
+			description: "ゴミ箱のアンケートは復元できる",
+			method:      http.MethodPost,
+			path:        "/api/questionnaires/3/restore",
+			userID:      "mazrean",
+			code:        http.StatusOK,
+		},
+		{
+			description: "ゴミ箱のアンケートでも管理者でなければ復元できない",
+			method:      http.MethodPost,
+			path:        "/api/questionnaires/3/restore",
+			userID:      "mds_boy",
+			code:        http.StatusForbidden,
+		},
+		{
+			description: "存在しないアンケートは復元できない",
+			method:      http.MethodPost,
+			path:        "/api/questionnaires/4/restore",
+			userID:      "mazrean",
+			code:        http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.path, nil)
+		req.Header.Set("X-Showcase-User", testCase.userID)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, testCase.code, rec.Code, testCase.description)
+	}
+}
+
+func TestCheckRoleDuplication(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, checkRoleDuplication([]string{"mazrean"}, []string{"mds_boy"}, []string{fakeGroupID1}))
+
+	var httpErr *echo.HTTPError
+	if assert.True(t, errors.As(checkRoleDuplication([]string{"mazrean"}, nil, []string{"mazrean"}), &httpErr)) {
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	}
+}
+
+func TestIsSameIDs(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isSameIDs([]string{"mazrean", "mds_boy"}, []string{"mds_boy", "mazrean"}))
+	assert.True(t, isSameIDs(nil, []string{}))
+	assert.False(t, isSameIDs([]string{"mazrean"}, []string{"mazrean", "mds_boy"}))
+	assert.False(t, isSameIDs([]string{"mazrean", "mds_boy"}, []string{"mazrean"}))
+}
@@ -0,0 +1,306 @@
+package router
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+)
+
+const (
+	// guestUserIDPrefix 共有リンクからの回答者のIDの接頭辞 (traQIDには:が使えないので重複しない)
+	guestUserIDPrefix = "guest:"
+	guestUserIDLength = 30
+)
+
+// ShareLinkSigner 共有リンクのトークンの署名
+type ShareLinkSigner struct {
+	secret []byte
+}
+
+// NewShareLinkSigner secretを鍵とするShareLinkSignerのコンストラクタ
+// 再起動で発行済みの共有リンクが無効にならないよう鍵は設定で固定する
+func NewShareLinkSigner(secret string) (*ShareLinkSigner, error) {
+	if secret == "" {
+		return nil, errors.New("share link secret is empty")
+	}
+
+	return &ShareLinkSigner{
+		secret: []byte(secret),
+	}, nil
+}
+
+// Sign 共有リンクのIDからトークンを作る
+func (s *ShareLinkSigner) Sign(shareLinkID int) string {
+	strShareLinkID := strconv.Itoa(shareLinkID)
+
+	return strShareLinkID + "." + base64.RawURLEncoding.EncodeToString(s.signature(strShareLinkID))
+}
+
+// Verify トークンの署名を確認して共有リンクのIDを返す
+func (s *ShareLinkSigner) Verify(token string) (int, error) {
+	strShareLinkID, strSignature := "", ""
+	if i := strings.Index(token, "."); i >= 0 {
+		strShareLinkID, strSignature = token[:i], token[i+1:]
+	}
+
+	shareLinkID, err := strconv.Atoi(strShareLinkID)
+	if err != nil {
+		return 0, errors.New("invalid share link token")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(strSignature)
+	if err != nil || !hmac.Equal(signature, s.signature(strShareLinkID)) {
+		return 0, errors.New("invalid share link signature")
+	}
+
+	return shareLinkID, nil
+}
+
+func (s *ShareLinkSigner) signature(strShareLinkID string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("share_link:" + strShareLinkID))
+
+	return mac.Sum(nil)
+}
+
+// ShareLinkInfo 共有リンクとそのトークン
+type ShareLinkInfo struct {
+	model.ShareLinks
+	Token string `json:"token"`
+}
+
+// GetQuestionnaireShareLinks GET /questionnaires/:questionnaireID/share-links
+func (q *Questionnaire) GetQuestionnaireShareLinks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	shareLinks, err := q.GetShareLinks(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	shareLinkInfos := make([]ShareLinkInfo, 0, len(shareLinks))
+	for _, shareLink := range shareLinks {
+		shareLinkInfos = append(shareLinkInfos, ShareLinkInfo{
+			ShareLinks: shareLink,
+			Token:      q.shareLinkSigner.Sign(shareLink.ID),
+		})
+	}
+
+	return c.JSON(http.StatusOK, shareLinkInfos)
+}
+
+// PostShareLink POST /questionnaires/:questionnaireID/share-links
+func (q *Questionnaire) PostShareLink(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	req := struct {
+		MaxUses   null.Int  `json:"max_uses"`
+		ExpiresAt null.Time `json:"expires_at"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("failed to bind request: %w", err))
+	}
+
+	if req.MaxUses.Valid && req.MaxUses.Int64 <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "max_uses must be positive")
+	}
+	if req.ExpiresAt.Valid && !req.ExpiresAt.Time.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	shareLinkID, err := q.audit.RecordCreation(ctx, userID, service.ActionShareLinkCreate, service.TargetShareLink, func(ctx context.Context) (int, error) {
+		return q.InsertShareLink(ctx, questionnaireID, userID, req.MaxUses, req.ExpiresAt)
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, ShareLinkInfo{
+		ShareLinks: model.ShareLinks{
+			ID:              shareLinkID,
+			QuestionnaireID: questionnaireID,
+			CreatedBy:       userID,
+			MaxUses:         req.MaxUses,
+			ExpiresAt:       req.ExpiresAt,
+			CreatedAt:       time.Now(),
+		},
+		Token: q.shareLinkSigner.Sign(shareLinkID),
+	})
+}
+
+// RevokeQuestionnaireShareLink DELETE /questionnaires/:questionnaireID/share-links/:shareLinkID
+func (q *Questionnaire) RevokeQuestionnaireShareLink(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	strShareLinkID := c.Param("shareLinkID")
+	shareLinkID, err := strconv.Atoi(strShareLinkID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("invalid shareLinkID:%s(error: %w)", strShareLinkID, err))
+	}
+
+	err = q.audit.Record(ctx, userID, service.ActionShareLinkRevoke, service.TargetShareLink, shareLinkID, func(ctx context.Context) error {
+		return q.RevokeShareLink(ctx, questionnaireID, shareLinkID)
+	})
+	if errors.Is(err, model.ErrNoRecordUpdated) {
+		return echo.NewHTTPError(http.StatusNotFound, "the share link does not exist or is already revoked")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetSharedQuestionnaire GET /share/:token
+func (q *Questionnaire) GetSharedQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	questionnaire, _, _, _, err := q.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	questionInfos, err := q.getQuestionInfos(ctx, questionnaireID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"questionnaireID": questionnaire.ID,
+		"title":           questionnaire.Title,
+		"description":     questionnaire.Description,
+		"res_time_limit":  questionnaire.ResTimeLimit,
+		"is_anonymous":    questionnaire.IsAnonymous,
+		"closed_at":       questionnaire.ClosedAt,
+		"questions":       questionInfos,
+	})
+}
+
+// PostGuestResponse POST /share/:token/responses
+func (r *Response) PostGuestResponse(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	questionnaireID, err := getQuestionnaireID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaireID: %w", err))
+	}
+
+	shareLinkID, err := getShareLinkID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get shareLinkID: %w", err))
+	}
+
+	req := struct {
+		Body []model.ResponseBody `json:"body"`
+	}{}
+	if err := c.Bind(&req); err != nil {
+		c.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	if err := r.checkResponseAcceptable(ctx, questionnaireID); err != nil {
+		return err
+	}
+
+	if err := r.checkResponseBodiesInQuestionnaire(ctx, questionnaireID, req.Body); err != nil {
+		return err
+	}
+
+	if err := r.validateResponseBodies(ctx, req.Body); err != nil {
+		return err
+	}
+
+	guestUserID, err := generateGuestUserID()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to generate guest id: %w", err))
+	}
+
+	// 下書きは後から編集できないので送信済みの回答にする
+	submittedAt := null.TimeFrom(time.Now())
+	var responseID int
+	// 回答の追加に失敗した場合に共有リンクの回答数を消費しないように同じトランザクションで行う
+	err = r.Do(ctx, nil, func(ctx context.Context) error {
+		err := r.UseShareLink(ctx, shareLinkID)
+		if errors.Is(err, model.ErrNoRecordUpdated) {
+			return echo.NewHTTPError(http.StatusGone, "the share link has expired or been revoked")
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		responseID, err = r.InsertRespondent(ctx, guestUserID, questionnaireID, submittedAt)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		err = r.InsertResponses(ctx, responseID, createResponseMetas(req.Body))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to insert responses: %w", err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return toHTTPError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"responseID":      responseID,
+		"questionnaireID": questionnaireID,
+		"submitted_at":    submittedAt,
+		"body":            req.Body,
+	})
+}
+
+// generateGuestUserID 共有リンクからの回答者のIDの生成
+func generateGuestUserID() (string, error) {
+	randomString, err := generateRandomString()
+	if err != nil {
+		return "", err
+	}
+
+	return (guestUserIDPrefix + randomString)[:guestUserIDLength], nil
+}
@@ -0,0 +1,275 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/config"
+	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+)
+
+// fakeShareLink テスト用のメモリ上のmodel.IShareLinkの実装
+type fakeShareLink struct {
+	shareLinks []model.ShareLinks
+}
+
+func (f *fakeShareLink) InsertShareLink(ctx context.Context, questionnaireID int, createdBy string, maxUses null.Int, expiresAt null.Time) (int, error) {
+	shareLinkID := len(f.shareLinks) + 1
+	f.shareLinks = append(f.shareLinks, model.ShareLinks{
+		ID:              shareLinkID,
+		QuestionnaireID: questionnaireID,
+		CreatedBy:       createdBy,
+		MaxUses:         maxUses,
+		ExpiresAt:       expiresAt,
+	})
+
+	return shareLinkID, nil
+}
+
+func (f *fakeShareLink) GetShareLinks(ctx context.Context, questionnaireID int) ([]model.ShareLinks, error) {
+	shareLinks := []model.ShareLinks{}
+	for _, shareLink := range f.shareLinks {
+		if shareLink.QuestionnaireID == questionnaireID {
+			shareLinks = append(shareLinks, shareLink)
+		}
+	}
+
+	return shareLinks, nil
+}
+
+func (f *fakeShareLink) GetShareLink(ctx context.Context, shareLinkID int) (*model.ShareLinks, error) {
+	for _, shareLink := range f.shareLinks {
+		if shareLink.ID == shareLinkID {
+			return &shareLink, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get a share link: %w", gorm.ErrRecordNotFound)
+}
+
+func (f *fakeShareLink) UseShareLink(ctx context.Context, shareLinkID int) error {
+	for i, shareLink := range f.shareLinks {
+		if shareLink.ID == shareLinkID && shareLink.IsAvailable(time.Now()) {
+			f.shareLinks[i].UseCount++
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to use a share link: %w", model.ErrNoRecordUpdated)
+}
+
+func (f *fakeShareLink) RevokeShareLink(ctx context.Context, questionnaireID int, shareLinkID int) error {
+	for i, shareLink := range f.shareLinks {
+		if shareLink.ID == shareLinkID && shareLink.QuestionnaireID == questionnaireID && !shareLink.RevokedAt.Valid {
+			f.shareLinks[i].RevokedAt = null.TimeFrom(time.Now())
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to revoke a share link: %w", model.ErrNoRecordUpdated)
+}
+
+func TestShareLinkSigner(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewShareLinkSigner("")
+	assert.Error(t, err, "empty secret")
+
+	signer, err := NewShareLinkSigner("secret")
+	require.NoError(t, err)
+	otherSigner, err := NewShareLinkSigner("other secret")
+	require.NoError(t, err)
+
+	token := signer.Sign(12)
+	assert.True(t, strings.HasPrefix(token, "12."))
+
+	shareLinkID, err := signer.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, shareLinkID)
+
+	_, err = otherSigner.Verify(token)
+	assert.Error(t, err, "signed with another secret")
+
+	_, err = signer.Verify("13" + strings.TrimPrefix(token, "12"))
+	assert.Error(t, err, "id is changed")
+
+	for _, invalidToken := range []string{"", "12", "12.", "a." + strings.TrimPrefix(token, "12."), "12.!!!"} {
+		_, err = signer.Verify(invalidToken)
+		assert.Error(t, err, invalidToken)
+	}
+}
+
+func TestShareLinkAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	shareLink := &fakeShareLink{}
+	availableID, err := shareLink.InsertShareLink(ctx, 1, "mazrean", null.NewInt(0, false), null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	expiredID, err := shareLink.InsertShareLink(ctx, 1, "mazrean", null.NewInt(0, false), null.TimeFrom(time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+	usedUpID, err := shareLink.InsertShareLink(ctx, 1, "mazrean", null.IntFrom(1), null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	require.NoError(t, shareLink.UseShareLink(ctx, usedUpID))
+	revokedID, err := shareLink.InsertShareLink(ctx, 1, "mazrean", null.NewInt(0, false), null.NewTime(time.Time{}, false))
+	require.NoError(t, err)
+	require.NoError(t, shareLink.RevokeShareLink(ctx, 1, revokedID))
+
+	signer := &ShareLinkSigner{secret: []byte("secret")}
+	m := NewMiddleware(nil, nil, nil, nil, nil, nil, shareLink, nil, NewHeaderAuthenticator(), signer, nil, config.IdempotencyKeyConfig{})
+
+	e := echo.New()
+	e.GET("/api/share/:token", func(c echo.Context) error {
+		questionnaireID, err := getQuestionnaireID(c)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, strconv.Itoa(questionnaireID))
+	}, m.ShareLinkAuthenticate)
+
+	testCases := []struct {
+		description string
+		token       string
+		expectCode  int
+	}{
+		{
+			description: "available",
+			token:       signer.Sign(availableID),
+			expectCode:  http.StatusOK,
+		},
+		{
+			description: "invalid signature",
+			token:       strconv.Itoa(availableID) + ".invalid",
+			expectCode:  http.StatusNotFound,
+		},
+		{
+			description: "unknown share link",
+			token:       signer.Sign(100),
+			expectCode:  http.StatusNotFound,
+		},
+		{
+			description: "expired",
+			token:       signer.Sign(expiredID),
+			expectCode:  http.StatusGone,
+		},
+		{
+			description: "used up",
+			token:       signer.Sign(usedUpID),
+			expectCode:  http.StatusGone,
+		},
+		{
+			description: "revoked",
+			token:       signer.Sign(revokedID),
+			expectCode:  http.StatusGone,
+		},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/api/share/"+testCase.token, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, testCase.expectCode, rec.Code, testCase.description)
+		if testCase.expectCode == http.StatusOK {
+			assert.Equal(t, "1", rec.Body.String(), testCase.description)
+		}
+	}
+}
+
+func TestPostShareLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	shareLink := &fakeShareLink{}
+	signer := &ShareLinkSigner{secret: []byte("secret")}
+	auditLog := &fakeAuditLog{}
+	q := NewQuestionnaire(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, shareLink, nil, nil, nil, signer, newFakeAudit(auditLog, nil, nil, nil, shareLink))
+
+	e := echo.New()
+
+	testCases := []struct {
+		description string
+		body        string
+		expectCode  int
+	}{
+		{
+			description: "no limit",
+			body:        `{}`,
+			expectCode:  http.StatusCreated,
+		},
+		{
+			description: "with limit",
+			body:        `{"max_uses": 30, "expires_at": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`,
+			expectCode:  http.StatusCreated,
+		},
+		{
+			description: "zero max uses",
+			body:        `{"max_uses": 0}`,
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			description: "past expires_at",
+			body:        `{"expires_at": "` + time.Now().Add(-time.Hour).Format(time.RFC3339) + `"}`,
+			expectCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		c, rec := newInvitationContext(e, "mazrean", testCase.body)
+		err := q.PostShareLink(c)
+		assert.Equal(t, testCase.expectCode, getStatusCode(err, rec), testCase.description)
+	}
+
+	shareLinks, err := shareLink.GetShareLinks(ctx, 1)
+	require.NoError(t, err)
+	if assert.Len(t, shareLinks, 2) {
+		assert.False(t, shareLinks[0].MaxUses.Valid)
+		assert.Equal(t, int64(30), shareLinks[1].MaxUses.Int64)
+	}
+
+	c, rec := newInvitationContext(e, "mazrean", "")
+	c.SetParamNames("shareLinkID")
+	c.SetParamValues("1")
+	err = q.RevokeQuestionnaireShareLink(c)
+	assert.Equal(t, http.StatusOK, getStatusCode(err, rec))
+
+	c, rec = newInvitationContext(e, "mazrean", "")
+	c.SetParamNames("shareLinkID")
+	c.SetParamValues("1")
+	err = q.RevokeQuestionnaireShareLink(c)
+	assert.Equal(t, http.StatusNotFound, getStatusCode(err, rec), "already revoked")
+
+	actions := []string{}
+	for _, record := range auditLog.auditLogs {
+		actions = append(actions, record.Action)
+	}
+	assert.Equal(t, []string{service.ActionShareLinkCreate, service.ActionShareLinkCreate, service.ActionShareLinkRevoke}, actions)
+}
+
+func TestGenerateGuestUserID(t *testing.T) {
+	t.Parallel()
+
+	guestUserID, err := generateGuestUserID()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(guestUserID, guestUserIDPrefix))
+	assert.Len(t, guestUserID, guestUserIDLength, "fits in user_traqid")
+
+	otherGuestUserID, err := generateGuestUserID()
+	assert.NoError(t, err)
+	assert.NotEqual(t, guestUserID, otherGuestUserID)
+}
@@ -12,6 +12,8 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/traPtitech/anke-to/model"
+	"github.com/traPtitech/anke-to/service"
+	"github.com/traPtitech/anke-to/traq"
 )
 
 // User Userの構造体
@@ -20,18 +22,40 @@ type User struct {
 	model.IQuestionnaire
 	model.ITarget
 	model.IAdministrator
+	model.IAPIToken
+	model.IInvitation
+	traq.IGroup
+	traq.IUser
+	traq.IWebhook
+	audit *service.Audit
 }
 
 // NewUser Userのコンストラクタ
-func NewUser(respondent model.IRespondent, questionnaire model.IQuestionnaire, target model.ITarget, administrator model.IAdministrator) *User {
+func NewUser(respondent model.IRespondent, questionnaire model.IQuestionnaire, target model.ITarget, administrator model.IAdministrator, apiToken model.IAPIToken, invitation model.IInvitation, group traq.IGroup, user traq.IUser, webhook traq.IWebhook, audit *service.Audit) *User {
 	return &User{
 		IRespondent:    respondent,
 		IQuestionnaire: questionnaire,
 		ITarget:        target,
 		IAdministrator: administrator,
+		IAPIToken:      apiToken,
+		IInvitation:    invitation,
+		IGroup:         group,
+		IUser:          user,
+		IWebhook:       webhook,
+		audit:          audit,
 	}
 }
 
+// GetUsers GET /users
+func (u *User) GetUsers(c echo.Context) error {
+	users, err := u.GetUserInfos()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get users: %w", err))
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
 // GetUsersMe GET /users/me
 func (*User) GetUsersMe(c echo.Context) error {
 	userID, err := getUserID(c)
@@ -46,12 +70,14 @@ func (*User) GetUsersMe(c echo.Context) error {
 
 // GetMyResponses GET /users/me/responses
 func (u *User) GetMyResponses(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 	}
 
-	myResponses, err := u.GetRespondentInfos(userID)
+	myResponses, err := u.GetRespondentInfos(ctx, userID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
@@ -61,6 +87,8 @@ func (u *User) GetMyResponses(c echo.Context) error {
 
 // GetMyResponsesByID GET /users/me/responses/:questionnaireID
 func (u *User) GetMyResponsesByID(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
@@ -72,7 +100,7 @@ func (u *User) GetMyResponsesByID(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest)
 	}
 
-	myresponses, err := u.GetRespondentInfos(userID, questionnaireID)
+	myresponses, err := u.GetRespondentInfos(ctx, userID, questionnaireID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
@@ -82,13 +110,15 @@ func (u *User) GetMyResponsesByID(c echo.Context) error {
 
 // GetTargetedQuestionnaire GET /users/me/targeted
 func (u *User) GetTargetedQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 	}
 
 	sort := c.QueryParam("sort")
-	ret, err := u.GetTargettedQuestionnaires(userID, "", sort)
+	ret, err := u.GetTargettedQuestionnaires(ctx, userID, getUserGroupIDs(c, u.IGroup, userID), "", sort)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -101,13 +131,15 @@ func (u *User) GetTargetedQuestionnaire(c echo.Context) error {
 
 // GetMyQuestionnaire GET /users/me/administrates
 func (u *User) GetMyQuestionnaire(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	userID, err := getUserID(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
 	}
 
 	// 自分が管理者になっているアンケート一覧
-	questionnaires, err := u.GetAdminQuestionnaires(userID)
+	questionnaires, err := u.GetAdminQuestionnaires(ctx, userID, getUserGroupIDs(c, u.IGroup, userID))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaires: %w", err))
 	}
@@ -117,7 +149,7 @@ func (u *User) GetMyQuestionnaire(c echo.Context) error {
 		questionnaireIDs = append(questionnaireIDs, questionnaire.ID)
 	}
 
-	targets, err := u.GetTargets(questionnaireIDs)
+	targets, err := u.GetTargets(ctx, questionnaireIDs)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get targets: %w", err))
 	}
@@ -131,7 +163,7 @@ func (u *User) GetMyQuestionnaire(c echo.Context) error {
 		}
 	}
 
-	respondents, err := u.GetRespondentsUserIDs(questionnaireIDs)
+	respondents, err := u.GetRespondentsUserIDs(ctx, questionnaireIDs)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get respondents: %w", err))
 	}
@@ -145,32 +177,30 @@ func (u *User) GetMyQuestionnaire(c echo.Context) error {
 		}
 	}
 
-	administrators, err := u.GetAdministrators(questionnaireIDs)
+	administrators, err := u.GetAdministrators(ctx, questionnaireIDs)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get administrators: %w", err))
 	}
-	administratorMap := map[int][]string{}
-	for _, administrator := range administrators {
-		admins, ok := administratorMap[administrator.QuestionnaireID]
-		if !ok {
-			administratorMap[administrator.QuestionnaireID] = []string{administrator.UserTraqid}
-		} else {
-			administratorMap[administrator.QuestionnaireID] = append(admins, administrator.UserTraqid)
-		}
-	}
+	administratorMap := splitAdministratorsByRole(administrators)
 
 	type QuestionnaireInfo struct {
-		ID             int       `json:"questionnaireID"`
-		Title          string    `json:"title"`
-		Description    string    `json:"description"`
-		ResTimeLimit   null.Time `json:"res_time_limit"`
-		CreatedAt      string    `json:"created_at"`
-		ModifiedAt     string    `json:"modified_at"`
-		ResSharedTo    string    `json:"res_shared_to"`
-		AllResponded   bool      `json:"all_responded"`
-		Targets        []string  `json:"targets"`
-		Administrators []string  `json:"administrators"`
-		Respondents    []string  `json:"respondents"`
+		ID              int       `json:"questionnaireID"`
+		Title           string    `json:"title"`
+		Description     string    `json:"description"`
+		ResTimeLimit    null.Time `json:"res_time_limit"`
+		CreatedAt       string    `json:"created_at"`
+		ModifiedAt      string    `json:"modified_at"`
+		ResSharedTo     string    `json:"res_shared_to"`
+		IsAnonymous     bool      `json:"is_anonymous"`
+		ClosedAt        null.Time `json:"closed_at"`
+		OrphanedAt      null.Time `json:"orphaned_at"`
+		AllResponded    bool      `json:"all_responded"`
+		Targets         []string  `json:"targets"`
+		Administrators  []string  `json:"administrators"`
+		Editors         []string  `json:"editors"`
+		Viewers         []string  `json:"viewers"`
+		Respondents     []string  `json:"respondents"`
+		RespondentCount int       `json:"respondent_count"`
 	}
 	ret := []QuestionnaireInfo{}
 
@@ -180,54 +210,84 @@ func (u *User) GetMyQuestionnaire(c echo.Context) error {
 			targets = []string{}
 		}
 
-		administrators, ok := administratorMap[questionnaire.ID]
-		if !ok {
-			administrators = []string{}
-		}
+		roleMap := administratorMap[questionnaire.ID]
 
 		respondents, ok := respondentMap[questionnaire.ID]
 		if !ok {
 			respondents = []string{}
 		}
+		respondentCount := len(respondents)
 
 		allresponded := true
-		for _, t := range targets {
-			found := false
-			for _, r := range respondents {
-				if t == r {
-					found = true
+		if questionnaire.IsAnonymous {
+			// 匿名のアンケートでは対象者と回答者を照合できない
+			respondents = []string{}
+			allresponded = len(targets) == 0
+		} else {
+			for _, t := range targets {
+				found := false
+				for _, r := range respondents {
+					if t == r {
+						found = true
+						break
+					}
+				}
+				if !found {
+					allresponded = false
 					break
 				}
 			}
-			if !found {
-				allresponded = false
-				break
-			}
 		}
 
 		ret = append(ret, QuestionnaireInfo{
-			ID:             questionnaire.ID,
-			Title:          questionnaire.Title,
-			Description:    questionnaire.Description,
-			ResTimeLimit:   questionnaire.ResTimeLimit,
-			CreatedAt:      questionnaire.CreatedAt.Format(time.RFC3339),
-			ModifiedAt:     questionnaire.ModifiedAt.Format(time.RFC3339),
-			ResSharedTo:    questionnaire.ResSharedTo,
-			AllResponded:   allresponded,
-			Targets:        targets,
-			Administrators: administrators,
-			Respondents:    respondents,
+			ID:              questionnaire.ID,
+			Title:           questionnaire.Title,
+			Description:     questionnaire.Description,
+			ResTimeLimit:    questionnaire.ResTimeLimit,
+			CreatedAt:       questionnaire.CreatedAt.Format(time.RFC3339),
+			ModifiedAt:      questionnaire.ModifiedAt.Format(time.RFC3339),
+			ResSharedTo:     questionnaire.ResSharedTo,
+			IsAnonymous:     questionnaire.IsAnonymous,
+			ClosedAt:        questionnaire.ClosedAt,
+			OrphanedAt:      questionnaire.OrphanedAt,
+			AllResponded:    allresponded,
+			Targets:         targets,
+			Administrators:  nonNilIDs(roleMap[model.RoleOwner]),
+			Editors:         nonNilIDs(roleMap[model.RoleEditor]),
+			Viewers:         nonNilIDs(roleMap[model.RoleViewer]),
+			Respondents:     respondents,
+			RespondentCount: respondentCount,
 		})
 	}
 
 	return c.JSON(http.StatusOK, ret)
 }
 
+// GetMyDeletedQuestionnaires GET /users/me/trash
+func (u *User) GetMyDeletedQuestionnaires(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get userID: %w", err))
+	}
+
+	// 自分が管理者になっている削除済みのアンケート一覧
+	questionnaires, err := u.GetDeletedQuestionnaires(ctx, userID, getUserGroupIDs(c, u.IGroup, userID))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get questionnaires: %w", err))
+	}
+
+	return c.JSON(http.StatusOK, questionnaires)
+}
+
 // GetTargettedQuestionnairesBytraQID GET /users/:traQID/targeted
 func (u *User) GetTargettedQuestionnairesBytraQID(c echo.Context) error {
+	ctx := c.Request().Context()
+
 	traQID := c.Param("traQID")
 	sort := c.QueryParam("sort")
-	ret, err := u.GetTargettedQuestionnaires(traQID, "unanswered", sort)
+	ret, err := u.GetTargettedQuestionnaires(ctx, traQID, getUserGroupIDs(c, u.IGroup, traQID), "unanswered", sort)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -237,3 +297,44 @@ func (u *User) GetTargettedQuestionnairesBytraQID(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, ret)
 }
+
+// checkUserIDs 対象者・管理者のtraQIDが凍結されていないtraQのユーザーかの確認
+// 全員を表すtraPとグループのIDは確認しない
+func checkUserIDs(user traq.IUser, ids []string) error {
+	userIDs := []string{}
+	for _, id := range ids {
+		if id != "traP" && !traq.IsGroupID(id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	userSet, err := getUserSet(user)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if _, ok := userSet[userID]; !ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("user(%s) does not exist or is suspended", userID))
+		}
+	}
+
+	return nil
+}
+
+// getUserSet 凍結されていないtraQのユーザーのtraQIDの集合
+func getUserSet(user traq.IUser) (map[string]struct{}, error) {
+	users, err := user.GetUsers()
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("failed to get users: %w", err))
+	}
+	userSet := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		userSet[user] = struct{}{}
+	}
+
+	return userSet, nil
+}
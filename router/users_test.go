@@ -0,0 +1,129 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traPtitech/anke-to/traq"
+)
+
+// fakeUser テスト用のtraq.IUserの実装
+type fakeUser struct {
+	users []traq.Users
+	err   error
+}
+
+func newFakeUser() *fakeUser {
+	return &fakeUser{
+		users: []traq.Users{
+			{
+				ID:          "0fa5d740-0841-4b88-b7c8-34a68774c784",
+				TraqID:      "mazrean",
+				DisplayName: "まざりん",
+			},
+			{
+				ID:          "7f5e1c2a-1d0b-4f3a-9a7e-5b1c2d3e4f50",
+				TraqID:      "mds_boy",
+				DisplayName: "mds_boy",
+			},
+		},
+	}
+}
+
+func (f *fakeUser) GetUsers() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	userIDs := make([]string, 0, len(f.users))
+	for _, user := range f.users {
+		userIDs = append(userIDs, user.TraqID)
+	}
+
+	return userIDs, nil
+}
+
+func (f *fakeUser) GetUserInfos() ([]traq.Users, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.users, nil
+}
+
+func TestGetUsers(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	u := NewUser(nil, nil, nil, nil, nil, nil, nil, newFakeUser(), nil, nil)
+	e.GET("/api/users", u.GetUsers)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	users := []traq.Users{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&users))
+	assert.Equal(t, newFakeUser().users, users)
+
+	failing := NewUser(nil, nil, nil, nil, nil, nil, nil, &fakeUser{err: errors.New("traQ is down")}, nil, nil)
+	e.GET("/api/failing/users", failing.GetUsers)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/failing/users", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestCheckUserIDs(t *testing.T) {
+	t.Parallel()
+
+	user := newFakeUser()
+
+	testCases := []struct {
+		description string
+		ids         []string
+		code        int
+	}{
+		{
+			description: "存在するユーザーなのでOK",
+			ids:         []string{"mazrean", "mds_boy"},
+		},
+		{
+			description: "traPとグループのIDは確認しないのでOK",
+			ids:         []string{"traP", fakeGroupID1, "mazrean"},
+		},
+		{
+			description: "存在しないか凍結されたユーザーなので400",
+			ids:         []string{"mazrean", "suspended"},
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := checkUserIDs(user, testCase.ids)
+		if testCase.code == 0 {
+			assert.NoError(t, err, testCase.description)
+			continue
+		}
+
+		var httpErr *echo.HTTPError
+		if assert.True(t, errors.As(err, &httpErr), testCase.description) {
+			assert.Equal(t, testCase.code, httpErr.Code, testCase.description)
+		}
+	}
+
+	failingUser := &fakeUser{err: errors.New("traQ is down")}
+	assert.NoError(t, checkUserIDs(failingUser, []string{"traP"}), "traQに問い合わせないのでOK")
+
+	var httpErr *echo.HTTPError
+	if assert.True(t, errors.As(checkUserIDs(failingUser, []string{"mazrean"}), &httpErr)) {
+		assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/traPtitech/anke-to/model"
+	"gopkg.in/guregu/null.v3"
+)
+
+// 監査ログの操作の種類
+const (
+	ActionQuestionnaireCreate              = "questionnaire.create"
+	ActionQuestionnaireUpdate              = "questionnaire.update"
+	ActionQuestionnaireDelete              = "questionnaire.delete"
+	ActionQuestionnaireRestore             = "questionnaire.restore"
+	ActionQuestionnaireClose               = "questionnaire.close"
+	ActionQuestionnaireReopen              = "questionnaire.reopen"
+	ActionQuestionnaireTransferOwnership   = "questionnaire.transfer_ownership"
+	ActionQuestionnaireInviteAdministrator = "questionnaire.invite_administrator"
+	ActionQuestionnaireAcceptInvitation    = "questionnaire.accept_invitation"
+	ActionQuestionnaireDeclineInvitation   = "questionnaire.decline_invitation"
+	ActionQuestionCreate                   = "question.create"
+	ActionQuestionUpdate                   = "question.update"
+	ActionQuestionDelete                   = "question.delete"
+	ActionResponseProxyCreate              = "response.proxy_create"
+	ActionResponseDelete                   = "response.delete"
+	ActionResponseImport                   = "response.import"
+	ActionShareLinkCreate                  = "share_link.create"
+	ActionShareLinkRevoke                  = "share_link.revoke"
+)
+
+// 監査ログの操作の対象の種類
+const (
+	TargetQuestionnaire = "questionnaire"
+	TargetQuestion      = "question"
+	TargetResponse      = "response"
+	TargetShareLink     = "share_link"
+)
+
+// Audit 管理操作を監査ログに記録しながら実行する
+type Audit struct {
+	model.ITransaction
+	model.IAuditLog
+	model.IQuestionnaire
+	model.IAdministrator
+	model.IInvitation
+	model.IQuestion
+	model.IOption
+	model.IScaleLabel
+	model.IValidation
+	model.IRespondent
+	model.IShareLink
+}
+
+// NewAudit Auditのコンストラクタ
+func NewAudit(transaction model.ITransaction, auditLog model.IAuditLog, questionnaire model.IQuestionnaire, administrator model.IAdministrator, invitation model.IInvitation, question model.IQuestion, option model.IOption, scaleLabel model.IScaleLabel, validation model.IValidation, respondent model.IRespondent, shareLink model.IShareLink) *Audit {
+	return &Audit{
+		ITransaction:   transaction,
+		IAuditLog:      auditLog,
+		IQuestionnaire: questionnaire,
+		IAdministrator: administrator,
+		IInvitation:    invitation,
+		IQuestion:      question,
+		IOption:        option,
+		IScaleLabel:    scaleLabel,
+		IValidation:    validation,
+		IRespondent:    respondent,
+		IShareLink:     shareLink,
+	}
+}
+
+/*
+Record 対象の操作前後の状態とともに操作を監査ログに記録する
+operationは記録と同じトランザクションで実行され，そのトランザクションのctxを受け取る
+operationが失敗した場合はそのエラーをそのまま返し，記録しない
+*/
+func (a *Audit) Record(ctx context.Context, actor string, action string, targetType string, targetID int, operation func(ctx context.Context) error) error {
+	return a.Do(ctx, nil, func(ctx context.Context) error {
+		before, err := a.snapshot(ctx, targetType, targetID)
+		if err != nil {
+			return err
+		}
+
+		err = operation(ctx)
+		if err != nil {
+			return err
+		}
+
+		return a.record(ctx, actor, action, targetType, targetID, before)
+	})
+}
+
+/*
+RecordCreation 作成の操作を作成された対象の状態とともに監査ログに記録する
+operationは記録と同じトランザクションで実行され，作成した対象のIDを返す
+*/
+func (a *Audit) RecordCreation(ctx context.Context, actor string, action string, targetType string, operation func(ctx context.Context) (int, error)) (int, error) {
+	var targetID int
+	err := a.Do(ctx, nil, func(ctx context.Context) error {
+		var err error
+		targetID, err = operation(ctx)
+		if err != nil {
+			return err
+		}
+
+		return a.record(ctx, actor, action, targetType, targetID, null.NewString("", false))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return targetID, nil
+}
+
+func (a *Audit) record(ctx context.Context, actor string, action string, targetType string, targetID int, before null.String) error {
+	after, err := a.snapshot(ctx, targetType, targetID)
+	if err != nil {
+		return err
+	}
+
+	err = a.InsertAuditLog(ctx, actor, action, targetType, targetID, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", action, err)
+	}
+
+	return nil
+}
+
+// snapshot 対象の現在の状態のJSON (対象が存在しない場合はNULL)
+func (a *Audit) snapshot(ctx context.Context, targetType string, targetID int) (null.String, error) {
+	var target interface{}
+	var err error
+	switch targetType {
+	case TargetQuestionnaire:
+		target, err = a.questionnaireSnapshot(ctx, targetID)
+	case TargetQuestion:
+		target, err = a.questionSnapshot(ctx, targetID)
+	case TargetResponse:
+		target, err = a.GetRespondentDetail(ctx, targetID)
+	case TargetShareLink:
+		target, err = a.GetShareLink(ctx, targetID)
+	default:
+		return null.String{}, fmt.Errorf("unknown audit target type: %s", targetType)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return null.NewString("", false), nil
+	}
+	if err != nil {
+		return null.String{}, fmt.Errorf("failed to get the %s snapshot: %w", targetType, err)
+	}
+
+	snapshot, err := json.Marshal(target)
+	if err != nil {
+		return null.String{}, fmt.Errorf("failed to marshal the %s snapshot: %w", targetType, err)
+	}
+
+	return null.StringFrom(string(snapshot)), nil
+}
+
+type questionnaireSnapshot struct {
+	*model.Questionnaires
+	Targets []string `json:"targets"`
+	// Administrators 役割ごとの管理者
+	Administrators map[string][]string `json:"administrators"`
+	// Invitations 承認されていない管理者への招待
+	Invitations []model.Invitations `json:"invitations"`
+}
+
+func (a *Audit) questionnaireSnapshot(ctx context.Context, questionnaireID int) (*questionnaireSnapshot, error) {
+	// 回答者は管理操作の対象ではないので含めない
+	questionnaire, targets, _, _, err := a.GetQuestionnaireInfo(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	administrators, err := a.GetAdministrators(ctx, []int{questionnaireID})
+	if err != nil {
+		return nil, err
+	}
+	roleAdministrators := map[string][]string{
+		model.RoleOwner:  {},
+		model.RoleEditor: {},
+		model.RoleViewer: {},
+	}
+	for _, administrator := range administrators {
+		roleAdministrators[administrator.Role] = append(roleAdministrators[administrator.Role], administrator.UserTraqid)
+	}
+
+	invitations, err := a.GetInvitations(ctx, questionnaireID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &questionnaireSnapshot{
+		Questionnaires: questionnaire,
+		Targets:        targets,
+		Administrators: roleAdministrators,
+		Invitations:    invitations,
+	}, nil
+}
+
+type questionSnapshot struct {
+	model.Questions
+	Options    []string           `json:"options"`
+	ScaleLabel *model.ScaleLabels `json:"scale_label"`
+	Validation *model.Validations `json:"validation"`
+}
+
+func (a *Audit) questionSnapshot(ctx context.Context, questionID int) (*questionSnapshot, error) {
+	question, err := a.GetQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := a.GetOptions(ctx, []int{questionID})
+	if err != nil {
+		return nil, err
+	}
+	optionBodies := make([]string, 0, len(options))
+	for _, option := range options {
+		optionBodies = append(optionBodies, option.Body)
+	}
+
+	snapshot := questionSnapshot{
+		Questions: question,
+		Options:   optionBodies,
+	}
+
+	scaleLabels, err := a.GetScaleLabels(ctx, []int{questionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(scaleLabels) != 0 {
+		snapshot.ScaleLabel = &scaleLabels[0]
+	}
+
+	validations, err := a.GetValidations(ctx, []int{questionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(validations) != 0 {
+		snapshot.Validation = &validations[0]
+	}
+
+	return &snapshot, nil
+}
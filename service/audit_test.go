@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/traPtitech/anke-to/model"
+)
+
+// fakeTransaction ロールバックされた回数を数えるテスト用のmodel.ITransactionの実装
+type fakeTransaction struct {
+	rollbacks int
+}
+
+type fakeTxKey struct{}
+
+func (f *fakeTransaction) Do(ctx context.Context, txOption *sql.TxOptions, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, fakeTxKey{}, true))
+	if err != nil {
+		f.rollbacks++
+	}
+
+	return err
+}
+
+func inFakeTransaction(ctx context.Context) bool {
+	inTx, _ := ctx.Value(fakeTxKey{}).(bool)
+	return inTx
+}
+
+// fakeAuditLog テスト用のメモリ上のmodel.IAuditLogの実装
+type fakeAuditLog struct {
+	model.IAuditLog
+	auditLogs []model.AuditLogs
+	err       error
+}
+
+func (f *fakeAuditLog) InsertAuditLog(ctx context.Context, actor string, action string, targetType string, targetID int, before null.String, after null.String) error {
+	if f.err != nil {
+		return f.err
+	}
+	if !inFakeTransaction(ctx) {
+		return errors.New("not in a transaction")
+	}
+
+	f.auditLogs = append(f.auditLogs, model.AuditLogs{
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	})
+
+	return nil
+}
+
+// fakeShareLink GetShareLinkのみを実装したテスト用のmodel.IShareLink
+type fakeShareLink struct {
+	model.IShareLink
+	shareLinks map[int]*model.ShareLinks
+}
+
+func (f *fakeShareLink) GetShareLink(ctx context.Context, shareLinkID int) (*model.ShareLinks, error) {
+	shareLink, ok := f.shareLinks[shareLinkID]
+	if !ok {
+		return nil, fmt.Errorf("failed to get a share link: %w", gorm.ErrRecordNotFound)
+	}
+
+	return shareLink, nil
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	transaction := &fakeTransaction{}
+	auditLog := &fakeAuditLog{}
+	shareLink := &fakeShareLink{shareLinks: map[int]*model.ShareLinks{}}
+	audit := NewAudit(transaction, auditLog, nil, nil, nil, nil, nil, nil, nil, nil, shareLink)
+
+	shareLinkID, err := audit.RecordCreation(ctx, "mazrean", ActionShareLinkCreate, TargetShareLink, func(ctx context.Context) (int, error) {
+		assert.True(t, inFakeTransaction(ctx), "the operation runs in the transaction")
+		shareLink.shareLinks[1] = &model.ShareLinks{ID: 1, QuestionnaireID: 1, CreatedBy: "mazrean"}
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, shareLinkID)
+
+	err = audit.Record(ctx, "mds_boy", ActionShareLinkRevoke, TargetShareLink, 1, func(ctx context.Context) error {
+		assert.True(t, inFakeTransaction(ctx), "the operation runs in the transaction")
+		shareLink.shareLinks[1].RevokedAt = null.TimeFrom(shareLink.shareLinks[1].CreatedAt)
+		return nil
+	})
+	require.NoError(t, err)
+
+	operationErr := errors.New("operation failed")
+	err = audit.Record(ctx, "mds_boy", ActionShareLinkRevoke, TargetShareLink, 1, func(ctx context.Context) error {
+		return operationErr
+	})
+	assert.Equal(t, operationErr, err, "the error of the operation is returned as it is")
+
+	if assert.Len(t, auditLog.auditLogs, 2, "failed operations are not recorded") {
+		assert.Equal(t, "mazrean", auditLog.auditLogs[0].Actor)
+		assert.False(t, auditLog.auditLogs[0].Before.Valid, "created targets have no before")
+		assert.Contains(t, auditLog.auditLogs[0].After.String, `"created_by":"mazrean"`)
+
+		assert.Equal(t, ActionShareLinkRevoke, auditLog.auditLogs[1].Action)
+		assert.Contains(t, auditLog.auditLogs[1].Before.String, `"revoked_at":null`)
+		assert.NotContains(t, auditLog.auditLogs[1].After.String, `"revoked_at":null`)
+	}
+
+	auditLog.err = errors.New("database is down")
+	err = audit.Record(ctx, "mds_boy", ActionShareLinkRevoke, TargetShareLink, 1, func(ctx context.Context) error {
+		return nil
+	})
+	assert.Error(t, err, "an error is returned if the operation cannot be recorded")
+	assert.Equal(t, 2, transaction.rollbacks, "the operation is rolled back if it cannot be recorded")
+
+	err = audit.Record(ctx, "mds_boy", ActionShareLinkRevoke, "unknown", 1, func(ctx context.Context) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package traq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const requestTimeout = 10 * time.Second
+
+// getJSON botのアクセストークンでtraQのAPIを叩きレスポンスのJSONをデコードする
+func getJSON(url string, accessToken string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	client := &http.Client{
+		Timeout: requestTimeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode: %w", err)
+	}
+
+	return nil
+}
+
+// traqUser traQのAPIのユーザー
+type traqUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	IconFileID  string `json:"iconFileId"`
+	State       int    `json:"state"`
+	Bot         bool   `json:"bot"`
+}
+
+// getActiveUsers 凍結されていないbot以外のユーザーの一覧の取得
+func getActiveUsers(baseURL string, accessToken string) ([]traqUser, error) {
+	users := []traqUser{}
+	err := getJSON(baseURL+"/users", accessToken, &users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// stateが1のユーザーのみが凍結されていないユーザー
+	activeUsers := make([]traqUser, 0, len(users))
+	for _, user := range users {
+		if user.State != 1 || user.Bot || user.Name == "traP" {
+			continue
+		}
+		activeUsers = append(activeUsers, user)
+	}
+
+	return activeUsers, nil
+}
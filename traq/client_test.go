@@ -0,0 +1,78 @@
+package traq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	fakeGroupID    = "c1a4e7a3-2b36-4c3e-8a25-0d5b0c0f7a11"
+	fakeAdminID    = "0fa5d740-0841-4b88-b7c8-34a68774c784"
+	fakeIconFileID = "6a3f2d1e-0c9b-4a8e-9d7c-6b5a4f3e2d1c"
+)
+
+// fakeTraQRequests fakeのtraQのサーバーが受けたリクエストの数
+type fakeTraQRequests struct {
+	users  int32
+	groups int32
+}
+
+// newFakeTraQServer /usersと/groupsのみを持つtraQのAPIのサーバー
+func newFakeTraQServer(t *testing.T, requests *fakeTraQRequests) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests.users, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": "` + fakeAdminID + `", "name": "mazrean", "displayName": "まざりん", "iconFileId": "` + fakeIconFileID + `", "state": 1, "bot": false},
+			{"id": "7f5e1c2a-1d0b-4f3a-9a7e-5b1c2d3e4f50", "name": "ryoha", "state": 1, "bot": false},
+			{"id": "2b3c4d5e-6f70-4a1b-8c2d-3e4f5a6b7c8d", "name": "suspended", "state": 0, "bot": false},
+			{"id": "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b", "name": "BOT_anke-to", "state": 1, "bot": true}
+		]`))
+	})
+	mux.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests.groups, 1)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{
+				"id": "` + fakeGroupID + `",
+				"name": "SysAd",
+				"description": "SysAd班",
+				"type": "",
+				"members": [
+					{"id": "` + fakeAdminID + `", "role": ""},
+					{"id": "2b3c4d5e-6f70-4a1b-8c2d-3e4f5a6b7c8d", "role": ""},
+					{"id": "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b", "role": ""}
+				],
+				"admins": ["` + fakeAdminID + `"],
+				"createdAt": "2020-10-01T00:00:00Z",
+				"updatedAt": "2020-10-02T00:00:00Z"
+			},
+			{
+				"id": "5d1f0e3c-7b2a-4c9d-8e6f-1a2b3c4d5e6f",
+				"name": "20B",
+				"description": "2020年度入学学部生",
+				"type": "grade",
+				"members": [
+					{"id": "7f5e1c2a-1d0b-4f3a-9a7e-5b1c2d3e4f50", "role": ""}
+				],
+				"admins": [],
+				"createdAt": "2020-04-01T00:00:00Z",
+				"updatedAt": "2020-04-01T00:00:00Z"
+			}
+		]`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
@@ -0,0 +1,9 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package traq
+
+// IGroup traQのユーザーグループのinterface
+type IGroup interface {
+	GetGroups() ([]Groups, error)
+	GetUserGroupIDs(userID string) ([]string, error)
+}
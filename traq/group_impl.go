@@ -0,0 +1,147 @@
+package traq
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traPtitech/anke-to/config"
+)
+
+// groupCacheTTL traQのグループの一覧をキャッシュする期間
+const groupCacheTTL = 5 * time.Minute
+
+// Group traQのグループAPIのクライアント
+type Group struct {
+	baseURL     string
+	accessToken string
+	mutex       sync.Mutex
+	groups      []Groups
+	expiresAt   time.Time
+}
+
+// NewGroup Groupのコンストラクター
+func NewGroup(traQConfig config.TraQConfig) *Group {
+	return &Group{
+		baseURL:     strings.TrimSuffix(traQConfig.APIURL, "/"),
+		accessToken: traQConfig.AccessToken,
+	}
+}
+
+// Groups traQのユーザーグループの構造体
+type Groups struct {
+	ID          string `json:"groupId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// AdminUser グループの管理者のうち1人のtraQID
+	AdminUser string `json:"adminUser"`
+	// Members 凍結されていないbot以外のメンバーのtraQID
+	Members   []string  `json:"members"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var groupIDRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// IsGroupID 対象者・管理者のIDがグループのIDか
+// traQIDは32文字以下なのでUUIDの形式のものはグループのIDとみなす
+func IsGroupID(id string) bool {
+	return groupIDRegexp.MatchString(id)
+}
+
+// GetGroups グループの一覧の取得
+func (g *Group) GetGroups() ([]Groups, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.groups != nil && time.Now().Before(g.expiresAt) {
+		return g.groups, nil
+	}
+
+	groups, err := g.fetchGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	g.groups = groups
+	g.expiresAt = time.Now().Add(groupCacheTTL)
+
+	return groups, nil
+}
+
+// GetUserGroupIDs ユーザーが所属するグループのIDの一覧の取得
+func (g *Group) GetUserGroupIDs(userID string) ([]string, error) {
+	groups, err := g.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := []string{}
+	for _, group := range groups {
+		for _, member := range group.Members {
+			if member == userID {
+				groupIDs = append(groupIDs, group.ID)
+				break
+			}
+		}
+	}
+
+	return groupIDs, nil
+}
+
+func (g *Group) fetchGroups() ([]Groups, error) {
+	traqGroups := []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Members     []struct {
+			ID string `json:"id"`
+		} `json:"members"`
+		Admins    []string  `json:"admins"`
+		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}{}
+	err := getJSON(g.baseURL+"/groups", g.accessToken, &traqGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	// traQのグループのメンバーはUUIDなのでtraQIDに変換する
+	users, err := getActiveUsers(g.baseURL, g.accessToken)
+	if err != nil {
+		return nil, err
+	}
+	userNames := make(map[string]string, len(users))
+	for _, user := range users {
+		userNames[user.ID] = user.Name
+	}
+
+	groups := make([]Groups, 0, len(traqGroups))
+	for _, traqGroup := range traqGroups {
+		group := Groups{
+			ID:          traqGroup.ID,
+			Name:        traqGroup.Name,
+			Description: traqGroup.Description,
+			Members:     make([]string, 0, len(traqGroup.Members)),
+			CreatedAt:   traqGroup.CreatedAt,
+			UpdatedAt:   traqGroup.UpdatedAt,
+		}
+		for _, adminID := range traqGroup.Admins {
+			if name, ok := userNames[adminID]; ok {
+				group.AdminUser = name
+				break
+			}
+		}
+		for _, member := range traqGroup.Members {
+			if name, ok := userNames[member.ID]; ok {
+				group.Members = append(group.Members, name)
+			}
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,51 @@
+package traq
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGroups(t *testing.T) {
+	t.Parallel()
+
+	requests := &fakeTraQRequests{}
+	server := newFakeTraQServer(t, requests)
+	group := &Group{
+		baseURL: server.URL,
+	}
+
+	groups, err := group.GetGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, fakeGroupID, groups[0].ID)
+	assert.Equal(t, "SysAd", groups[0].Name)
+	assert.Equal(t, "mazrean", groups[0].AdminUser)
+	assert.Equal(t, []string{"mazrean"}, groups[0].Members, "suspended users and bots are excluded")
+	assert.Equal(t, "", groups[1].AdminUser)
+	assert.Equal(t, []string{"ryoha"}, groups[1].Members)
+
+	_, err = group.GetGroups()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests.groups), "groups are cached")
+
+	groupIDs, err := group.GetUserGroupIDs("mazrean")
+	require.NoError(t, err)
+	assert.Equal(t, []string{fakeGroupID}, groupIDs)
+
+	groupIDs, err = group.GetUserGroupIDs("suspended")
+	require.NoError(t, err)
+	assert.Empty(t, groupIDs)
+}
+
+func TestIsGroupID(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsGroupID(fakeGroupID))
+	assert.False(t, IsGroupID("mazrean"))
+	assert.False(t, IsGroupID("traP"))
+	assert.False(t, IsGroupID("C1A4E7A3-2B36-4C3E-8A25-0D5B0C0F7A11x"))
+}
@@ -0,0 +1,9 @@
+//go:generate mockgen -source=$GOFILE -destination=mock_$GOPACKAGE/mock_$GOFILE
+
+package traq
+
+// IUser traQのユーザーのinterface
+type IUser interface {
+	GetUsers() ([]string, error)
+	GetUserInfos() ([]Users, error)
+}
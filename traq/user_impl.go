@@ -0,0 +1,82 @@
+package traq
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traPtitech/anke-to/config"
+)
+
+// userCacheTTL traQのユーザーの一覧をキャッシュする期間
+const userCacheTTL = 5 * time.Minute
+
+// User traQのユーザーAPIのクライアント
+type User struct {
+	baseURL     string
+	accessToken string
+	mutex       sync.Mutex
+	users       []Users
+	expiresAt   time.Time
+}
+
+// NewUser Userのコンストラクター
+func NewUser(traQConfig config.TraQConfig) *User {
+	return &User{
+		baseURL:     strings.TrimSuffix(traQConfig.APIURL, "/"),
+		accessToken: traQConfig.AccessToken,
+	}
+}
+
+// Users traQのユーザーの構造体
+type Users struct {
+	ID          string `json:"userId"`
+	TraqID      string `json:"traqID"`
+	DisplayName string `json:"displayName"`
+	IconFileID  string `json:"iconFileId"`
+}
+
+// GetUsers 凍結されていないbot以外のユーザーのtraQIDの一覧の取得
+func (u *User) GetUsers() ([]string, error) {
+	users, err := u.GetUserInfos()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.TraqID)
+	}
+
+	return userIDs, nil
+}
+
+// GetUserInfos 凍結されていないbot以外のユーザーの一覧の取得
+func (u *User) GetUserInfos() ([]Users, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.users != nil && time.Now().Before(u.expiresAt) {
+		return u.users, nil
+	}
+
+	traqUsers, err := getActiveUsers(u.baseURL, u.accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]Users, 0, len(traqUsers))
+	for _, user := range traqUsers {
+		users = append(users, Users{
+			ID:          user.ID,
+			TraqID:      user.Name,
+			DisplayName: user.DisplayName,
+			IconFileID:  user.IconFileID,
+		})
+	}
+
+	u.users = users
+	u.expiresAt = time.Now().Add(userCacheTTL)
+
+	return users, nil
+}
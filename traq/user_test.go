@@ -0,0 +1,34 @@
+package traq
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsers(t *testing.T) {
+	t.Parallel()
+
+	requests := &fakeTraQRequests{}
+	server := newFakeTraQServer(t, requests)
+	user := &User{
+		baseURL: server.URL,
+	}
+
+	users, err := user.GetUsers()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mazrean", "ryoha"}, users, "suspended users and bots are excluded")
+
+	userInfos, err := user.GetUserInfos()
+	require.NoError(t, err)
+	require.Len(t, userInfos, 2)
+	assert.Equal(t, Users{
+		ID:          fakeAdminID,
+		TraqID:      "mazrean",
+		DisplayName: "まざりん",
+		IconFileID:  fakeIconFileID,
+	}, userInfos[0])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests.users), "users are cached")
+}
@@ -8,23 +8,29 @@ import (
 	"fmt"
 	"net/http"
 	netUrl "net/url"
-	"os"
 	"strings"
 
 	"github.com/labstack/echo"
+	"github.com/traPtitech/anke-to/config"
 )
 
 // Webhook Webhookの構造体
-type Webhook struct{}
+type Webhook struct {
+	id     string
+	secret string
+}
 
 // NewWebhook Webhookのコンストラクター
-func NewWebhook() *Webhook {
-	return new(Webhook)
+func NewWebhook(traQConfig config.TraQConfig) *Webhook {
+	return &Webhook{
+		id:     traQConfig.WebhookID,
+		secret: traQConfig.WebhookSecret,
+	}
 }
 
 // PostMessage Webhookでのメッセージの投稿
-func (*Webhook) PostMessage(message string) error {
-	url := "https://q.trap.jp/api/v3/webhooks/" + os.Getenv("TRAQ_WEBHOOK_ID")
+func (w *Webhook) PostMessage(message string) error {
+	url := "https://q.trap.jp/api/v3/webhooks/" + w.id
 	req, err := http.NewRequest("POST",
 		url,
 		strings.NewReader(message))
@@ -33,7 +39,7 @@ func (*Webhook) PostMessage(message string) error {
 	}
 
 	req.Header.Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
-	req.Header.Set("X-TRAQ-Signature", calcHMACSHA1(message))
+	req.Header.Set("X-TRAQ-Signature", calcHMACSHA1(w.secret, message))
 
 	query := netUrl.Values{}
 	query.Add("embed", "1")
@@ -57,8 +63,8 @@ func (*Webhook) PostMessage(message string) error {
 	return nil
 }
 
-func calcHMACSHA1(message string) string {
-	mac := hmac.New(sha1.New, []byte(os.Getenv("TRAQ_WEBHOOK_SECRET")))
+func calcHMACSHA1(secret string, message string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
 	_, _ = mac.Write([]byte(message))
 	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -4,55 +4,95 @@ package main
 
 import (
 	"github.com/google/wire"
+	"github.com/traPtitech/anke-to/config"
 	"github.com/traPtitech/anke-to/model"
 	"github.com/traPtitech/anke-to/router"
+	"github.com/traPtitech/anke-to/service"
 	"github.com/traPtitech/anke-to/traq"
 )
 
 var (
-	administratorBind = wire.Bind(new(model.IAdministrator), new(*model.Administrator))
-	optionBind        = wire.Bind(new(model.IOption), new(*model.Option))
-	questionnaireBind = wire.Bind(new(model.IQuestionnaire), new(*model.Questionnaire))
-	questionBind      = wire.Bind(new(model.IQuestion), new(*model.Question))
-	respondentBind    = wire.Bind(new(model.IRespondent), new(*model.Respondent))
-	responseBind      = wire.Bind(new(model.IResponse), new(*model.Response))
-	scaleLabelBind    = wire.Bind(new(model.IScaleLabel), new(*model.ScaleLabel))
-	targetBind        = wire.Bind(new(model.ITarget), new(*model.Target))
-	validationBind    = wire.Bind(new(model.IValidation), new(*model.Validation))
+	administratorBind  = wire.Bind(new(model.IAdministrator), new(*model.Administrator))
+	apiTokenBind       = wire.Bind(new(model.IAPIToken), new(*model.APIToken))
+	auditLogBind       = wire.Bind(new(model.IAuditLog), new(*model.AuditLog))
+	idempotencyKeyBind = wire.Bind(new(model.IIdempotencyKey), new(*model.IdempotencyKey))
+	invitationBind     = wire.Bind(new(model.IInvitation), new(*model.Invitation))
+	optionBind         = wire.Bind(new(model.IOption), new(*model.Option))
+	questionnaireBind  = wire.Bind(new(model.IQuestionnaire), new(*model.Questionnaire))
+	questionBind       = wire.Bind(new(model.IQuestion), new(*model.Question))
+	respondentBind     = wire.Bind(new(model.IRespondent), new(*model.Respondent))
+	responseBind       = wire.Bind(new(model.IResponse), new(*model.Response))
+	revisionBind       = wire.Bind(new(model.IRevision), new(*model.Revision))
+	scaleLabelBind     = wire.Bind(new(model.IScaleLabel), new(*model.ScaleLabel))
+	sessionBind        = wire.Bind(new(model.ISession), new(*model.Session))
+	shareLinkBind      = wire.Bind(new(model.IShareLink), new(*model.ShareLink))
+	targetBind         = wire.Bind(new(model.ITarget), new(*model.Target))
+	transactionBind    = wire.Bind(new(model.ITransaction), new(*model.Transaction))
+	validationBind     = wire.Bind(new(model.IValidation), new(*model.Validation))
 
 	webhookBind = wire.Bind(new(traq.IWebhook), new(*traq.Webhook))
+	userBind    = wire.Bind(new(traq.IUser), new(*traq.User))
+	groupBind   = wire.Bind(new(traq.IGroup), new(*traq.Group))
 )
 
-func InjectAPIServer() *router.API {
+func InjectAPIServer(conf *config.Config) (*router.API, error) {
 	wire.Build(
+		wire.FieldsOf(new(*config.Config), "Server", "Auth", "TraQ", "RateLimit", "IdempotencyKey", "ShareLinkSecret"),
 		router.NewAPI,
+		router.NewAuthenticator,
 		router.NewMiddleware,
 		router.NewQuestionnaire,
 		router.NewQuestion,
 		router.NewResponse,
 		router.NewResult,
 		router.NewUser,
+		router.NewRevision,
+		router.NewGroup,
+		router.NewShareLinkSigner,
+		router.NewRateLimiters,
+		router.NewAuditLog,
+		service.NewAudit,
 		model.NewAdministrator,
+		model.NewAPIToken,
+		model.NewAuditLog,
+		model.NewIdempotencyKey,
+		model.NewInvitation,
 		model.NewOption,
 		model.NewQuestionnaire,
 		model.NewQuestion,
 		model.NewRespondent,
 		model.NewResponse,
+		model.NewRevision,
 		model.NewScaleLabel,
+		model.NewSession,
+		model.NewShareLink,
 		model.NewTarget,
+		model.NewTransaction,
 		model.NewValidation,
 		traq.NewWebhook,
+		traq.NewUser,
+		traq.NewGroup,
 		administratorBind,
+		apiTokenBind,
+		auditLogBind,
+		idempotencyKeyBind,
+		invitationBind,
 		optionBind,
 		questionnaireBind,
 		questionBind,
 		respondentBind,
 		responseBind,
+		revisionBind,
 		scaleLabelBind,
+		sessionBind,
+		shareLinkBind,
 		targetBind,
+		transactionBind,
 		validationBind,
 		webhookBind,
+		userBind,
+		groupBind,
 	)
 
-	return nil
+	return nil, nil
 }
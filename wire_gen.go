@@ -7,8 +7,10 @@ package main
 
 import (
 	"github.com/google/wire"
+	"github.com/traPtitech/anke-to/config"
 	"github.com/traPtitech/anke-to/model"
 	"github.com/traPtitech/anke-to/router"
+	"github.com/traPtitech/anke-to/service"
 	"github.com/traPtitech/anke-to/traq"
 )
 
@@ -18,39 +20,77 @@ import (
 
 // Injectors from wire.go:
 
-func InjectAPIServer() *router.API {
+func InjectAPIServer(conf *config.Config) (*router.API, error) {
 	administrator := model.NewAdministrator()
+	questionnaire := model.NewQuestionnaire()
 	respondent := model.NewRespondent()
 	question := model.NewQuestion()
-	middleware := router.NewMiddleware(administrator, respondent, question)
-	questionnaire := model.NewQuestionnaire()
+	idempotencyKey := model.NewIdempotencyKey()
+	apiToken := model.NewAPIToken()
+	session := model.NewSession()
+	authConfig := conf.Auth
+	traQConfig := conf.TraQ
+	authenticator, err := router.NewAuthenticator(authConfig, traQConfig, session)
+	if err != nil {
+		return nil, err
+	}
+	shareLink := model.NewShareLink()
+	group := traq.NewGroup(traQConfig)
+	string2 := conf.ShareLinkSecret
+	shareLinkSigner, err := router.NewShareLinkSigner(string2)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitConfig := conf.RateLimit
+	rateLimiters := router.NewRateLimiters(rateLimitConfig)
+	idempotencyKeyConfig := conf.IdempotencyKey
+	middleware := router.NewMiddleware(administrator, questionnaire, respondent, question, idempotencyKey, apiToken, shareLink, group, authenticator, shareLinkSigner, rateLimiters, idempotencyKeyConfig)
 	target := model.NewTarget()
 	option := model.NewOption()
 	scaleLabel := model.NewScaleLabel()
 	validation := model.NewValidation()
-	webhook := traq.NewWebhook()
-	routerQuestionnaire := router.NewQuestionnaire(questionnaire, target, administrator, question, option, scaleLabel, validation, webhook)
-	routerQuestion := router.NewQuestion(validation, question, option, scaleLabel)
+	revision := model.NewRevision()
+	webhook := traq.NewWebhook(traQConfig)
+	user := traq.NewUser(traQConfig)
+	invitation := model.NewInvitation()
+	auditLog := model.NewAuditLog()
+	transaction := model.NewTransaction()
+	audit := service.NewAudit(transaction, auditLog, questionnaire, administrator, invitation, question, option, scaleLabel, validation, respondent, shareLink)
+	routerQuestionnaire := router.NewQuestionnaire(questionnaire, target, administrator, question, option, scaleLabel, validation, revision, respondent, invitation, shareLink, webhook, user, group, shareLinkSigner, audit)
+	routerQuestion := router.NewQuestion(validation, questionnaire, question, administrator, option, scaleLabel, revision, group, audit)
 	response := model.NewResponse()
-	routerResponse := router.NewResponse(questionnaire, validation, scaleLabel, respondent, response)
-	result := router.NewResult(respondent, questionnaire, administrator)
-	user := router.NewUser(respondent, questionnaire, target, administrator)
-	api := router.NewAPI(middleware, routerQuestionnaire, routerQuestion, routerResponse, result, user)
-	return api
+	routerResponse := router.NewResponse(transaction, questionnaire, validation, scaleLabel, respondent, response, question, option, shareLink, user, audit)
+	result := router.NewResult(respondent, questionnaire, administrator, response, question, option, scaleLabel, group)
+	routerUser := router.NewUser(respondent, questionnaire, target, administrator, apiToken, invitation, group, user, webhook, audit)
+	routerRevision := router.NewRevision(revision)
+	routerGroup := router.NewGroup(group)
+	routerAuditLog := router.NewAuditLog(auditLog)
+	serverConfig := conf.Server
+	api := router.NewAPI(middleware, routerQuestionnaire, routerQuestion, routerResponse, result, routerUser, routerRevision, routerGroup, routerAuditLog, serverConfig)
+	return api, nil
 }
 
 // wire.go:
 
 var (
-	administratorBind = wire.Bind(new(model.IAdministrator), new(*model.Administrator))
-	optionBind        = wire.Bind(new(model.IOption), new(*model.Option))
-	questionnaireBind = wire.Bind(new(model.IQuestionnaire), new(*model.Questionnaire))
-	questionBind      = wire.Bind(new(model.IQuestion), new(*model.Question))
-	respondentBind    = wire.Bind(new(model.IRespondent), new(*model.Respondent))
-	responseBind      = wire.Bind(new(model.IResponse), new(*model.Response))
-	scaleLabelBind    = wire.Bind(new(model.IScaleLabel), new(*model.ScaleLabel))
-	targetBind        = wire.Bind(new(model.ITarget), new(*model.Target))
-	validationBind    = wire.Bind(new(model.IValidation), new(*model.Validation))
+	administratorBind  = wire.Bind(new(model.IAdministrator), new(*model.Administrator))
+	apiTokenBind       = wire.Bind(new(model.IAPIToken), new(*model.APIToken))
+	auditLogBind       = wire.Bind(new(model.IAuditLog), new(*model.AuditLog))
+	idempotencyKeyBind = wire.Bind(new(model.IIdempotencyKey), new(*model.IdempotencyKey))
+	invitationBind     = wire.Bind(new(model.IInvitation), new(*model.Invitation))
+	optionBind         = wire.Bind(new(model.IOption), new(*model.Option))
+	questionnaireBind  = wire.Bind(new(model.IQuestionnaire), new(*model.Questionnaire))
+	questionBind       = wire.Bind(new(model.IQuestion), new(*model.Question))
+	respondentBind     = wire.Bind(new(model.IRespondent), new(*model.Respondent))
+	responseBind       = wire.Bind(new(model.IResponse), new(*model.Response))
+	revisionBind       = wire.Bind(new(model.IRevision), new(*model.Revision))
+	scaleLabelBind     = wire.Bind(new(model.IScaleLabel), new(*model.ScaleLabel))
+	sessionBind        = wire.Bind(new(model.ISession), new(*model.Session))
+	shareLinkBind      = wire.Bind(new(model.IShareLink), new(*model.ShareLink))
+	targetBind         = wire.Bind(new(model.ITarget), new(*model.Target))
+	validationBind     = wire.Bind(new(model.IValidation), new(*model.Validation))
 
 	webhookBind = wire.Bind(new(traq.IWebhook), new(*traq.Webhook))
+	userBind    = wire.Bind(new(traq.IUser), new(*traq.User))
+	groupBind   = wire.Bind(new(traq.IGroup), new(*traq.Group))
 )